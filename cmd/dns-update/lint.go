@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"dns-update/internal/lint"
+	"dns-update/internal/service"
+)
+
+// runLint 执行 lint 子命令，返回进程退出码
+func runLint(dnsService *service.DNSService, args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	minTTL := fs.Int64("min-ttl", lint.DefaultOptions.MinTTL, "低于该值的TTL视为过低")
+	asJSON := fs.Bool("json", false, "以JSON格式输出检查报告")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: dns-update lint [-min-ttl 秒] [-json] <域名>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	domain := fs.Arg(0)
+
+	records, err := dnsService.ListDomainRecords(domain, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "获取域名解析记录失败: %v\n", err)
+		return 1
+	}
+
+	report := lint.Analyze(domain, records, &lint.Options{MinTTL: *minTTL})
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "输出检查报告失败: %v\n", err)
+			return 1
+		}
+	} else {
+		printLintReport(report)
+	}
+
+	if report.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+// printLintReport 以文本格式输出检查报告
+func printLintReport(report *lint.Report) {
+	fmt.Printf("域名: %s  记录数: %d\n", report.Domain, report.RecordCount)
+	for _, issue := range report.Issues {
+		fmt.Printf("[%s] %-18s %-10s %-6s %s", issue.Severity, issue.Rule, issue.RR, issue.Type, issue.Message)
+		if len(issue.RecordIds) > 0 {
+			fmt.Printf(" %v", issue.RecordIds)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("错误: %d  警告: %d  提示: %d\n",
+		report.Summary[lint.SeverityError],
+		report.Summary[lint.SeverityWarning],
+		report.Summary[lint.SeverityInfo],
+	)
+}
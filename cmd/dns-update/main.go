@@ -1,16 +1,37 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"slices"
 
+	"dns-update/internal/acme"
+	"dns-update/internal/approval"
+	"dns-update/internal/audit"
+	"dns-update/internal/batch"
 	"dns-update/internal/config"
+	"dns-update/internal/controller"
+	"dns-update/internal/docker"
+	"dns-update/internal/externaldns"
+	"dns-update/internal/failover"
 	"dns-update/internal/handler"
+	"dns-update/internal/lease"
 	"dns-update/internal/middleware"
+	"dns-update/internal/migration"
+	"dns-update/internal/notify"
+	"dns-update/internal/policy"
+	"dns-update/internal/schedule"
 	"dns-update/internal/service"
+	"dns-update/internal/slb"
+	"dns-update/internal/store"
 	"dns-update/pkg/logger"
 
 	"github.com/alibabacloud-go/tea/tea"
 	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // @title        DNS Update API
@@ -19,14 +40,24 @@ import (
 // @BasePath     /api
 
 func main() {
+	// 子命令的标准输出只用于输出结果，日志改为输出到标准错误
+	subcommand := len(os.Args) > 1
+
 	// 初始化日志
 	logger.InitLogger()
-	defer func(Log *zap.Logger) {
-		err := Log.Sync()
+	if subcommand {
+		bootstrap := logger.DefaultLogConfig
+		bootstrap.Outputs = []string{logger.OutputStderr}
+		if err := logger.Configure(bootstrap); err != nil {
+			panic("初始化日志失败: " + err.Error())
+		}
+	}
+	defer func() {
+		err := logger.GetLogger().Sync()
 		if err != nil {
 			logger.GetLogger().Error("日志同步失败", zap.Error(err))
 		}
-	}(logger.Log)
+	}()
 	log := logger.GetLogger()
 
 	// 加载配置
@@ -35,21 +66,239 @@ func main() {
 		log.Fatal("加载配置失败", zap.Error(err))
 	}
 
+	// 按配置重新初始化日志
+	logCfg := logConfig(&cfg.Logging)
+	if subcommand {
+		logCfg.Outputs = subcommandOutputs(logCfg.Outputs)
+	}
+	if err := logger.Configure(logCfg); err != nil {
+		log.Fatal("初始化日志失败", zap.Error(err))
+	}
+	log = logger.GetLogger()
+
 	// 初始化 DNS 服务
 	dnsService, err := service.NewDNSService(
 		tea.String(cfg.Aliyun.AccessKeyId),
 		tea.String(cfg.Aliyun.AccessKeySecret),
 		cfg.Aliyun.RegionId,
+		&service.DNSServiceOptions{
+			RateLimit:       cfg.Aliyun.RateLimit,
+			RateBurst:       cfg.Aliyun.RateBurst,
+			PageConcurrency: cfg.Aliyun.PageConcurrency,
+		},
 	)
 	if err != nil {
 		log.Fatal("初始化DNS服务失败", zap.Error(err))
 	}
 
+	// 子命令模式
+	if subcommand {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(dnsService, os.Args[2:]))
+		default:
+			log.Fatal("未知的子命令", zap.String("command", os.Args[1]))
+		}
+	}
+
+	// 初始化通知
+	targets := make([]notify.Target, 0, len(cfg.Notify.Webhooks))
+	for _, w := range cfg.Notify.Webhooks {
+		targets = append(targets, notify.Target{Name: w.Name, URL: w.URL, Format: w.Format})
+	}
+	notifier := notify.NewWebhook(targets)
+
+	// 初始化审计日志和写操作策略，策略需要在其他组件使用 DNS 服务前设置
+	auditLog, err := audit.New(cfg.Storage.Dir)
+	if err != nil {
+		log.Fatal("初始化审计日志失败", zap.Error(err))
+	}
+	if len(cfg.Policy.Rules) > 0 {
+		engine, err := policy.NewEngine(auditLog, notifier, &policy.Options{
+			Rules:            policyRules(cfg.Policy.Rules),
+			BreakGlassActors: cfg.Policy.BreakGlassActors,
+		})
+		if err != nil {
+			log.Fatal("初始化写操作策略失败", zap.Error(err))
+		}
+		dnsService.SetWriteGuard(engine)
+	}
+
+	// 初始化批量操作
+	batchExecutor := batch.NewExecutor(dnsService)
+	batchJobs := batch.NewJobManager(batchExecutor)
+
+	// 初始化定时变更，定时变更、TTL 迁移和临时记录以创建者的身份执行
+	scheduleFile, err := store.NewFile(cfg.Storage.Dir, schedule.StoreFile)
+	if err != nil {
+		log.Fatal("初始化数据目录失败", zap.Error(err))
+	}
+	scheduler, err := schedule.NewManager(func(actor service.Actor) schedule.Executor {
+		return batchExecutor.With(dnsService.WithActor(actor))
+	}, notifier, scheduleFile, &schedule.Options{
+		MaxAttempts:   cfg.Scheduler.MaxAttempts,
+		RetryInterval: cfg.Scheduler.RetryInterval,
+		MaxDelay:      cfg.Scheduler.MaxDelay,
+	})
+	if err != nil {
+		log.Fatal("初始化定时变更失败", zap.Error(err))
+	}
+	go scheduler.Run(context.Background())
+
+	// 初始化 TTL 迁移
+	migrationFile, err := store.NewFile(cfg.Storage.Dir, migration.StoreFile)
+	if err != nil {
+		log.Fatal("初始化数据目录失败", zap.Error(err))
+	}
+	migrations, err := migration.NewManager(func(actor service.Actor) migration.RecordService {
+		return dnsService.WithActor(actor)
+	}, notifier, migrationFile, &migration.Options{
+		LowTTL: cfg.Migration.LowTTL,
+		Margin: cfg.Migration.Margin,
+	})
+	if err != nil {
+		log.Fatal("初始化迁移失败", zap.Error(err))
+	}
+	go migrations.Run(context.Background())
+
+	// 初始化临时记录
+	leaseFile, err := store.NewFile(cfg.Storage.Dir, lease.StoreFile)
+	if err != nil {
+		log.Fatal("初始化数据目录失败", zap.Error(err))
+	}
+	leases, err := lease.NewManager(func(actor service.Actor) lease.RecordService {
+		return dnsService.WithActor(actor)
+	}, notifier, leaseFile, &lease.Options{
+		Interval:          cfg.Lease.Interval,
+		ReconcileInterval: cfg.Lease.ReconcileInterval,
+		MaxDuration:       cfg.Lease.MaxDuration,
+	})
+	if err != nil {
+		log.Fatal("初始化临时记录失败", zap.Error(err))
+	}
+	go leases.Run(context.Background())
+
+	// 初始化变更审批
+	var approvals *approval.Manager
+	if len(cfg.Approval.Rules) > 0 {
+		rules := make([]approval.Rule, 0, len(cfg.Approval.Rules))
+		for _, r := range cfg.Approval.Rules {
+			rules = append(rules, approval.Rule{Domain: r.Domain, RR: r.RR})
+		}
+		approvalFile, err := store.NewFile(cfg.Storage.Dir, approval.StoreFile)
+		if err != nil {
+			log.Fatal("初始化数据目录失败", zap.Error(err))
+		}
+		approvals, err = approval.NewManager(batchExecutor, dnsService, auditLog, notifier, approvalFile, &approval.Options{
+			Rules:     rules,
+			Approvers: cfg.Approval.Approvers,
+			Expiry:    cfg.Approval.ExpireAfter,
+		})
+		if err != nil {
+			log.Fatal("初始化变更审批失败", zap.Error(err))
+		}
+		go approvals.Run(context.Background())
+	}
+
 	// 初始化处理器
-	dnsHandler := handler.NewDNSHandler(dnsService)
+	handlers := &handler.Handlers{
+		DNS:       handler.NewDNSHandler(dnsService, leases, approvals),
+		Batch:     handler.NewBatchHandler(dnsService, batchExecutor, batchJobs, approvals),
+		Audit:     handler.NewAuditHandler(auditLog),
+		LogLevel:  handler.NewLogLevelHandler(auditLog),
+		SLB:       handler.NewSLBHandler(dnsService, slb.NewManager(dnsService), auditLog),
+		Schedule:  handler.NewScheduleHandler(scheduler, auditLog),
+		Migration: handler.NewMigrationHandler(migrations, auditLog),
+	}
+	// 受变更审批保护的记录只能通过审批修改，自动写入记录的组件遇到时拒绝写入
+	var protects func(domain, rr string) bool
+	if approvals != nil {
+		handlers.Approval = handler.NewApprovalHandler(approvals)
+		protects = approvals.Protects
+	}
+
+	// 初始化 ACME 验证接口
+	if len(cfg.Acme.Tokens) > 0 {
+		tokens := make([]acme.Token, 0, len(cfg.Acme.Tokens))
+		for _, t := range cfg.Acme.Tokens {
+			tokens = append(tokens, acme.Token{
+				Name:       t.Name,
+				Username:   t.Username,
+				Password:   t.Password,
+				Domains:    t.Domains,
+				Subdomains: t.Subdomains,
+			})
+		}
+		acmeManager := acme.NewManager(dnsService, &acme.Options{
+			TTL:        cfg.Acme.ChallengeTTL,
+			StaleAfter: cfg.Acme.StaleAfter,
+			Protects:   protects,
+		})
+		go acmeManager.Run(context.Background())
+		handlers.Acme = handler.NewAcmeHandler(acmeManager, tokens)
+	}
+
+	// 初始化 external-dns webhook
+	if cfg.ExternalDNS.Enabled {
+		provider := externaldns.NewProvider(dnsService, &externaldns.Options{
+			Filter: externaldns.DomainFilter{
+				Include: cfg.ExternalDNS.DomainFilters,
+				Exclude: cfg.ExternalDNS.ExcludeDomains,
+			},
+			MinTTL:   cfg.ExternalDNS.MinTTL,
+			Protects: protects,
+		})
+		handlers.ExternalDNS = handler.NewExternalDNSHandler(provider)
+	}
+
+	// 初始化 Kubernetes 注解控制器
+	if cfg.Kubernetes.Enabled {
+		ctrl, err := newController(&cfg.Kubernetes, dnsService, protects)
+		if err != nil {
+			log.Fatal("初始化Kubernetes控制器失败", zap.Error(err))
+		}
+		go func() {
+			if err := ctrl.Run(context.Background()); err != nil {
+				log.Error("Kubernetes控制器异常退出", zap.Error(err))
+			}
+		}()
+	}
+
+	// 初始化 Docker 容器标签监听
+	if cfg.Docker.Enabled {
+		client, err := docker.NewClient(cfg.Docker.Host)
+		if err != nil {
+			log.Fatal("初始化Docker客户端失败", zap.Error(err))
+		}
+		watcher, err := docker.NewWatcher(client, dnsService, &docker.Options{
+			HostIP:   cfg.Docker.HostIP,
+			Owner:    cfg.Docker.Owner,
+			TTL:      cfg.Docker.TTL,
+			Resync:   cfg.Docker.Resync,
+			Protects: protects,
+		})
+		if err != nil {
+			log.Fatal("初始化Docker容器监听失败", zap.Error(err))
+		}
+		go watcher.Run(context.Background())
+	}
+
+	// 初始化健康检查故障转移
+	if len(cfg.Failover.Groups) > 0 {
+		failoverManager, err := failover.NewManager(dnsService, notifier, failoverGroups(cfg.Failover.Groups))
+		if err != nil {
+			log.Fatal("初始化故障转移失败", zap.Error(err))
+		}
+		go failoverManager.Run(context.Background())
+		handlers.Failover = handler.NewFailoverHandler(failoverManager)
+	}
 
 	// 初始化路由
-	r := handler.InitRouter(dnsHandler)
+	tokens := make([]middleware.Token, 0, len(cfg.Auth.Tokens))
+	for _, t := range cfg.Auth.Tokens {
+		tokens = append(tokens, middleware.Token{Name: t.Name, Token: t.Token})
+	}
+	r := handler.InitRouter(handlers, tokens, cfg.Logging.Admins)
 
 	// 添加中间件
 	r.Use(middleware.RequestTimer())
@@ -65,3 +314,129 @@ func main() {
 		log.Fatal("启动服务失败", zap.Error(err))
 	}
 }
+
+// newController 创建 Kubernetes 注解控制器，未配置 kubeconfig 时使用集群内配置
+func newController(cfg *config.KubernetesConfig, dnsService *service.DNSService, protects func(domain, rr string) bool) (*controller.Controller, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", cfg.Kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("加载Kubernetes配置失败: %w", err)
+	}
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("创建Kubernetes客户端失败: %w", err)
+	}
+	dyn, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("创建Kubernetes客户端失败: %w", err)
+	}
+
+	return controller.New(kube, dyn, dnsService, &controller.Options{
+		Namespace: cfg.Namespace,
+		Gateway:   cfg.Gateway,
+		TTL:       cfg.TTL,
+		Resync:    cfg.Resync,
+		Workers:   cfg.Workers,
+		Protects:  protects,
+	}), nil
+}
+
+// policyRules 将配置转换为写操作策略规则
+func policyRules(rules []config.PolicyRule) []policy.Rule {
+	result := make([]policy.Rule, 0, len(rules))
+	for _, r := range rules {
+		windows := make([]policy.Window, 0, len(r.Windows))
+		for _, w := range r.Windows {
+			windows = append(windows, policy.Window{
+				Start: w.Start,
+				End:   w.End,
+				Days:  w.Days,
+				From:  w.From,
+				To:    w.To,
+			})
+		}
+		result = append(result, policy.Rule{
+			Name:           r.Name,
+			Timezone:       r.Timezone,
+			Windows:        windows,
+			Domains:        r.Domains,
+			RR:             r.RR,
+			Types:          r.Types,
+			Actions:        r.Actions,
+			AllowedActors:  r.AllowedActors,
+			Deny:           r.Deny,
+			ForbidDelete:   r.ForbidDelete,
+			MaxTTLDecrease: r.MaxTTLDecrease,
+			Message:        r.Message,
+		})
+	}
+	return result
+}
+
+// logConfig 将配置转换为日志配置
+func logConfig(c *config.LoggingConfig) logger.LogConfig {
+	return logger.LogConfig{
+		Level:      c.Level,
+		Format:     c.Format,
+		Outputs:    c.Output,
+		LogPath:    c.File.Path,
+		MaxSize:    c.File.MaxSize,
+		MaxBackups: c.File.MaxBackups,
+		MaxAge:     c.File.MaxAge,
+		Compress:   c.File.Compress,
+		Syslog: logger.SyslogConfig{
+			Network: c.Syslog.Network,
+			Address: c.Syslog.Address,
+			Tag:     c.Syslog.Tag,
+		},
+		Sampling: logger.SamplingConfig{
+			Initial:    c.Sampling.Initial,
+			Thereafter: c.Sampling.Thereafter,
+			Tick:       c.Sampling.Tick,
+		},
+	}
+}
+
+// subcommandOutputs 子命令的日志不输出到标准输出，改为输出到标准错误
+func subcommandOutputs(outputs []string) []string {
+	result := make([]string, 0, len(outputs))
+	for _, output := range outputs {
+		if output == logger.OutputStdout {
+			output = logger.OutputStderr
+		}
+		if !slices.Contains(result, output) {
+			result = append(result, output)
+		}
+	}
+	return result
+}
+
+// failoverGroups 将配置转换为故障转移组
+func failoverGroups(groups []config.FailoverGroup) []failover.Group {
+	result := make([]failover.Group, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, failover.Group{
+			Name:     g.Name,
+			Domain:   g.Domain,
+			RR:       g.RR,
+			Type:     g.Type,
+			Mode:     g.Mode,
+			Primary:  g.Primary,
+			Backups:  g.Backups,
+			TTL:      g.TTL,
+			Interval: g.Interval,
+			Fall:     g.Fall,
+			Rise:     g.Rise,
+			Check: failover.CheckConfig{
+				Type:     g.Check.Type,
+				Port:     g.Check.Port,
+				Scheme:   g.Check.Scheme,
+				Path:     g.Check.Path,
+				Host:     g.Check.Host,
+				Expect:   g.Check.Expect,
+				Insecure: g.Check.Insecure,
+				Timeout:  g.Check.Timeout,
+			},
+		})
+	}
+	return result
+}
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/domains": {
-            "get": {
-                "description": "获取账户下所有的域名列表",
+        "/acme-dns/update": {
+            "post": {
+                "description": "兼容 acme-dns 的 update 接口，使用 X-Api-User/X-Api-Key 认证。subdomain 为凭证 subdomains 中登记的\nacme-dns 账户 subdomain，或直接填写证书域名，验证记录写入 _acme-challenge.\u003c证书域名\u003e，每个名称保留最近的两个值",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,31 +25,77 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "domain-management"
+                    "acme"
+                ],
+                "summary": "设置ACME验证记录（acme-dns）",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户名",
+                        "name": "X-Api-User",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "密码",
+                        "name": "X-Api-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "验证信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AcmeDNSUpdateRequest"
+                        }
+                    }
                 ],
-                "summary": "获取域名列表",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/service.Domain"
-                            }
+                            "$ref": "#/definitions/handler.AcmeDNSUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/domains/{domain}/records": {
-            "get": {
-                "description": "获取指定域名的所有解析记录",
+        "/acme/httpreq/cleanup": {
+            "post": {
+                "description": "兼容 lego httpreq 的 cleanup 接口，只删除值相同的验证记录，同名的其他验证记录不受影响",
                 "consumes": [
                     "application/json"
                 ],
@@ -57,48 +103,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "record-management"
+                    "acme"
                 ],
-                "summary": "获取域名解析记录",
+                "summary": "删除ACME验证记录",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "域名",
-                        "name": "domain",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "maximum": 500,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "每页记录数，默认20",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "验证信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPReqRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/service.DomainRecord"
-                            }
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/domains/{domain}/records/id/{record_id}": {
-            "get": {
-                "description": "根据记录ID查询单个域名解析记录",
+        "/acme/httpreq/present": {
+            "post": {
+                "description": "兼容 lego httpreq 的 present 接口，使用 Basic 认证，只能为凭证允许的域名创建 _acme-challenge TXT 记录",
                 "consumes": [
                     "application/json"
                 ],
@@ -106,110 +164,95 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "record-query"
+                    "acme"
                 ],
-                "summary": "按记录ID查询解析记录",
+                "summary": "创建ACME验证记录",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "域名",
-                        "name": "domain",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "解析记录ID",
-                        "name": "record_id",
-                        "in": "path",
-                        "required": true
+                        "description": "验证信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPReqRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/service.DomainRecord"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/domains/{domain}/records/rr/{rr}": {
+        "/admin/log-levels": {
             "get": {
-                "description": "根据主机记录查询域名解析记录",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "返回全局（root）和各组件的日志级别，组件未单独设置时跟随全局级别",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "record-query"
-                ],
-                "summary": "按主机记录查询解析记录",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "域名",
-                        "name": "domain",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "主机记录",
-                        "name": "rr",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "获取日志级别",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/service.DomainRecord"
+                                "$ref": "#/definitions/logger.LevelStatus"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/domains/{domain}/records/search": {
-            "get": {
-                "description": "根据多个条件搜索域名解析记录",
+        "/admin/log-levels/{component}": {
+            "put": {
+                "description": "设置全局（root）或组件的日志级别，指定 duration 时为临时调整，到期后自动恢复到调整前的级别",
                 "consumes": [
                     "application/json"
                 ],
@@ -217,201 +260,4236 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "record-query"
+                    "admin"
                 ],
-                "summary": "搜索域名解析记录",
+                "summary": "设置日志级别",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "域名",
-                        "name": "domain",
+                        "description": "组件(root/handler/service/scheduler)",
+                        "name": "component",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "解析记录ID",
-                        "name": "record_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "主机记录",
-                        "name": "rr",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "记录类型",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "状态(Enable/Disable)",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 500,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "每页记录数，默认20",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "日志级别",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetLogLevelRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/service.DomainRecord"
-                            }
+                            "$ref": "#/definitions/logger.LevelStatus"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/domains/{domain}/records/status/{status}": {
-            "get": {
-                "description": "查询指定域名下所有特定状态的解析记录",
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "取消临时调整，组件恢复为跟随全局级别，全局级别恢复为配置文件中的级别",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "record-query"
+                    "admin"
                 ],
-                "summary": "按记录状态查询解析记录",
+                "summary": "重置日志级别",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "域名",
-                        "name": "domain",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "状态(Enable/Disable)",
-                        "name": "status",
+                        "description": "组件(root/handler/service/scheduler)",
+                        "name": "component",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "maximum": 500,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "每页记录数，默认20",
-                        "name": "page_size",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/service.DomainRecord"
-                            }
+                            "$ref": "#/definitions/logger.LevelStatus"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/domains/{domain}/records/type/{type}": {
+        "/approvals": {
             "get": {
-                "description": "根据记录类型查询域名解析记录",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "按提交时间倒序返回受保护记录的变更请求",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "record-query"
+                    "approval"
                 ],
-                "summary": "按记录类型查询解析记录",
+                "summary": "获取变更请求",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "域名",
-                        "name": "domain",
-                        "in": "path",
-                        "required": true
+                        "description": "状态(pending/applying/applied/failed/rejected/expired)",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "记录类型",
-                        "name": "type",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "maximum": 500,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "每页记录数，默认20",
-                        "name": "page_size",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/approval.Change"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/approvals/{change_id}": {
+            "get": {
+                "description": "返回变更请求及提交时相对于当前解析记录的差异",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approval"
+                ],
+                "summary": "查询变更请求",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "变更请求ID",
+                        "name": "change_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/approval.Change"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/approvals/{change_id}/approve": {
+            "post": {
+                "description": "需要由提交人以外的已授权身份审批，通过后以原子方式执行全部操作；\n提交后解析记录已被修改时不执行，变更请求标记为失败",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approval"
+                ],
+                "summary": "审批通过变更请求",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "变更请求ID",
+                        "name": "change_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "审批意见",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DecisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/approval.Change"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/approvals/{change_id}/reject": {
+            "post": {
+                "description": "需要由提交人以外的已授权身份操作",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approval"
+                ],
+                "summary": "拒绝变更请求",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "变更请求ID",
+                        "name": "change_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "拒绝原因",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DecisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/approval.Change"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "description": "按时间倒序返回最近的审计日志",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "查询审计日志",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "操作人",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作，前缀匹配，如 approval.",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "条数，默认100，最大1000",
+                        "name": "limit",
                         "in": "query"
                     }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/service.DomainRecord"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "string"
-                        }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/audit.Entry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains": {
+            "get": {
+                "description": "获取账户下所有的域名列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "domain-management"
+                ],
+                "summary": "获取域名列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Domain"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/custom-lines": {
+            "get": {
+                "description": "获取域名下按地址段定义的自定义线路（需要企业版）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "line"
+                ],
+                "summary": "获取自定义线路",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.CustomLine"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "按CIDR、单个地址或起止地址定义线路，地址段不能与其他自定义线路重叠。\n返回的线路代码可用于创建和修改解析记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "line"
+                ],
+                "summary": "添加自定义线路",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "自定义线路",
+                        "name": "line",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CustomLineInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.CustomLine"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/custom-lines/{line_id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "line"
+                ],
+                "summary": "查询自定义线路",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "线路ID",
+                        "name": "line_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.CustomLine"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "修改线路名称并整体替换地址段，地址段不能与其他自定义线路重叠",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "line"
+                ],
+                "summary": "修改自定义线路",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "线路ID",
+                        "name": "line_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "自定义线路",
+                        "name": "line",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CustomLineInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "line"
+                ],
+                "summary": "删除自定义线路",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "线路ID",
+                        "name": "line_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/line-records/{rr}/{type}": {
+            "get": {
+                "description": "将主机记录和类型下的解析记录按线路归并为一条逻辑记录",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "line"
+                ],
+                "summary": "获取分线路解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录",
+                        "name": "rr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.LineRecordSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "按线路指定记录值，展开为各线路的解析记录并与现有记录比对后增删改，必须包含默认线路。\n未列出的线路上的记录会被删除；中途失败时已完成的修改不会回滚，重新提交即可继续",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "line"
+                ],
+                "summary": "设置分线路解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录",
+                        "name": "rr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "各线路的记录值",
+                        "name": "set",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.LineRecordSetInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.LineRecordSetResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/lines": {
+            "get": {
+                "description": "获取域名版本支持的解析线路（包括自定义线路），tree=true时按父线路组织为树",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "line"
+                ],
+                "summary": "获取解析线路",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否按树形结构返回",
+                        "name": "tree",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.RecordLine"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/lint": {
+            "get": {
+                "description": "分析域名的解析记录，检查CNAME冲突、悬空CNAME、重复记录、缺失的SPF/DMARC/CAA以及TTL问题",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-management"
+                ],
+                "summary": "检查域名解析配置",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "低于该值的TTL视为过低，默认60",
+                        "name": "min_ttl",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/lint.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/migrations": {
+            "post": {
+                "description": "按顺序执行：降低TTL -\u003e 等待原TTL的缓存过期 -\u003e 修改记录值 -\u003e 等待新值生效并观察 -\u003e 恢复TTL。\n状态保存在数据目录中，服务重启后从当前阶段继续。\n迁移以创建者的身份执行，创建时先做策略检查，不允许修改的记录返回403",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "migration"
+                ],
+                "summary": "创建迁移",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "迁移计划",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/migration.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/migration.Migration"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records": {
+            "get": {
+                "description": "获取指定域名的所有解析记录。默认返回完整数组；指定page/per_page/cursor时返回RecordListResponse分页信封；\nformat=ndjson或Accept为application/x-ndjson时按行流式输出，中途失败时最后一行为StreamErrorLine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "record-management"
+                ],
+                "summary": "获取域名解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "上游接口每页记录数，默认500",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "页码，指定后返回分页信封",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页返回的记录数，默认100",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的next_cursor，需与原查询条件一起使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "输出格式(json/ndjson)，ndjson时逐行流式输出",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.DomainRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "为指定域名添加解析记录，提交前按记录类型校验记录值和解析线路。\n指定 expires_at 或 ttl_minutes 时记录为临时记录，到期后自动删除。\n受变更审批保护的记录转为待审批的变更请求，返回202",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-management"
+                ],
+                "summary": "添加解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "解析记录",
+                        "name": "record",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateRecordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateRecordResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/approval.Change"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/batch": {
+            "post": {
+                "description": "批量执行 create/update/delete/enable/disable 操作并返回每个操作的结果。\natomic 为 true 时任一操作失败将回滚已成功的操作；操作数超过50或 async=true 时以异步任务执行并返回202。\n涉及受保护记录时整批操作转为待审批的变更请求，返回202",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-management"
+                ],
+                "summary": "批量操作解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否以异步任务执行",
+                        "name": "async",
+                        "in": "query"
+                    },
+                    {
+                        "description": "批量操作",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/batch.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/batch.Result"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/batch.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/batch/{job_id}": {
+            "get": {
+                "description": "查询异步批量操作任务的状态和结果",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-management"
+                ],
+                "summary": "查询批量操作任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/batch.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/id/{record_id}": {
+            "get": {
+                "description": "根据记录ID查询单个域名解析记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-query"
+                ],
+                "summary": "按记录ID查询解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析记录ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.DomainRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "修改指定的解析记录，提交前按记录类型校验记录值，未指定线路时保持原线路。\n受变更审批保护的记录转为待审批的变更请求，返回202",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-management"
+                ],
+                "summary": "修改解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析记录ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "解析记录",
+                        "name": "record",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.DomainRecordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/approval.Change"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "删除指定的解析记录，受变更审批保护的记录转为待审批的变更请求，返回202",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-management"
+                ],
+                "summary": "删除解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析记录ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/approval.Change"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/id/{record_id}/lease": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lease"
+                ],
+                "summary": "查询解析记录的租约",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析记录ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/lease.Lease"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "延长或缩短临时记录的租约；记录没有租约时将其设为临时记录。\n到期时以设置租约的调用方身份删除记录，设置时先检查策略是否允许删除",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lease"
+                ],
+                "summary": "设置解析记录的租约",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析记录ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "到期时间",
+                        "name": "expiry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lease.Expiry"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/lease.Lease"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "移除后记录不再自动删除",
+                "tags": [
+                    "lease"
+                ],
+                "summary": "移除解析记录的租约",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析记录ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/id/{record_id}/status": {
+            "put": {
+                "description": "启用或暂停指定的解析记录，受变更审批保护的记录转为待审批的变更请求，返回202",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-management"
+                ],
+                "summary": "设置解析记录状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析记录ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "状态(Enable/Disable)",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetRecordStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/approval.Change"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/rr/{rr}": {
+            "get": {
+                "description": "根据主机记录查询域名解析记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "record-query"
+                ],
+                "summary": "按主机记录查询解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录",
+                        "name": "rr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "页码，指定后返回分页信封",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页返回的记录数，默认100",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的next_cursor，需与原查询条件一起使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "输出格式(json/ndjson)，ndjson时逐行流式输出",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.DomainRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/search": {
+            "get": {
+                "description": "根据多个条件搜索域名解析记录，支持 DescribeDomainRecords 的全部过滤参数，条件可任意组合。\nrr_keyword/type_keyword/value_keyword 只在 ADVANCED 模式下生效，未指定 search_mode 时自动使用 ADVANCED 模式。\n分页、排序和流式输出参数与获取域名解析记录接口相同",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "record-query"
+                ],
+                "summary": "搜索域名解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析记录ID",
+                        "name": "record_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录（精确匹配）",
+                        "name": "rr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字，LIKE/EXACT模式下匹配主机记录和记录值",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录关键字",
+                        "name": "rr_keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型关键字",
+                        "name": "type_keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录值关键字",
+                        "name": "value_keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "解析线路",
+                        "name": "line",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态(Enable/Disable)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "搜索模式(LIKE/EXACT/ADVANCED)",
+                        "name": "search_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段",
+                        "name": "order_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序方向(ASC/DESC)",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "上游接口每页记录数，默认500",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "页码，指定后返回分页信封",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页返回的记录数，默认100",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的next_cursor，需与原查询条件一起使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "输出格式(json/ndjson)，ndjson时逐行流式输出",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.DomainRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/status/{status}": {
+            "get": {
+                "description": "查询指定域名下所有特定状态的解析记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "record-query"
+                ],
+                "summary": "按记录状态查询解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态(Enable/Disable)",
+                        "name": "status",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "上游接口每页记录数，默认500",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "页码，指定后返回分页信封",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页返回的记录数，默认100",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的next_cursor，需与原查询条件一起使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "输出格式(json/ndjson)，ndjson时逐行流式输出",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.DomainRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/records/type/{type}": {
+            "get": {
+                "description": "根据记录类型查询域名解析记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "record-query"
+                ],
+                "summary": "按记录类型查询解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "上游接口每页记录数，默认500",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "页码，指定后返回分页信封",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页返回的记录数，默认100",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的next_cursor，需与原查询条件一起使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "输出格式(json/ndjson)，ndjson时逐行流式输出",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.DomainRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/schedules": {
+            "post": {
+                "description": "在指定时间执行一组解析记录操作（与批量操作格式相同），限流或上游临时错误时按配置重试，\n执行结果通过通知发送。任务保存在数据目录中，服务重启后继续等待执行。\n任务以创建者的身份执行，创建时先做策略检查，不允许的操作返回403",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "创建定时变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "定时变更",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/slb": {
+            "get": {
+                "description": "获取域名下开启过权重负载均衡的子域名及其状态",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slb"
+                ],
+                "summary": "获取负载均衡子域名",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录",
+                        "name": "rr",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.SLBSubDomain"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/slb/{rr}/{type}": {
+            "get": {
+                "description": "获取子域名的负载均衡状态和各成员的权重",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slb"
+                ],
+                "summary": "获取负载均衡成员",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录",
+                        "name": "rr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型(A/AAAA/CNAME)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.SLBMembers"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/slb/{rr}/{type}/shifts": {
+            "post": {
+                "description": "在指定步数内按间隔逐步调整成员权重，目标权重为0的成员在最后一步被暂停。\n同一子域名同时只能有一个进行中的任务，任务只保存在内存中，服务重启后不会继续。\n每一步以创建者的身份执行，创建时先做策略检查，不允许时返回403",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slb"
+                ],
+                "summary": "创建流量切换任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录",
+                        "name": "rr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型(A/AAAA/CNAME)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "切换计划",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/slb.ShiftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/slb.Shift"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/slb/{rr}/{type}/status": {
+            "put": {
+                "description": "开启或关闭子域名的权重负载均衡，关闭后按轮询返回所有记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slb"
+                ],
+                "summary": "开启或关闭负载均衡",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录",
+                        "name": "rr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型(A/AAAA/CNAME)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "状态",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetSLBStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{domain}/slb/{rr}/{type}/weights": {
+            "put": {
+                "description": "按记录值设置成员的权重，子域名未开启负载均衡时自动开启",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slb"
+                ],
+                "summary": "设置负载均衡权重",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录",
+                        "name": "rr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型(A/AAAA/CNAME)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "权重",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetSLBWeightsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/external-dns": {
+            "get": {
+                "description": "返回 webhook 管理的域名过滤条件",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external-dns"
+                ],
+                "summary": "external-dns 协商",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/externaldns.DomainFilter"
+                        }
+                    }
+                }
+            }
+        },
+        "/external-dns/adjustendpoints": {
+            "post": {
+                "description": "将期望的端点规范化为与获取记录接口一致的形式",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external-dns"
+                ],
+                "summary": "external-dns 规范化端点",
+                "parameters": [
+                    {
+                        "description": "端点",
+                        "name": "endpoints",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/externaldns.Endpoint"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/externaldns.Endpoint"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/external-dns/records": {
+            "get": {
+                "description": "返回过滤范围内的所有端点，同一名称和类型的多条记录合并为一个端点",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external-dns"
+                ],
+                "summary": "external-dns 获取记录",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/externaldns.Endpoint"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "按删除、更新、创建的顺序执行 external-dns 计划的变更",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external-dns"
+                ],
+                "summary": "external-dns 执行变更",
+                "parameters": [
+                    {
+                        "description": "变更",
+                        "name": "changes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/externaldns.Changes"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/failover": {
+            "get": {
+                "description": "返回所有故障转移组的目标健康状态和当前生效的值",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "failover"
+                ],
+                "summary": "获取故障转移组状态",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/failover.GroupStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/failover/{name}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "failover"
+                ],
+                "summary": "获取单个故障转移组状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "故障转移组名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/failover.GroupStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/leases": {
+            "get": {
+                "description": "按到期时间返回所有临时记录的租约",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lease"
+                ],
+                "summary": "获取临时记录",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/lease.Lease"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/migrations": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "migration"
+                ],
+                "summary": "获取迁移",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/migration.Migration"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/migrations/{migration_id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "migration"
+                ],
+                "summary": "查询迁移",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "迁移ID",
+                        "name": "migration_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/migration.Migration"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/migrations/{migration_id}/abort": {
+            "post": {
+                "description": "记录值尚未修改时恢复原TTL；已修改记录值时保持当前状态",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "migration"
+                ],
+                "summary": "中止迁移",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "迁移ID",
+                        "name": "migration_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/migration.Migration"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/migrations/{migration_id}/pause": {
+            "post": {
+                "description": "暂停后不再进入下一阶段，正在等待的时间照常计算",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "migration"
+                ],
+                "summary": "暂停迁移",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "迁移ID",
+                        "name": "migration_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/migration.Migration"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/migrations/{migration_id}/resume": {
+            "post": {
+                "description": "恢复暂停或失败的迁移，失败的步骤会重新执行",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "migration"
+                ],
+                "summary": "恢复迁移",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "迁移ID",
+                        "name": "migration_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/migration.Migration"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/records/search": {
+            "get": {
+                "description": "在账户下所有域名中搜索解析记录，结果按域名分组，单个域名查询失败时在该域名的结果中返回错误",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "record-query"
+                ],
+                "summary": "跨域名搜索解析记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "记录值关键字",
+                        "name": "value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "记录值是否精确匹配",
+                        "name": "value_exact",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "主机记录，支持*和?通配符",
+                        "name": "rr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态(Enable/Disable)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 20,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "并发查询的域名数，默认5",
+                        "name": "concurrency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.CrossDomainResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "description": "按执行时间返回定时变更，可按状态和域名过滤",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "获取定时变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "状态(scheduled/running/succeeded/failed/canceled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "域名",
+                        "name": "domain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Job"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{job_id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "查询定时变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "取消尚未执行或等待重试的定时变更",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "取消定时变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/slb/shifts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slb"
+                ],
+                "summary": "获取流量切换任务",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/slb.Shift"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/slb/shifts/{shift_id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slb"
+                ],
+                "summary": "查询流量切换任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "shift_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/slb.Shift"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "中止进行中的任务，已调整的权重保持不变",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slb"
+                ],
+                "summary": "中止流量切换任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "shift_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/slb.Shift"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apperror.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "apperror.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperror.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "upstream_request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "approval.Change": {
+            "type": "object",
+            "properties": {
+                "approver": {
+                    "description": "审批或拒绝的身份",
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decided_at": {
+                    "type": "string"
+                },
+                "diff": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/approval.DiffEntry"
+                    }
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.Operation"
+                    }
+                },
+                "requester": {
+                    "type": "string"
+                },
+                "result": {
+                    "$ref": "#/definitions/batch.Result"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "approval.DiffEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/batch.Action"
+                },
+                "after": {
+                    "description": "create/update 操作提交的记录",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.DomainRecordInput"
+                        }
+                    ]
+                },
+                "before": {
+                    "description": "提交时的解析记录，create 操作为空",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.DomainRecord"
+                        }
+                    ]
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/approval.FieldChange"
+                    }
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "record_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "approval.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Entry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "如 approval.approved",
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "操作人，未认证时为空",
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "target": {
+                    "description": "操作对象，如变更请求ID",
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "batch.Action": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "enable",
+                "disable"
+            ],
+            "x-enum-varnames": [
+                "ActionCreate",
+                "ActionUpdate",
+                "ActionDelete",
+                "ActionEnable",
+                "ActionDisable"
+            ]
+        },
+        "batch.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "result": {
+                    "$ref": "#/definitions/batch.Result"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "batch.Operation": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/batch.Action"
+                },
+                "record": {
+                    "description": "create/update 必填",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.DomainRecordInput"
+                        }
+                    ]
+                },
+                "record_id": {
+                    "description": "update/delete/enable/disable 必填",
+                    "type": "string"
+                }
+            }
+        },
+        "batch.OperationResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/batch.Action"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "record_id": {
+                    "type": "string"
+                },
+                "restored_record_id": {
+                    "description": "RestoredRecordId 回滚删除操作时重新创建的记录ID，原记录ID已失效",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "batch.Request": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "任一操作失败时回滚已成功的操作",
+                    "type": "boolean"
+                },
+                "concurrency": {
+                    "description": "并发度，默认5，最大20",
+                    "type": "integer"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.Operation"
+                    }
+                }
+            }
+        },
+        "batch.Result": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.OperationResult"
+                    }
+                },
+                "rolled_back": {
+                    "type": "boolean"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "externaldns.Changes": {
+            "type": "object",
+            "properties": {
+                "Create": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/externaldns.Endpoint"
+                    }
+                },
+                "Delete": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/externaldns.Endpoint"
+                    }
+                },
+                "UpdateNew": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/externaldns.Endpoint"
+                    }
+                },
+                "UpdateOld": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/externaldns.Endpoint"
+                    }
+                }
+            }
+        },
+        "externaldns.DomainFilter": {
+            "type": "object",
+            "properties": {
+                "exclude": {
+                    "description": "排除这些域名及其子域名",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "include": {
+                    "description": "只管理这些域名及其子域名，为空表示全部",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "externaldns.Endpoint": {
+            "type": "object",
+            "properties": {
+                "dnsName": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "providerSpecific": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/externaldns.ProviderSpecificProperty"
+                    }
+                },
+                "recordTTL": {
+                    "type": "integer"
+                },
+                "recordType": {
+                    "type": "string"
+                },
+                "setIdentifier": {
+                    "type": "string"
+                },
+                "targets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "externaldns.ProviderSpecificProperty": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "failover.GroupStatus": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "当前生效的值",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_sync": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rr": {
+                    "type": "string"
+                },
+                "targets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/failover.TargetStatus"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "failover.TargetStatus": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "consecutive_successes": {
+                    "type": "integer"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "last_change": {
+                    "type": "string"
+                },
+                "last_check": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AcmeDNSUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "subdomain": {
+                    "description": "acme-dns 账户的 subdomain（需在凭证中登记）或证书域名",
+                    "type": "string"
+                },
+                "txt": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AcmeDNSUpdateResponse": {
+            "type": "object",
+            "properties": {
+                "txt": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateRecordRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "到期时间，RFC3339 格式",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "解析线路，为空表示默认线路，修改时为空会重置为默认线路",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "仅MX记录使用",
+                    "type": "integer"
+                },
+                "rr": {
+                    "type": "string"
+                },
+                "ttl": {
+                    "description": "0 表示使用默认值",
+                    "type": "integer"
+                },
+                "ttl_minutes": {
+                    "description": "从现在起多少分钟后到期",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateRecordResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "record_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DecisionRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.HTTPReqRequest": {
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string"
+                },
+                "fqdn": {
+                    "type": "string"
+                },
+                "keyAuth": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SetLogLevelRequest": {
+            "type": "object",
+            "required": [
+                "level"
+            ],
+            "properties": {
+                "duration": {
+                    "description": "临时调整的时长，到期后自动恢复，为空表示一直有效",
+                    "type": "string",
+                    "example": "15m"
+                },
+                "level": {
+                    "description": "debug、info、warn、error",
+                    "type": "string",
+                    "example": "debug"
+                }
+            }
+        },
+        "handler.SetRecordStatusRequest": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SetSLBStatusRequest": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "description": "解析线路，为空表示默认线路",
+                    "type": "string"
+                },
+                "open": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.SetSLBWeightsRequest": {
+            "type": "object",
+            "properties": {
+                "weights": {
+                    "description": "按记录值指定权重(1-100)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "lease.Expiry": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "到期时间，RFC3339 格式",
+                    "type": "string"
+                },
+                "ttl_minutes": {
+                    "description": "从现在起多少分钟后到期",
+                    "type": "integer"
+                }
+            }
+        },
+        "lease.Lease": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "最近一次设置租约的调用方，到期时按其身份做策略检查",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Actor"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "record_id": {
+                    "type": "string"
+                },
+                "rr": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "lint.Issue": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "record_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rr": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "severity": {
+                    "$ref": "#/definitions/lint.Severity"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "lint.Report": {
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/lint.Issue"
+                    }
+                },
+                "record_count": {
+                    "type": "integer"
+                },
+                "summary": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "lint.Severity": {
+            "type": "string",
+            "enum": [
+                "error",
+                "warning",
+                "info"
+            ],
+            "x-enum-varnames": [
+                "SeverityError",
+                "SeverityWarning",
+                "SeverityInfo"
+            ]
+        },
+        "logger.LevelStatus": {
+            "type": "object",
+            "properties": {
+                "component": {
+                    "type": "string"
+                },
+                "inherited": {
+                    "description": "是否跟随全局级别",
+                    "type": "boolean"
+                },
+                "level": {
+                    "description": "当前生效的级别",
+                    "type": "string"
+                },
+                "revert_to": {
+                    "description": "临时调整恢复后的级别，为空表示跟随全局级别",
+                    "type": "string"
+                },
+                "until": {
+                    "description": "临时调整的恢复时间",
+                    "type": "string"
+                }
+            }
+        },
+        "migration.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "hold": {
+                    "description": "修改记录值后、恢复TTL前额外观察的时长，如 30m",
+                    "type": "string"
+                },
+                "low_ttl": {
+                    "description": "迁移期间的TTL，0 表示使用默认值",
+                    "type": "integer"
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/migration.RecordRequest"
+                    }
+                },
+                "restore_ttl": {
+                    "description": "迁移完成后的TTL，0 表示恢复原TTL",
+                    "type": "integer"
+                }
+            }
+        },
+        "migration.Event": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "phase": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "migration.Migration": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "创建迁移的调用方，每一步按其身份做策略检查",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Actor"
+                        }
+                    ]
+                },
+                "attempts": {
+                    "description": "当前步骤已失败的次数",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/migration.Event"
+                    }
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "hold_seconds": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "low_ttl": {
+                    "type": "integer"
+                },
+                "phase": {
+                    "type": "string"
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/migration.Record"
+                    }
+                },
+                "restore_ttl": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "wait_until": {
+                    "description": "当前阶段最早在该时间之后继续",
+                    "type": "string"
+                }
+            }
+        },
+        "migration.Record": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "string"
+                },
+                "original_ttl": {
+                    "type": "integer"
+                },
+                "original_value": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "record_id": {
+                    "type": "string"
+                },
+                "rr": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "migration.RecordRequest": {
+            "type": "object",
+            "properties": {
+                "record_id": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "新的记录值",
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "任一操作失败时回滚已成功的操作",
+                    "type": "boolean"
+                },
+                "max_attempts": {
+                    "description": "最多执行次数，0 表示使用默认值",
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "operations": {
+                    "description": "与批量操作相同的操作列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.Operation"
+                    }
+                },
+                "run_at": {
+                    "description": "执行时间，RFC3339 格式",
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.Job": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "创建任务的调用方，执行时按其身份做策略检查",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Actor"
+                        }
+                    ]
+                },
+                "atomic": {
+                    "type": "boolean"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "next_run_at": {
+                    "description": "下一次重试的时间",
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.Operation"
+                    }
+                },
+                "results": {
+                    "description": "各操作最近一次的执行结果",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.OperationResult"
+                    }
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Actor": {
+            "type": "object",
+            "properties": {
+                "break_glass": {
+                    "description": "紧急变更的原因，非空时可以越过策略限制，并写入审计日志",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "调用方身份，内部组件（故障转移、控制器等）为空",
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.CrossDomainResult": {
+            "type": "object",
+            "properties": {
+                "domains_failed": {
+                    "type": "integer"
+                },
+                "domains_searched": {
+                    "type": "integer"
+                },
+                "matched_records": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.DomainSearchResult"
+                    }
+                }
+            }
+        },
+        "service.CustomLine": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "创建和修改解析记录时使用的线路代码",
+                    "type": "string"
+                },
+                "domain_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.IPSegment"
+                    }
+                }
+            }
+        },
+        "service.CustomLineInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "segments": {
+                    "description": "CIDR(10.0.0.0/8)、单个地址或起止地址(10.0.0.1-10.0.0.9)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
         "service.Domain": {
             "type": "object",
             "properties": {
@@ -425,6 +4503,11 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "puny_code": {
+                    "description": "ASCII 形式",
+                    "type": "string"
+                },
+                "unicode_name": {
+                    "description": "Unicode 形式",
                     "type": "string"
                 }
             }
@@ -432,6 +4515,9 @@ const docTemplate = `{
         "service.DomainRecord": {
             "type": "object",
             "properties": {
+                "domain_name": {
+                    "type": "string"
+                },
                 "line": {
                     "type": "string"
                 },
@@ -444,9 +4530,16 @@ const docTemplate = `{
                 "record_id": {
                     "type": "string"
                 },
+                "remark": {
+                    "type": "string"
+                },
                 "rr": {
                     "type": "string"
                 },
+                "rr_unicode": {
+                    "description": "国际化主机记录的 Unicode 形式",
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
                 },
@@ -456,6 +4549,304 @@ const docTemplate = `{
                 "type": {
                     "type": "string"
                 },
+                "value": {
+                    "type": "string"
+                },
+                "value_unicode": {
+                    "description": "主机名类型记录值的 Unicode 形式",
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "负载均衡权重，开启权重负载均衡后有效",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.DomainRecordInput": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "description": "解析线路，为空表示默认线路，修改时为空会重置为默认线路",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "仅MX记录使用",
+                    "type": "integer"
+                },
+                "rr": {
+                    "type": "string"
+                },
+                "ttl": {
+                    "description": "0 表示使用默认值",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.DomainSearchResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "domain_name": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.DomainRecord"
+                    }
+                }
+            }
+        },
+        "service.IPSegment": {
+            "type": "object",
+            "properties": {
+                "end_ip": {
+                    "type": "string"
+                },
+                "start_ip": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.LineRecordSet": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "description": "线路代码 -\u003e 记录值",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "priority": {
+                    "description": "仅MX记录使用",
+                    "type": "integer"
+                },
+                "rr": {
+                    "type": "string"
+                },
+                "ttl": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.LineRecordSetInput": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "description": "线路代码 -\u003e 记录值",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "priority": {
+                    "description": "仅MX记录使用",
+                    "type": "integer"
+                },
+                "ttl": {
+                    "description": "0 表示使用默认值",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.LineRecordSetResult": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "integer"
+                },
+                "deleted": {
+                    "type": "integer"
+                },
+                "set": {
+                    "$ref": "#/definitions/service.LineRecordSet"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.RecordLine": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.RecordLine"
+                    }
+                },
+                "code": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "father_code": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.SLBLine": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "string"
+                },
+                "open": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.SLBMembers": {
+            "type": "object",
+            "properties": {
+                "open": {
+                    "type": "boolean"
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.DomainRecord"
+                    }
+                },
+                "sub_domain": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.SLBSubDomain": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "description": "各解析线路的负载均衡状态",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SLBLine"
+                    }
+                },
+                "open": {
+                    "type": "boolean"
+                },
+                "record_count": {
+                    "type": "integer"
+                },
+                "sub_domain": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "slb.Shift": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "next_step_at": {
+                    "type": "string"
+                },
+                "rr": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "step": {
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "integer"
+                },
+                "targets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/slb.ShiftTarget"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "slb.ShiftRequest": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "description": "每步的间隔，如 5m",
+                    "type": "string"
+                },
+                "steps": {
+                    "description": "分几步完成，默认5",
+                    "type": "integer"
+                },
+                "weights": {
+                    "description": "按记录值指定目标权重，0 表示最终暂停该记录，未指定的成员保持不变",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "slb.ShiftTarget": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "record_id": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                },
                 "value": {
                     "type": "string"
                 }
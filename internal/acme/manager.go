@@ -0,0 +1,439 @@
+package acme
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+	"dns-update/internal/validation"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// remarkPrefix 验证记录备注的前缀，后接创建时间的 Unix 时间戳，用于重启后识别过期的验证记录
+const remarkPrefix = "acme-challenge:"
+
+// acmeDNSKeep acme-dns 接口每个名称保留的验证值数量，同时申请根域名和通配符证书时需要两个
+const acmeDNSKeep = 2
+
+// RecordService 验证记录管理依赖的解析记录服务
+type RecordService interface {
+	ListDomains() ([]service.Domain, error)
+	ResolveZone(fqdn string) (string, string, error)
+	QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error)
+	AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error)
+	SetDomainRecordRemark(recordId, remark string) error
+	DeleteDomainRecord(recordId string) error
+}
+
+// Options 验证记录管理的选项
+type Options struct {
+	TTL        int64         // 验证记录的TTL
+	StaleAfter time.Duration // 超过该时长未清理的验证记录会被自动删除
+	// Protects 判断主机记录是否受变更审批保护，受保护的名称不创建或删除验证记录
+	Protects func(domain, rr string) bool
+}
+
+// DefaultOptions 默认的验证记录管理选项
+var DefaultOptions = Options{
+	TTL:        600,
+	StaleAfter: time.Hour,
+}
+
+// challenge 已创建的验证记录
+type challenge struct {
+	fqdn      string
+	domain    string
+	recordId  string
+	createdAt time.Time
+}
+
+// nameLock 单个验证记录名称的锁
+type nameLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// Manager 管理 DNS-01 验证记录
+//
+// 同一名称的操作串行执行，不同值的验证记录可以同时存在（根域名和通配符证书共用一个名称）
+type Manager struct {
+	records RecordService
+	opts    Options
+	log     *zap.Logger
+
+	mu         sync.Mutex
+	locks      map[string]*nameLock
+	challenges map[string]*challenge // 按记录ID索引
+}
+
+// NewManager 创建验证记录管理器
+func NewManager(records RecordService, opts *Options) *Manager {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	o := *opts
+	if o.TTL == 0 {
+		o.TTL = DefaultOptions.TTL
+	}
+	if o.StaleAfter <= 0 {
+		o.StaleAfter = DefaultOptions.StaleAfter
+	}
+
+	return &Manager{
+		records:    records,
+		opts:       o,
+		log:        logger.GetLogger(),
+		locks:      make(map[string]*nameLock),
+		challenges: make(map[string]*challenge),
+	}
+}
+
+// Present 创建验证记录，相同的值已存在时直接返回
+func (m *Manager) Present(fqdn, value string) error {
+	_, err := m.present(fqdn, value)
+	return err
+}
+
+// Update 按 acme-dns 的语义设置验证记录：创建新值并只保留最近的两个值
+func (m *Manager) Update(fqdn, value string) error {
+	fqdn, err := normalizeName(fqdn)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	current, err := m.present(fqdn, value)
+	if err != nil {
+		return err
+	}
+
+	unlock := m.lock(fqdn)
+	defer unlock()
+
+	domain, rr, err := m.records.ResolveZone(fqdn)
+	if err != nil {
+		return err
+	}
+	records, err := m.challengeRecords(domain, rr)
+	if err != nil {
+		return err
+	}
+
+	// 只处理本服务创建的验证记录，按创建时间从新到旧排列
+	type managed struct {
+		recordId  string
+		createdAt time.Time
+	}
+	var owned []managed
+	for _, r := range records {
+		if createdAt, ok := m.createdAt(&r); ok && r.RecordId != current {
+			owned = append(owned, managed{recordId: r.RecordId, createdAt: createdAt})
+		}
+	}
+	slices.SortFunc(owned, func(a, b managed) int {
+		return b.createdAt.Compare(a.createdAt)
+	})
+
+	for i := acmeDNSKeep - 1; i < len(owned); i++ {
+		if err := m.deleteRecord(owned[i].recordId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cleanup 删除指定值的验证记录，记录不存在时直接返回
+func (m *Manager) Cleanup(fqdn, value string) error {
+	fqdn, err := normalizeName(fqdn)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	unlock := m.lock(fqdn)
+	defer unlock()
+
+	domain, rr, err := m.records.ResolveZone(fqdn)
+	if err != nil {
+		return err
+	}
+	records, err := m.challengeRecords(domain, rr)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if r.Value != value {
+			continue
+		}
+		if err := m.checkProtected(fqdn, domain, rr); err != nil {
+			return err
+		}
+		if err := m.deleteRecord(r.RecordId); err != nil {
+			return err
+		}
+		m.log.Info("已清理ACME验证记录",
+			zap.String("fqdn", fqdn),
+			zap.String("record_id", r.RecordId),
+		)
+	}
+	return nil
+}
+
+// Run 定期清理过期的验证记录，直到 ctx 被取消
+//
+// 启动时扫描所有域名下带有本服务备注的验证记录，清理重启前遗留的过期记录；
+// 之后只检查本进程创建的验证记录
+func (m *Manager) Run(ctx context.Context) {
+	m.sweepAll()
+
+	interval := max(m.opts.StaleAfter/4, time.Minute)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.sweepTracked()
+		}
+	}
+}
+
+// present 创建验证记录并返回记录ID
+func (m *Manager) present(fqdn, value string) (string, error) {
+	fqdn, err := normalizeName(fqdn)
+	if err != nil {
+		return "", apperror.BadRequest(err.Error())
+	}
+	if err := ValidateValue(value); err != nil {
+		return "", apperror.Invalid(apperror.FieldError{Field: "value", Message: err.Error()})
+	}
+
+	unlock := m.lock(fqdn)
+	defer unlock()
+
+	domain, rr, err := m.records.ResolveZone(fqdn)
+	if err != nil {
+		return "", err
+	}
+	if err := m.checkProtected(fqdn, domain, rr); err != nil {
+		return "", err
+	}
+	records, err := m.challengeRecords(domain, rr)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range records {
+		if r.Value == value {
+			m.log.Info("ACME验证记录已存在",
+				zap.String("fqdn", fqdn),
+				zap.String("record_id", r.RecordId),
+			)
+			return r.RecordId, nil
+		}
+	}
+
+	recordId, err := m.records.AddDomainRecord(domain, &service.DomainRecordInput{
+		RR:    rr,
+		Type:  validation.TypeTXT,
+		Value: value,
+		TTL:   m.opts.TTL,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := m.records.SetDomainRecordRemark(recordId, remarkPrefix+strconv.FormatInt(now.Unix(), 10)); err != nil {
+		// 备注只用于重启后识别过期记录，失败不影响本次验证
+		m.log.Warn("设置ACME验证记录备注失败",
+			zap.String("record_id", recordId),
+			zap.Error(err),
+		)
+	}
+
+	m.mu.Lock()
+	m.challenges[recordId] = &challenge{
+		fqdn:      fqdn,
+		domain:    domain,
+		recordId:  recordId,
+		createdAt: now,
+	}
+	m.mu.Unlock()
+
+	m.log.Info("已创建ACME验证记录",
+		zap.String("fqdn", fqdn),
+		zap.String("domain", domain),
+		zap.String("record_id", recordId),
+	)
+	return recordId, nil
+}
+
+// sweepTracked 清理本进程创建的过期验证记录
+func (m *Manager) sweepTracked() {
+	deadline := time.Now().Add(-m.opts.StaleAfter)
+
+	m.mu.Lock()
+	var stale []challenge
+	for _, c := range m.challenges {
+		if c.createdAt.Before(deadline) {
+			stale = append(stale, *c)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, c := range stale {
+		unlock := m.lock(c.fqdn)
+		err := m.deleteRecord(c.recordId)
+		unlock()
+		if err != nil {
+			m.log.Error("清理过期的ACME验证记录失败",
+				zap.String("fqdn", c.fqdn),
+				zap.String("record_id", c.recordId),
+				zap.Error(err),
+			)
+			continue
+		}
+		m.log.Info("已清理过期的ACME验证记录",
+			zap.String("fqdn", c.fqdn),
+			zap.String("record_id", c.recordId),
+		)
+	}
+}
+
+// sweepAll 扫描所有域名，清理带有本服务备注的过期验证记录
+func (m *Manager) sweepAll() {
+	domains, err := m.records.ListDomains()
+	if err != nil {
+		m.log.Error("扫描过期的ACME验证记录失败", zap.Error(err))
+		return
+	}
+
+	deadline := time.Now().Add(-m.opts.StaleAfter)
+	for _, d := range domains {
+		records, err := m.records.QueryDomainRecords(&service.RecordQuery{
+			DomainName: d.DomainName,
+			RRKeyWord:  ChallengeLabel,
+			Type:       validation.TypeTXT,
+		})
+		if err != nil {
+			m.log.Error("扫描过期的ACME验证记录失败",
+				zap.String("domain", d.DomainName),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		for _, r := range records {
+			if r.RR != ChallengeLabel && !strings.HasPrefix(r.RR, ChallengeLabel+".") {
+				continue
+			}
+			createdAt, ok := parseRemark(r.Remark)
+			if !ok || !createdAt.Before(deadline) || m.protects(d.DomainName, r.RR) {
+				continue
+			}
+			if err := m.deleteRecord(r.RecordId); err != nil {
+				m.log.Error("清理过期的ACME验证记录失败",
+					zap.String("domain", d.DomainName),
+					zap.String("record_id", r.RecordId),
+					zap.Error(err),
+				)
+				continue
+			}
+			m.log.Info("已清理过期的ACME验证记录",
+				zap.String("domain", d.DomainName),
+				zap.String("rr", r.RR),
+				zap.String("record_id", r.RecordId),
+			)
+		}
+	}
+}
+
+// deleteRecord 删除验证记录并停止跟踪，记录已不存在时视为成功
+func (m *Manager) deleteRecord(recordId string) error {
+	if err := m.records.DeleteDomainRecord(recordId); err != nil && !apperror.IsNotFound(err) {
+		return err
+	}
+
+	m.mu.Lock()
+	delete(m.challenges, recordId)
+	m.mu.Unlock()
+	return nil
+}
+
+// protects 判断主机记录是否受变更审批保护
+func (m *Manager) protects(domain, rr string) bool {
+	return m.opts.Protects != nil && m.opts.Protects(domain, rr)
+}
+
+// checkProtected 受变更审批保护的名称拒绝写入验证记录
+func (m *Manager) checkProtected(fqdn, domain, rr string) error {
+	if m.protects(domain, rr) {
+		return apperror.Forbidden(fqdn + " 受变更审批保护，请通过审批流程修改")
+	}
+	return nil
+}
+
+// challengeRecords 查询名称下的所有 TXT 记录
+func (m *Manager) challengeRecords(domain, rr string) ([]service.DomainRecord, error) {
+	return m.records.QueryDomainRecords(&service.RecordQuery{
+		DomainName: domain,
+		RR:         rr,
+		Type:       validation.TypeTXT,
+	})
+}
+
+// createdAt 返回本服务创建的验证记录的创建时间，不是本服务创建的记录返回 false
+func (m *Manager) createdAt(r *service.DomainRecord) (time.Time, bool) {
+	m.mu.Lock()
+	c, ok := m.challenges[r.RecordId]
+	m.mu.Unlock()
+	if ok {
+		return c.createdAt, true
+	}
+	return parseRemark(r.Remark)
+}
+
+// lock 锁定单个验证记录名称，返回解锁函数
+func (m *Manager) lock(fqdn string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[fqdn]
+	if !ok {
+		l = &nameLock{}
+		m.locks[fqdn] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		m.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.locks, fqdn)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// parseRemark 从备注中解析验证记录的创建时间
+func parseRemark(remark string) (time.Time, bool) {
+	ts, ok := strings.CutPrefix(remark, remarkPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0), true
+}
@@ -0,0 +1,119 @@
+package acme
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"dns-update/pkg/idn"
+)
+
+// ChallengeLabel DNS-01 验证记录的主机记录前缀
+const ChallengeLabel = "_acme-challenge"
+
+// challengeValueLength DNS-01 验证值（SHA-256 摘要的 base64url 编码）的长度
+const challengeValueLength = 43
+
+// Token 访问验证接口的凭证，只能为允许的域名创建验证记录
+type Token struct {
+	Name     string   // 凭证名称，用于日志
+	Username string   // 用户名（acme-dns 的 X-Api-User）
+	Password string   // 密码（acme-dns 的 X-Api-Key）
+	Domains  []string // 允许申请证书的域名，*.example.com 表示其下任意子域名及其通配符证书
+	// acme-dns 账户的 subdomain 到证书域名的映射，lego 等客户端使用注册时得到的 subdomain 调用 update 接口
+	Subdomains map[string]string
+}
+
+// Authenticate 在凭证列表中查找匹配的凭证，未找到时返回 nil
+func Authenticate(tokens []Token, username, password string) *Token {
+	var found *Token
+	for i := range tokens {
+		// 逐个比较所有凭证，避免通过响应时间猜测用户名
+		userOK := subtle.ConstantTimeCompare([]byte(tokens[i].Username), []byte(username)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(tokens[i].Password), []byte(password)) == 1
+		if userOK && passOK && found == nil {
+			found = &tokens[i]
+		}
+	}
+	return found
+}
+
+// Allows 判断凭证是否允许操作指定的验证记录
+func (t *Token) Allows(fqdn string) bool {
+	name, ok := challengeName(fqdn)
+	if !ok {
+		return false
+	}
+
+	for _, domain := range t.Domains {
+		domain, err := normalizeName(domain)
+		if err != nil {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(domain, "*."); ok {
+			// 通配符证书的验证记录与根域名相同，见 ChallengeFQDN
+			if name == suffix || strings.HasSuffix(name, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if name == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveSubdomain 返回 acme-dns 请求中 subdomain 对应的证书域名。
+// 已登记的 subdomain 按映射转换；未登记且包含点的按证书域名处理，兼容直接填写域名的客户端（如 acme.sh）
+func (t *Token) ResolveSubdomain(subdomain string) (string, bool) {
+	subdomain = strings.TrimSpace(subdomain)
+	for registered, domain := range t.Subdomains {
+		if strings.EqualFold(registered, subdomain) {
+			return domain, true
+		}
+	}
+	if strings.Contains(strings.Trim(subdomain, "."), ".") {
+		return subdomain, true
+	}
+	return "", false
+}
+
+// ChallengeFQDN 返回证书域名对应的验证记录名称，通配符证书与根域名使用同一名称
+func ChallengeFQDN(domain string) string {
+	domain = strings.TrimPrefix(strings.TrimSuffix(domain, "."), "*.")
+	return ChallengeLabel + "." + domain
+}
+
+// ChallengeValue 根据 keyAuthorization 计算验证记录的值
+func ChallengeValue(keyAuth string) string {
+	sum := sha256.Sum256([]byte(keyAuth))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ValidateValue 校验验证记录的值，只允许 DNS-01 规定的摘要格式，避免凭证被用于写入任意 TXT 记录
+func ValidateValue(value string) error {
+	if len(value) != challengeValueLength {
+		return errors.New("验证值长度必须为43个字符")
+	}
+	if _, err := base64.RawURLEncoding.DecodeString(value); err != nil {
+		return errors.New("验证值必须是base64url编码")
+	}
+	return nil
+}
+
+// challengeName 从验证记录名称中取出证书域名
+func challengeName(fqdn string) (string, bool) {
+	fqdn, err := normalizeName(fqdn)
+	if err != nil {
+		return "", false
+	}
+	return strings.CutPrefix(fqdn, ChallengeLabel+".")
+}
+
+// normalizeName 去掉末尾的点并转换为小写 ASCII 形式
+func normalizeName(name string) (string, error) {
+	return idn.ToASCII(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
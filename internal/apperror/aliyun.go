@@ -0,0 +1,94 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"dns-update/pkg/redact"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// aliyunCodeStatus 阿里云错误码与HTTP状态码的精确映射
+var aliyunCodeStatus = map[string]int{
+	"DomainRecordDuplicate":       http.StatusConflict,
+	"DomainRecordConflict":        http.StatusConflict,
+	"DomainRecordLocked":          http.StatusConflict,
+	"DomainRecordNotFound":        http.StatusNotFound,
+	"DomainRecordNotBelongToUser": http.StatusNotFound,
+	"InvalidDomainName.NoExist":   http.StatusNotFound,
+	"InvalidRR.NoExist":           http.StatusNotFound,
+	"DomainNotFound":              http.StatusNotFound,
+	"Forbidden.RAM":               http.StatusForbidden,
+	"Forbidden":                   http.StatusForbidden,
+	"Throttling":                  http.StatusTooManyRequests,
+	"Throttling.User":             http.StatusTooManyRequests,
+	"Throttling.Api":              http.StatusTooManyRequests,
+}
+
+// aliyunCodeToStatus 根据阿里云错误码推断HTTP状态码
+func aliyunCodeToStatus(code string, upstreamStatus int) int {
+	if status, ok := aliyunCodeStatus[code]; ok {
+		return status
+	}
+
+	switch {
+	case strings.HasPrefix(code, "Throttling"):
+		return http.StatusTooManyRequests
+	case strings.HasPrefix(code, "Forbidden"):
+		return http.StatusForbidden
+	case strings.HasSuffix(code, ".NoExist"), strings.HasSuffix(code, "NotFound"):
+		return http.StatusNotFound
+	case strings.HasSuffix(code, "Duplicate"), strings.HasSuffix(code, "Conflict"):
+		return http.StatusConflict
+	case strings.HasPrefix(code, "Invalid"), strings.HasPrefix(code, "Missing"):
+		return http.StatusBadRequest
+	}
+
+	// 其余情况沿用上游的4xx状态码，上游5xx统一视为网关错误
+	if upstreamStatus >= 400 && upstreamStatus < 500 {
+		return upstreamStatus
+	}
+	return http.StatusBadGateway
+}
+
+// fromSDKError 解析阿里云SDK返回的错误
+func fromSDKError(err error) *Error {
+	var sdkErr *tea.SDKError
+	if !errors.As(err, &sdkErr) {
+		return nil
+	}
+
+	code := tea.StringValue(sdkErr.Code)
+	message := tea.StringValue(sdkErr.Message)
+	var requestId string
+
+	// Data 中保存了上游原始的响应体，优先使用其中的 Message 和 RequestId
+	if data := tea.StringValue(sdkErr.Data); data != "" {
+		var body map[string]interface{}
+		if json.Unmarshal([]byte(data), &body) == nil {
+			if v, ok := body["Message"].(string); ok && v != "" {
+				message = v
+			}
+			if v, ok := body["RequestId"].(string); ok {
+				requestId = v
+			}
+		}
+	}
+
+	if code == "" {
+		code = CodeUpstream
+	}
+	// 上游错误信息可能包含请求参数，返回给调用方前替换其中的敏感信息
+	message = redact.String(message)
+
+	return &Error{
+		Status:            aliyunCodeToStatus(code, tea.IntValue(sdkErr.StatusCode)),
+		Code:              code,
+		Message:           message,
+		UpstreamRequestId: requestId,
+		Err:               err,
+	}
+}
@@ -0,0 +1,64 @@
+package apperror
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+func TestFromSDKErrorRedactsMessage(t *testing.T) {
+	const (
+		accessKeyId = "LTAI5tQz8xY2wV3uR7pK9mNa"
+		signature   = "Zx9v8u7t6s5r4q3p2o1n0m"
+	)
+	message := "Specified signature is not matched with our calculation. server string to sign is:GET&%2F&AccessKeyId%3D" +
+		accessKeyId + "%26Signature%3D" + signature + "%26Version%3D2015-01-09"
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "Message",
+			err: tea.NewSDKError(map[string]interface{}{
+				"code":       "SignatureDoesNotMatch",
+				"message":    message,
+				"statusCode": http.StatusBadRequest,
+			}),
+		},
+		{
+			name: "Data 中的 Message",
+			err: tea.NewSDKError(map[string]interface{}{
+				"code":       "SignatureDoesNotMatch",
+				"message":    "code: 400, see data",
+				"statusCode": http.StatusBadRequest,
+				"data": map[string]interface{}{
+					"Message":   message,
+					"RequestId": "6F3B2C1A-0000-4000-8000-000000000000",
+				},
+			}),
+		},
+		{
+			name: "包装后的错误",
+			err: fmt.Errorf("更新解析记录失败: %w", tea.NewSDKError(map[string]interface{}{
+				"code":    "InvalidAccessKeyId.NotFound",
+				"message": "Specified access key is not found: " + accessKeyId,
+			})),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := From(tt.err).ToResponse("req-1")
+			if strings.Contains(resp.Message, accessKeyId) || strings.Contains(resp.Message, signature) {
+				t.Fatalf("响应中的错误信息仍包含敏感信息: %q", resp.Message)
+			}
+			if resp.Code == "" || resp.Code == CodeInternal {
+				t.Errorf("错误码 = %q，期望使用上游错误码", resp.Code)
+			}
+		})
+	}
+}
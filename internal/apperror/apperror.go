@@ -0,0 +1,158 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// 通用错误码
+const (
+	CodeInvalidParameter = "InvalidParameter"
+	CodeNotFound         = "NotFound"
+	CodeUnauthorized     = "Unauthorized"
+	CodeConflict         = "Conflict"
+	CodeForbidden        = "Forbidden"
+	CodePolicyViolation  = "PolicyViolation"
+	CodeThrottling       = "Throttling"
+	CodeInternal         = "InternalError"
+	CodeUpstream         = "UpstreamError"
+)
+
+// Error 统一的应用错误
+type Error struct {
+	Status            int          // HTTP状态码
+	Code              string       // 错误码
+	Message           string       // 错误信息
+	UpstreamRequestId string       // 阿里云返回的RequestId
+	Fields            []FieldError // 字段级错误信息
+	Err               error        // 原始错误
+}
+
+// FieldError 字段级错误信息
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Response 统一的错误响应结构
+type Response struct {
+	Code              string       `json:"code"`
+	Message           string       `json:"message"`
+	RequestId         string       `json:"request_id"`
+	UpstreamRequestId string       `json:"upstream_request_id,omitempty"`
+	Fields            []FieldError `json:"fields,omitempty"`
+}
+
+// New 创建应用错误
+func New(status int, code, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// BadRequest 创建参数错误
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeInvalidParameter, message)
+}
+
+// Invalid 创建包含字段级错误信息的参数错误
+func Invalid(fields ...FieldError) *Error {
+	err := BadRequest("参数校验失败")
+	err.Fields = fields
+	return err
+}
+
+// NotFound 创建资源不存在错误
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Unauthorized 创建未认证错误
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden 创建无权限错误
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+// Conflict 创建资源冲突错误
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// Internal 包装内部错误
+func Internal(err error) *Error {
+	return &Error{
+		Status:  http.StatusInternalServerError,
+		Code:    CodeInternal,
+		Message: "服务内部错误",
+		Err:     err,
+	}
+}
+
+// From 将任意错误转换为应用错误
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	if upstream := fromSDKError(err); upstream != nil {
+		return upstream
+	}
+
+	return Internal(err)
+}
+
+// HasCode 判断错误码是否为指定值之一
+func HasCode(err error, codes ...string) bool {
+	appErr := From(err)
+	if appErr == nil {
+		return false
+	}
+	for _, code := range codes {
+		if appErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound 判断是否为资源不存在错误
+func IsNotFound(err error) bool {
+	appErr := From(err)
+	return appErr != nil && appErr.Status == http.StatusNotFound
+}
+
+// ToResponse 生成错误响应体
+func (e *Error) ToResponse(requestId string) Response {
+	return Response{
+		Code:              e.Code,
+		Message:           e.Message,
+		RequestId:         requestId,
+		UpstreamRequestId: e.UpstreamRequestId,
+		Fields:            e.Fields,
+	}
+}
@@ -0,0 +1,690 @@
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/audit"
+	"dns-update/internal/batch"
+	"dns-update/internal/notify"
+	"dns-update/internal/service"
+	"dns-update/internal/store"
+	"dns-update/pkg/idn"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// 变更请求状态
+const (
+	StatusPending  = "pending"
+	StatusApplying = "applying"
+	StatusApplied  = "applied"
+	StatusFailed   = "failed"
+	StatusRejected = "rejected"
+	StatusExpired  = "expired"
+)
+
+// StoreFile 变更请求的状态文件名
+const StoreFile = "approvals.json"
+
+// Executor 执行审批通过的解析记录操作
+type Executor interface {
+	Execute(ctx context.Context, domain string, req *batch.Request) *batch.Result
+}
+
+// RecordService 计算变更差异时依赖的解析记录服务
+type RecordService interface {
+	GetDomainRecordById(recordId string) (*service.DomainRecord, error)
+}
+
+// Rule 受保护的域名和主机记录
+type Rule struct {
+	Domain string   // 域名，* 表示所有域名
+	RR     []string // 主机记录通配符，如 @、www、*.prod，为空表示所有主机记录
+}
+
+// Options 变更审批的选项
+type Options struct {
+	Rules     []Rule
+	Approvers []string      // 允许审批的身份，为空表示除提交人以外的任意已认证身份
+	Expiry    time.Duration // 待审批的变更请求超过该时长自动失效
+	Retention time.Duration // 已结束变更请求的保留时长
+}
+
+// DefaultOptions 默认的变更审批选项
+var DefaultOptions = Options{
+	Expiry:    72 * time.Hour,
+	Retention: 30 * 24 * time.Hour,
+}
+
+// tick 检查过期变更请求的间隔
+const tick = time.Minute
+
+// FieldChange 单个字段的变化
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// DiffEntry 单个操作相对于当前解析记录的差异
+type DiffEntry struct {
+	Index    int                        `json:"index"`
+	Action   batch.Action               `json:"action"`
+	RecordId string                     `json:"record_id,omitempty"`
+	Before   *service.DomainRecord      `json:"before,omitempty"` // 提交时的解析记录，create 操作为空
+	After    *service.DomainRecordInput `json:"after,omitempty"`  // create/update 操作提交的记录
+	Fields   []FieldChange              `json:"fields,omitempty"`
+}
+
+// Change 待审批的变更请求
+type Change struct {
+	Id         string            `json:"id"`
+	Domain     string            `json:"domain"`
+	Operations []batch.Operation `json:"operations"`
+	Diff       []DiffEntry       `json:"diff"`
+	Status     string            `json:"status"`
+	Requester  string            `json:"requester"`
+	Approver   string            `json:"approver,omitempty"` // 审批或拒绝的身份
+	Comment    string            `json:"comment,omitempty"`
+	Result     *batch.Result     `json:"result,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	DecidedAt  *time.Time        `json:"decided_at,omitempty"`
+}
+
+// Actor 操作变更请求的调用方
+type Actor struct {
+	Identity  string
+	RequestId string
+}
+
+// Manager 管理受保护域名的变更请求，审批通过后以原子方式执行
+type Manager struct {
+	executor Executor
+	records  RecordService
+	audit    *audit.Log
+	notifier notify.Notifier
+	file     *store.File
+	opts     Options
+	log      *zap.Logger
+
+	mu      sync.Mutex
+	changes map[string]*Change
+}
+
+// NewManager 创建变更审批管理器并加载已保存的变更请求。
+// 服务停止时正在执行的变更结果未知，标记为失败
+func NewManager(executor Executor, records RecordService, auditLog *audit.Log, notifier notify.Notifier, file *store.File, opts *Options) (*Manager, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	o := *opts
+	if o.Expiry <= 0 {
+		o.Expiry = DefaultOptions.Expiry
+	}
+	if o.Retention <= 0 {
+		o.Retention = DefaultOptions.Retention
+	}
+
+	m := &Manager{
+		executor: executor,
+		records:  records,
+		audit:    auditLog,
+		notifier: notifier,
+		file:     file,
+		opts:     o,
+		log:      logger.GetLogger(),
+		changes:  make(map[string]*Change),
+	}
+
+	var changes []*Change
+	if err := file.Load(&changes); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, change := range changes {
+		if change.Status == StatusApplying {
+			change.Status = StatusFailed
+			change.Error = "服务停止时变更正在执行，执行结果未知，请核对解析记录"
+			change.UpdatedAt = now
+		}
+		m.changes[change.Id] = change
+	}
+
+	m.mu.Lock()
+	err := m.saveLocked()
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.log.Info("已加载变更请求",
+		zap.String("file", file.Path()),
+		zap.Int("count", len(changes)),
+		zap.Int("rules", len(o.Rules)),
+	)
+	return m, nil
+}
+
+// Protects 判断域名和主机记录是否受审批保护，rr 为空时判断域名下是否有受保护的记录
+func (m *Manager) Protects(domain, rr string) bool {
+	for _, rule := range m.opts.Rules {
+		if rule.Domain != "*" && !idn.Equal(rule.Domain, domain) {
+			continue
+		}
+		if rr == "" || len(rule.RR) == 0 {
+			return true
+		}
+		name := strings.ToLower(rr)
+		if ascii, err := idn.ToASCII(rr); err == nil {
+			name = strings.ToLower(ascii)
+		}
+		for _, pattern := range rule.RR {
+			if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Submit 涉及受保护记录的操作转为待审批的变更请求；不涉及时返回 nil，由调用方直接执行。
+// known 为调用方已查询的修改前记录，计算差异时不再重复查询
+func (m *Manager) Submit(domain string, req *batch.Request, actor *Actor, known ...*service.DomainRecord) (*Change, error) {
+	if !m.Protects(domain, "") {
+		return nil, nil
+	}
+
+	diff, err := m.diff(domain, req.Operations, known)
+	if err != nil {
+		return nil, err
+	}
+	protected := false
+	for _, entry := range diff {
+		if m.protectsEntry(domain, &entry) {
+			protected = true
+			break
+		}
+	}
+	if !protected {
+		return nil, nil
+	}
+	if actor.Identity == "" {
+		return nil, apperror.Unauthorized("修改受保护的解析记录需要携带访问令牌，以便由其他人审批")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	change := &Change{
+		Id:         newChangeId(),
+		Domain:     domain,
+		Operations: copyOperations(req.Operations),
+		Diff:       diff,
+		Status:     StatusPending,
+		Requester:  actor.Identity,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		ExpiresAt:  now.Add(m.opts.Expiry),
+	}
+
+	m.mu.Lock()
+	m.purgeLocked()
+	m.changes[change.Id] = change
+	if err := m.saveLocked(); err != nil {
+		delete(m.changes, change.Id)
+		m.mu.Unlock()
+		return nil, apperror.Internal(err)
+	}
+	c := copyChange(change)
+	m.mu.Unlock()
+
+	m.log.Info("已创建变更请求",
+		zap.String("change_id", c.Id),
+		zap.String("domain", domain),
+		zap.String("requester", actor.Identity),
+		zap.Int("operations", len(c.Operations)),
+	)
+	m.record(c, "approval.requested", actor, nil)
+	m.notifier.Notify(&notify.Event{
+		Kind:    "approval.requested",
+		Title:   "解析变更待审批",
+		Message: fmt.Sprintf("%s 提交了域名 %s 的变更，共%d个操作，等待其他人审批", actor.Identity, domain, len(c.Operations)),
+		Fields: map[string]string{
+			"change_id":  c.Id,
+			"domain":     domain,
+			"requester":  actor.Identity,
+			"expires_at": c.ExpiresAt.Format(time.RFC3339),
+		},
+	})
+	return c, nil
+}
+
+// Get 查询变更请求
+func (m *Manager) Get(id string) (*Change, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	change, ok := m.changes[id]
+	if !ok {
+		return nil, apperror.NotFound("变更请求不存在")
+	}
+	return copyChange(change), nil
+}
+
+// List 按提交时间倒序返回变更请求，status 和 domain 为空时不过滤
+func (m *Manager) List(status, domain string) []*Change {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changes := make([]*Change, 0, len(m.changes))
+	for _, change := range m.changes {
+		if status != "" && change.Status != status {
+			continue
+		}
+		if domain != "" && !idn.Equal(change.Domain, domain) {
+			continue
+		}
+		changes = append(changes, copyChange(change))
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].CreatedAt.After(changes[j].CreatedAt)
+	})
+	return changes
+}
+
+// Approve 审批通过并以原子方式执行变更；提交后记录已被修改时不执行
+func (m *Manager) Approve(ctx context.Context, id, comment string, actor *Actor) (*Change, error) {
+	m.mu.Lock()
+	change, err := m.decidableLocked(id, actor)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	now := time.Now()
+	change.Status = StatusApplying
+	change.Approver = actor.Identity
+	change.Comment = comment
+	change.DecidedAt = &now
+	change.UpdatedAt = now
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存变更请求失败", zap.Error(err))
+	}
+	c := copyChange(change)
+	m.mu.Unlock()
+
+	m.record(c, "approval.approved", actor, map[string]string{"comment": comment})
+	domain, ops := c.Domain, copyOperations(c.Operations)
+
+	if drift := m.drift(c.Diff); drift != "" {
+		m.finish(change, StatusFailed, nil, "提交后解析记录已被修改，请重新提交变更: "+drift, actor)
+		return m.Get(id)
+	}
+
+	m.log.Info("开始执行审批通过的变更",
+		zap.String("change_id", id),
+		zap.String("domain", domain),
+		zap.String("approver", actor.Identity),
+	)
+	result := m.executor.Execute(ctx, domain, &batch.Request{Operations: ops, Atomic: true})
+	if result.Failed == 0 && !result.RolledBack {
+		m.finish(change, StatusApplied, result, "", actor)
+	} else {
+		m.finish(change, StatusFailed, result, failureReason(result), actor)
+	}
+	return m.Get(id)
+}
+
+// Reject 拒绝变更请求
+func (m *Manager) Reject(id, comment string, actor *Actor) (*Change, error) {
+	m.mu.Lock()
+	change, err := m.decidableLocked(id, actor)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	now := time.Now()
+	change.Status = StatusRejected
+	change.Approver = actor.Identity
+	change.Comment = comment
+	change.DecidedAt = &now
+	change.UpdatedAt = now
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存变更请求失败", zap.Error(err))
+	}
+	c := copyChange(change)
+	m.mu.Unlock()
+
+	m.log.Info("已拒绝变更请求", zap.String("change_id", id), zap.String("approver", actor.Identity))
+	m.record(c, "approval.rejected", actor, map[string]string{"comment": comment})
+	m.notifier.Notify(&notify.Event{
+		Kind:    "approval.rejected",
+		Title:   "解析变更已被拒绝",
+		Message: fmt.Sprintf("%s 拒绝了 %s 提交的域名 %s 的变更", actor.Identity, c.Requester, c.Domain),
+		Fields:  map[string]string{"change_id": id, "domain": c.Domain, "comment": comment},
+	})
+	return c, nil
+}
+
+// Run 定期使超时未审批的变更请求失效，直到 ctx 取消
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.expire()
+		}
+	}
+}
+
+// expire 使超时未审批的变更请求失效
+func (m *Manager) expire() {
+	now := time.Now()
+
+	m.mu.Lock()
+	var expired []*Change
+	for _, change := range m.changes {
+		if change.Status == StatusPending && now.After(change.ExpiresAt) {
+			change.Status = StatusExpired
+			change.UpdatedAt = now
+			change.DecidedAt = &now
+			expired = append(expired, copyChange(change))
+		}
+	}
+	if len(expired) > 0 {
+		m.purgeLocked()
+		if err := m.saveLocked(); err != nil {
+			m.log.Error("保存变更请求失败", zap.Error(err))
+		}
+	}
+	m.mu.Unlock()
+
+	for _, change := range expired {
+		m.log.Info("变更请求超时未审批，已失效", zap.String("change_id", change.Id))
+		m.record(change, "approval.expired", &Actor{}, nil)
+	}
+}
+
+// decidableLocked 检查调用方能否审批变更请求，调用方需持有锁
+func (m *Manager) decidableLocked(id string, actor *Actor) (*Change, error) {
+	if actor.Identity == "" {
+		return nil, apperror.Unauthorized("审批变更需要携带访问令牌")
+	}
+	change, ok := m.changes[id]
+	if !ok {
+		return nil, apperror.NotFound("变更请求不存在")
+	}
+	if change.Status != StatusPending {
+		return nil, apperror.Conflict(fmt.Sprintf("变更请求当前状态为%s，无法审批", change.Status))
+	}
+	if time.Now().After(change.ExpiresAt) {
+		return nil, apperror.Conflict("变更请求已超时失效")
+	}
+	if actor.Identity == change.Requester {
+		return nil, apperror.Forbidden("不能审批自己提交的变更")
+	}
+	if len(m.opts.Approvers) > 0 && !slices.Contains(m.opts.Approvers, actor.Identity) {
+		return nil, apperror.Forbidden(fmt.Sprintf("%s 没有审批权限", actor.Identity))
+	}
+	return change, nil
+}
+
+// finish 结束变更请求，写入审计日志并发送通知
+func (m *Manager) finish(change *Change, status string, result *batch.Result, reason string, actor *Actor) {
+	m.mu.Lock()
+	change.Status = status
+	change.Result = result
+	change.Error = reason
+	change.UpdatedAt = time.Now()
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存变更请求失败", zap.Error(err))
+	}
+	c := copyChange(change)
+	m.mu.Unlock()
+
+	event := &notify.Event{
+		Kind: "approval." + status,
+		Fields: map[string]string{
+			"change_id": c.Id,
+			"domain":    c.Domain,
+			"requester": c.Requester,
+			"approver":  c.Approver,
+		},
+	}
+	if status == StatusApplied {
+		m.log.Info("审批通过的变更已执行", zap.String("change_id", c.Id), zap.String("domain", c.Domain))
+		m.record(c, "approval.applied", actor, nil)
+		event.Title = "解析变更已执行"
+		event.Message = fmt.Sprintf("%s 审批通过了 %s 提交的域名 %s 的变更，共%d个操作", c.Approver, c.Requester, c.Domain, len(c.Operations))
+	} else {
+		m.log.Error("审批通过的变更执行失败", zap.String("change_id", c.Id), zap.String("error", reason))
+		m.record(c, "approval.failed", actor, map[string]string{"error": reason})
+		event.Title = "解析变更执行失败"
+		event.Message = fmt.Sprintf("域名 %s 的变更执行失败: %s", c.Domain, reason)
+	}
+	m.notifier.Notify(event)
+}
+
+// record 写入审计日志
+func (m *Manager) record(change *Change, action string, actor *Actor, detail map[string]string) {
+	if detail == nil {
+		detail = make(map[string]string)
+	}
+	detail["requester"] = change.Requester
+	if change.Approver != "" {
+		detail["approver"] = change.Approver
+	}
+	detail["operations"] = strconv.Itoa(len(change.Operations))
+	for k, v := range detail {
+		if v == "" {
+			delete(detail, k)
+		}
+	}
+
+	m.audit.Record(&audit.Entry{
+		Actor:     actor.Identity,
+		Action:    action,
+		Domain:    change.Domain,
+		Target:    change.Id,
+		RequestId: actor.RequestId,
+		Detail:    detail,
+	})
+}
+
+// diff 查询操作涉及的解析记录并计算差异，known 中已有的记录不再查询
+func (m *Manager) diff(domain string, ops []batch.Operation, known []*service.DomainRecord) ([]DiffEntry, error) {
+	records := make(map[string]*service.DomainRecord, len(known))
+	for _, r := range known {
+		records[r.RecordId] = r
+	}
+
+	diff := make([]DiffEntry, 0, len(ops))
+	for i, op := range ops {
+		entry := DiffEntry{Index: i, Action: op.Action, RecordId: op.RecordId}
+		if op.Record != nil {
+			after := *op.Record
+			entry.After = &after
+		}
+
+		if op.Action != batch.ActionCreate {
+			before, ok := records[op.RecordId]
+			if !ok {
+				var err error
+				if before, err = m.records.GetDomainRecordById(op.RecordId); err != nil {
+					return nil, err
+				}
+			}
+			if before.DomainName != "" && !idn.Equal(before.DomainName, domain) {
+				return nil, apperror.NotFound(fmt.Sprintf("解析记录%s不属于指定域名", op.RecordId))
+			}
+			entry.Before = before
+		}
+
+		entry.Fields = fieldChanges(&entry)
+		diff = append(diff, entry)
+	}
+	return diff, nil
+}
+
+// protectsEntry 判断操作前后的主机记录是否受保护
+func (m *Manager) protectsEntry(domain string, entry *DiffEntry) bool {
+	if entry.Before != nil && m.Protects(domain, entry.Before.RR) {
+		return true
+	}
+	return entry.After != nil && m.Protects(domain, entry.After.RR)
+}
+
+// drift 检查提交后解析记录是否被修改，返回被修改的记录说明
+func (m *Manager) drift(diff []DiffEntry) string {
+	var changed []string
+	for _, entry := range diff {
+		if entry.Before == nil {
+			continue
+		}
+		current, err := m.records.GetDomainRecordById(entry.RecordId)
+		if err != nil {
+			changed = append(changed, fmt.Sprintf("%s: %s", entry.RecordId, apperror.From(err).Message))
+			continue
+		}
+		if !sameRecord(entry.Before, current) {
+			changed = append(changed, entry.RecordId)
+		}
+	}
+	return strings.Join(changed, ", ")
+}
+
+// fieldChanges 计算操作会修改的字段
+func fieldChanges(entry *DiffEntry) []FieldChange {
+	var before service.DomainRecord
+	if entry.Before != nil {
+		before = *entry.Before
+	}
+
+	var fields []FieldChange
+	add := func(field, from, to string) {
+		if from != to {
+			fields = append(fields, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	switch entry.Action {
+	case batch.ActionCreate, batch.ActionUpdate:
+		after := entry.After
+		add("rr", before.RR, after.RR)
+		add("type", before.Type, after.Type)
+		add("value", before.Value, after.Value)
+		if after.TTL != 0 {
+			add("ttl", formatInt(before.TTL), formatInt(after.TTL))
+		}
+		if after.Priority != 0 {
+			add("priority", formatInt(before.Priority), formatInt(after.Priority))
+		}
+		if after.Line != "" {
+			add("line", before.Line, after.Line)
+		}
+	case batch.ActionEnable:
+		add("status", before.Status, service.RecordStatusEnable)
+	case batch.ActionDisable:
+		add("status", before.Status, service.RecordStatusDisable)
+	}
+	return fields
+}
+
+// sameRecord 比较解析记录的可修改字段
+func sameRecord(a, b *service.DomainRecord) bool {
+	return a.RR == b.RR && a.Type == b.Type && a.Value == b.Value && a.TTL == b.TTL &&
+		a.Priority == b.Priority && a.Line == b.Line && a.Status == b.Status
+}
+
+// failureReason 汇总执行失败的原因
+func failureReason(result *batch.Result) string {
+	var reasons []string
+	for _, r := range result.Results {
+		switch r.Status {
+		case batch.StatusFailed:
+			reasons = append(reasons, fmt.Sprintf("操作%d: %s", r.Index, r.Error))
+		case batch.StatusRollbackFailed:
+			reasons = append(reasons, fmt.Sprintf("操作%d回滚失败: %s", r.Index, r.Error))
+		}
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "操作已回滚")
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// saveLocked 保存所有变更请求，调用方需持有锁
+func (m *Manager) saveLocked() error {
+	changes := make([]*Change, 0, len(m.changes))
+	for _, change := range m.changes {
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].CreatedAt.Before(changes[j].CreatedAt)
+	})
+	return m.file.Save(changes)
+}
+
+// purgeLocked 清理超过保留时长的已结束变更请求，调用方需持有锁
+func (m *Manager) purgeLocked() {
+	deadline := time.Now().Add(-m.opts.Retention)
+	for id, change := range m.changes {
+		if change.DecidedAt != nil && change.DecidedAt.Before(deadline) {
+			delete(m.changes, id)
+		}
+	}
+}
+
+// copyChange 复制变更请求，避免调用方与执行中的变更产生竞争
+func copyChange(change *Change) *Change {
+	c := *change
+	c.Operations = append([]batch.Operation(nil), change.Operations...)
+	c.Diff = append([]DiffEntry(nil), change.Diff...)
+	return &c
+}
+
+// copyOperations 复制操作及其记录参数，执行时补全参数不会修改保存的变更
+func copyOperations(ops []batch.Operation) []batch.Operation {
+	copied := make([]batch.Operation, len(ops))
+	for i, op := range ops {
+		if op.Record != nil {
+			record := *op.Record
+			op.Record = &record
+		}
+		copied[i] = op
+	}
+	return copied
+}
+
+func formatInt(v int64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// newChangeId 生成随机变更请求ID
+func newChangeId() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
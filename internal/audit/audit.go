@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// FileName 审计日志的文件名
+const FileName = "audit.log"
+
+// 查询审计日志时的默认条数和最大条数
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Entry 审计日志条目
+type Entry struct {
+	Time      time.Time         `json:"time"`
+	Actor     string            `json:"actor"`  // 操作人，未认证时为空
+	Action    string            `json:"action"` // 如 approval.approved
+	Domain    string            `json:"domain,omitempty"`
+	Target    string            `json:"target,omitempty"` // 操作对象，如变更请求ID
+	RequestId string            `json:"request_id,omitempty"`
+	Detail    map[string]string `json:"detail,omitempty"`
+}
+
+// Log 追加写入的审计日志，每行一条 JSON
+type Log struct {
+	path string
+	log  *zap.Logger
+
+	mu sync.Mutex
+}
+
+// New 创建审计日志，目录不存在时自动创建
+func New(dir string) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("创建审计日志目录失败: %w", err)
+	}
+	return &Log{
+		path: filepath.Join(dir, FileName),
+		log:  logger.GetLogger(),
+	}, nil
+}
+
+// Path 返回审计日志的路径
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record 写入一条审计日志，写入失败时记录错误日志
+func (l *Log) Record(e *Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	l.log.Info("审计",
+		zap.String("actor", e.Actor),
+		zap.String("action", e.Action),
+		zap.String("domain", e.Domain),
+		zap.String("target", e.Target),
+	)
+
+	if err := l.append(e); err != nil {
+		l.log.Error("写入审计日志失败",
+			zap.String("path", l.path),
+			zap.String("action", e.Action),
+			zap.Error(err),
+		)
+	}
+}
+
+// append 追加一条审计日志
+func (l *Log) append(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Query 审计日志查询条件，为空的字段不过滤
+type Query struct {
+	Actor  string
+	Action string // 前缀匹配，如 approval. 匹配所有审批操作
+	Domain string
+	Limit  int
+}
+
+// List 按时间倒序返回最近的审计日志
+func (l *Log) List(q *Query) ([]Entry, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// 顺序读取并只保留最后 limit 条
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if q.Actor != "" && e.Actor != q.Actor {
+			continue
+		}
+		if q.Action != "" && !strings.HasPrefix(e.Action, q.Action) {
+			continue
+		}
+		if q.Domain != "" && !strings.EqualFold(e.Domain, q.Domain) {
+			continue
+		}
+		entries = append(entries, e)
+		if len(entries) > limit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Entry, len(entries))
+	for i, e := range entries {
+		result[len(entries)-1-i] = e
+	}
+	return result, nil
+}
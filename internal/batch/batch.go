@@ -0,0 +1,466 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+	"dns-update/pkg/idn"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Action 批量操作类型
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionEnable  Action = "enable"
+	ActionDisable Action = "disable"
+)
+
+// 单个操作的执行状态
+const (
+	StatusPending        = "pending"
+	StatusSucceeded      = "succeeded"
+	StatusFailed         = "failed"
+	StatusSkipped        = "skipped"
+	StatusRolledBack     = "rolled_back"
+	StatusRollbackFailed = "rollback_failed"
+)
+
+// 并发度限制
+const (
+	DefaultConcurrency = 5
+	MaxConcurrency     = 20
+	MaxOperations      = 1000
+)
+
+// RecordService 批量操作依赖的解析记录服务
+type RecordService interface {
+	GetDomainRecordById(recordId string) (*service.DomainRecord, error)
+	QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error)
+	AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error)
+	UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error
+	SetDomainRecordStatus(recordId, status string) error
+	SetDomainRecordRemark(recordId, remark string) error
+	UpdateSLBWeight(recordId string, weight int32) error
+	DeleteDomainRecord(recordId string) error
+	CheckWrite(action, domainName, recordId string, input *service.DomainRecordInput) error
+}
+
+// Operation 单个批量操作
+type Operation struct {
+	Action   Action                     `json:"action"`
+	RecordId string                     `json:"record_id,omitempty"` // update/delete/enable/disable 必填
+	Record   *service.DomainRecordInput `json:"record,omitempty"`    // create/update 必填
+}
+
+// Request 批量操作请求
+type Request struct {
+	Operations  []Operation `json:"operations"`
+	Atomic      bool        `json:"atomic"`      // 任一操作失败时回滚已成功的操作
+	Concurrency int         `json:"concurrency"` // 并发度，默认5，最大20
+}
+
+// OperationResult 单个操作的执行结果
+type OperationResult struct {
+	Index    int    `json:"index"`
+	Action   Action `json:"action"`
+	RecordId string `json:"record_id,omitempty"`
+	Status   string `json:"status"`
+	// RestoredRecordId 回滚删除操作时重新创建的记录ID，原记录ID已失效
+	RestoredRecordId string `json:"restored_record_id,omitempty"`
+	Code             string `json:"code,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// Result 批量操作的执行结果
+type Result struct {
+	Total      int               `json:"total"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	RolledBack bool              `json:"rolled_back"`
+	Results    []OperationResult `json:"results"`
+}
+
+// Executor 批量操作执行器
+type Executor struct {
+	records RecordService
+	log     *zap.Logger
+}
+
+// NewExecutor 创建批量操作执行器
+func NewExecutor(records RecordService) *Executor {
+	return &Executor{
+		records: records,
+		log:     logger.GetLogger(),
+	}
+}
+
+// With 返回使用指定解析记录服务的执行器，用于以调用方身份执行操作
+func (e *Executor) With(records RecordService) *Executor {
+	return &Executor{
+		records: records,
+		log:     e.log,
+	}
+}
+
+// Validate 在执行前校验批量请求，返回字段级错误
+func (r *Request) Validate() error {
+	if len(r.Operations) == 0 {
+		return apperror.BadRequest("operations不能为空")
+	}
+	if len(r.Operations) > MaxOperations {
+		return apperror.BadRequest(fmt.Sprintf("单次最多提交%d个操作", MaxOperations))
+	}
+	if r.Concurrency < 0 || r.Concurrency > MaxConcurrency {
+		return apperror.BadRequest(fmt.Sprintf("concurrency必须在0-%d之间", MaxConcurrency))
+	}
+
+	var fields []apperror.FieldError
+	for i, op := range r.Operations {
+		if err := op.validate(); err != nil {
+			for _, f := range fieldErrors(err) {
+				f.Field = fmt.Sprintf("operations[%d].%s", i, f.Field)
+				fields = append(fields, f)
+			}
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.Invalid(fields...)
+	}
+	return nil
+}
+
+// validate 校验单个操作
+func (op *Operation) validate() error {
+	switch op.Action {
+	case ActionCreate:
+		if op.Record == nil {
+			return apperror.Invalid(apperror.FieldError{Field: "record", Message: "create操作需要提供record"})
+		}
+		return prefixFields("record", op.Record.Validate())
+	case ActionUpdate:
+		if op.RecordId == "" {
+			return apperror.Invalid(apperror.FieldError{Field: "record_id", Message: "update操作需要提供record_id"})
+		}
+		if op.Record == nil {
+			return apperror.Invalid(apperror.FieldError{Field: "record", Message: "update操作需要提供record"})
+		}
+		return prefixFields("record", op.Record.Validate())
+	case ActionDelete, ActionEnable, ActionDisable:
+		if op.RecordId == "" {
+			return apperror.Invalid(apperror.FieldError{Field: "record_id", Message: fmt.Sprintf("%s操作需要提供record_id", op.Action)})
+		}
+		return nil
+	}
+	return apperror.Invalid(apperror.FieldError{Field: "action", Message: fmt.Sprintf("不支持的操作类型: %s", op.Action)})
+}
+
+// Check 以执行器的调用方对请求中的操作做策略检查，不执行操作，用于定时变更在创建时拒绝不允许的操作
+func (e *Executor) Check(domain string, req *Request) error {
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		if err := e.records.CheckWrite(string(op.Action), domain, op.RecordId, op.Record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Execute 执行批量操作
+func (e *Executor) Execute(ctx context.Context, domain string, req *Request) *Result {
+	concurrency := req.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	e.log.Info("开始执行批量操作",
+		zap.String("domain", domain),
+		zap.Int("operations", len(req.Operations)),
+		zap.Bool("atomic", req.Atomic),
+		zap.Int("concurrency", concurrency),
+	)
+
+	results := make([]OperationResult, len(req.Operations))
+	undo := make([]undoFunc, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = OperationResult{
+			Index:    i,
+			Action:   op.Action,
+			RecordId: op.RecordId,
+			Status:   StatusPending,
+		}
+	}
+
+	// 原子模式下任一操作失败即停止派发后续操作
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+dispatch:
+	for i := range req.Operations {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			recordId, rollback, err := e.apply(domain, &req.Operations[i])
+			if recordId != "" {
+				results[i].RecordId = recordId
+			}
+			if err != nil {
+				setError(&results[i], err)
+				if req.Atomic {
+					cancel()
+				}
+				return
+			}
+			results[i].Status = StatusSucceeded
+			undo[i] = rollback
+		}(i)
+	}
+	wg.Wait()
+
+	result := &Result{Total: len(results)}
+	for i := range results {
+		if results[i].Status == StatusPending {
+			results[i].Status = StatusSkipped
+		}
+	}
+
+	// 原子模式下有操作失败（或请求被取消）时回滚
+	if req.Atomic && ctx.Err() != nil {
+		result.RolledBack = true
+		e.rollback(domain, results, undo)
+	}
+
+	for _, r := range results {
+		switch r.Status {
+		case StatusSucceeded:
+			result.Succeeded++
+		case StatusFailed:
+			result.Failed++
+		}
+	}
+	result.Results = results
+
+	e.log.Info("批量操作执行完成",
+		zap.String("domain", domain),
+		zap.Int("succeeded", result.Succeeded),
+		zap.Int("failed", result.Failed),
+		zap.Bool("rolled_back", result.RolledBack),
+	)
+	return result
+}
+
+// rollback 按相反顺序撤销已成功的操作
+func (e *Executor) rollback(domain string, results []OperationResult, undo []undoFunc) {
+	for i := len(results) - 1; i >= 0; i-- {
+		if results[i].Status != StatusSucceeded || undo[i] == nil {
+			continue
+		}
+		restoredId, err := undo[i]()
+		if restoredId != "" {
+			results[i].RestoredRecordId = restoredId
+		}
+		if err != nil {
+			e.log.Error("批量操作回滚失败",
+				zap.String("domain", domain),
+				zap.Int("index", i),
+				zap.String("record_id", results[i].RecordId),
+				zap.Error(err),
+			)
+			setError(&results[i], err)
+			results[i].Status = StatusRollbackFailed
+			continue
+		}
+		results[i].Status = StatusRolledBack
+	}
+}
+
+// undoFunc 回滚单个操作的补偿操作，重新创建了记录时返回新记录的ID
+type undoFunc func() (string, error)
+
+// apply 执行单个操作，返回涉及的记录ID以及用于回滚的补偿操作
+func (e *Executor) apply(domain string, op *Operation) (string, undoFunc, error) {
+	if op.Action == ActionCreate {
+		recordId, err := e.records.AddDomainRecord(domain, op.Record)
+		if err != nil {
+			return "", nil, err
+		}
+		return recordId, func() (string, error) {
+			return "", e.records.DeleteDomainRecord(recordId)
+		}, nil
+	}
+
+	// 其余操作先查询当前状态，既用于校验归属也用于回滚
+	previous, err := e.records.GetDomainRecordById(op.RecordId)
+	if err != nil {
+		return "", nil, err
+	}
+	if previous.DomainName != "" && !idn.Equal(previous.DomainName, domain) {
+		return "", nil, apperror.NotFound("解析记录不属于指定域名")
+	}
+	restore := recordInput(previous)
+
+	switch op.Action {
+	case ActionUpdate:
+		if op.Record.Line == "" {
+			// 未指定线路时保持原线路
+			op.Record.Line = previous.Line
+		}
+		if err := e.records.UpdateDomainRecord(op.RecordId, op.Record); err != nil {
+			return "", nil, err
+		}
+		return op.RecordId, func() (string, error) {
+			return "", e.records.UpdateDomainRecord(op.RecordId, restore)
+		}, nil
+
+	case ActionDelete:
+		// 记录详情接口不返回备注和权重，删除前从记录列表中补全，用于回滚时恢复
+		if previous, err = e.describe(domain, previous); err != nil {
+			return "", nil, err
+		}
+		if err := e.records.DeleteDomainRecord(op.RecordId); err != nil {
+			return "", nil, err
+		}
+		// 删除后只能重新创建，新记录的ID会发生变化
+		return op.RecordId, func() (string, error) {
+			return e.recreate(domain, previous, restore)
+		}, nil
+
+	case ActionEnable, ActionDisable:
+		status := service.RecordStatusEnable
+		if op.Action == ActionDisable {
+			status = service.RecordStatusDisable
+		}
+		if err := e.records.SetDomainRecordStatus(op.RecordId, status); err != nil {
+			return "", nil, err
+		}
+		previousStatus := service.RecordStatusEnable
+		if strings.EqualFold(previous.Status, service.RecordStatusDisable) {
+			previousStatus = service.RecordStatusDisable
+		}
+		return op.RecordId, func() (string, error) {
+			return "", e.records.SetDomainRecordStatus(op.RecordId, previousStatus)
+		}, nil
+	}
+
+	return "", nil, apperror.BadRequest(fmt.Sprintf("不支持的操作类型: %s", op.Action))
+}
+
+// describe 从记录列表中查询记录的完整信息，记录已不在列表中时返回原记录
+func (e *Executor) describe(domain string, record *service.DomainRecord) (*service.DomainRecord, error) {
+	domainName := record.DomainName
+	if domainName == "" {
+		domainName = domain
+	}
+	records, err := e.records.QueryDomainRecords(&service.RecordQuery{
+		DomainName: domainName,
+		RR:         record.RR,
+		Type:       record.Type,
+		RecordId:   record.RecordId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return record, nil
+	}
+	return &records[0], nil
+}
+
+// recreate 重新创建被删除的记录并恢复暂停状态、备注和权重，返回新记录的ID
+func (e *Executor) recreate(domain string, previous *service.DomainRecord, input *service.DomainRecordInput) (string, error) {
+	recordId, err := e.records.AddDomainRecord(domain, input)
+	if err != nil {
+		return "", err
+	}
+	e.log.Info("回滚删除操作，已重新创建解析记录",
+		zap.String("domain", domain),
+		zap.String("record_id", previous.RecordId),
+		zap.String("restored_record_id", recordId),
+	)
+
+	if strings.EqualFold(previous.Status, service.RecordStatusDisable) {
+		if err := e.records.SetDomainRecordStatus(recordId, service.RecordStatusDisable); err != nil {
+			return recordId, err
+		}
+	}
+	if previous.Remark != "" {
+		if err := e.records.SetDomainRecordRemark(recordId, previous.Remark); err != nil {
+			return recordId, err
+		}
+	}
+	// 新记录的权重默认为最小值，只在原权重不同时恢复
+	if previous.Weight > service.MinSLBWeight {
+		if err := e.records.UpdateSLBWeight(recordId, previous.Weight); err != nil {
+			return recordId, err
+		}
+	}
+	return recordId, nil
+}
+
+// recordInput 根据现有记录生成写入参数
+func recordInput(r *service.DomainRecord) *service.DomainRecordInput {
+	input := &service.DomainRecordInput{
+		RR:    r.RR,
+		Type:  r.Type,
+		Value: r.Value,
+		TTL:   r.TTL,
+		Line:  r.Line,
+	}
+	if r.Type == "MX" {
+		input.Priority = r.Priority
+	}
+	return input
+}
+
+// setError 记录操作失败的原因
+func setError(result *OperationResult, err error) {
+	appErr := apperror.From(err)
+	result.Status = StatusFailed
+	result.Code = appErr.Code
+	result.Error = appErr.Message
+}
+
+// fieldErrors 提取字段级错误，非字段错误归到 action 字段
+func fieldErrors(err error) []apperror.FieldError {
+	appErr := apperror.From(err)
+	if len(appErr.Fields) > 0 {
+		return appErr.Fields
+	}
+	return []apperror.FieldError{{Field: "action", Message: appErr.Message}}
+}
+
+// prefixFields 为字段级错误添加前缀
+func prefixFields(prefix string, err error) error {
+	if err == nil {
+		return nil
+	}
+	fields := fieldErrors(err)
+	for i := range fields {
+		fields[i].Field = prefix + "." + fields[i].Field
+	}
+	return apperror.Invalid(fields...)
+}
@@ -0,0 +1,189 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+)
+
+// fakeRecords 内存中的解析记录服务，记录详情与阿里云接口一致不返回备注和权重
+type fakeRecords struct {
+	mu      sync.Mutex
+	records map[string]*service.DomainRecord
+	nextId  int
+	failOn  string // 写入该记录值时返回错误
+}
+
+func newFakeRecords(records ...service.DomainRecord) *fakeRecords {
+	f := &fakeRecords{records: make(map[string]*service.DomainRecord)}
+	for _, r := range records {
+		f.records[r.RecordId] = &r
+	}
+	return f
+}
+
+func (f *fakeRecords) get(recordId string) (*service.DomainRecord, error) {
+	r, ok := f.records[recordId]
+	if !ok {
+		return nil, apperror.NotFound("记录不存在")
+	}
+	return r, nil
+}
+
+func (f *fakeRecords) GetDomainRecordById(recordId string) (*service.DomainRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, err := f.get(recordId)
+	if err != nil {
+		return nil, err
+	}
+	info := *r
+	info.Remark, info.Weight = "", 0
+	return &info, nil
+}
+
+func (f *fakeRecords) QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var records []service.DomainRecord
+	for _, r := range f.records {
+		if r.DomainName == query.DomainName && (query.RecordId == "" || r.RecordId == query.RecordId) {
+			records = append(records, *r)
+		}
+	}
+	return records, nil
+}
+
+func (f *fakeRecords) AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if input.Value == f.failOn {
+		return "", apperror.Conflict("记录已存在")
+	}
+	f.nextId++
+	id := fmt.Sprintf("new-%d", f.nextId)
+	f.records[id] = &service.DomainRecord{
+		RecordId:   id,
+		DomainName: domainName,
+		RR:         input.RR,
+		Type:       input.Type,
+		Value:      input.Value,
+		TTL:        input.TTL,
+		Line:       input.Line,
+		Status:     service.RecordStatusEnable,
+		Weight:     service.MinSLBWeight,
+	}
+	return id, nil
+}
+
+func (f *fakeRecords) UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, err := f.get(recordId)
+	if err != nil {
+		return err
+	}
+	r.RR, r.Type, r.Value, r.TTL, r.Line = input.RR, input.Type, input.Value, input.TTL, input.Line
+	return nil
+}
+
+func (f *fakeRecords) SetDomainRecordStatus(recordId, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, err := f.get(recordId)
+	if err != nil {
+		return err
+	}
+	r.Status = status
+	return nil
+}
+
+func (f *fakeRecords) SetDomainRecordRemark(recordId, remark string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, err := f.get(recordId)
+	if err != nil {
+		return err
+	}
+	r.Remark = remark
+	return nil
+}
+
+func (f *fakeRecords) UpdateSLBWeight(recordId string, weight int32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, err := f.get(recordId)
+	if err != nil {
+		return err
+	}
+	r.Weight = weight
+	return nil
+}
+
+func (f *fakeRecords) DeleteDomainRecord(recordId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.get(recordId); err != nil {
+		return err
+	}
+	delete(f.records, recordId)
+	return nil
+}
+
+func (f *fakeRecords) CheckWrite(string, string, string, *service.DomainRecordInput) error {
+	return nil
+}
+
+func TestRollbackRestoresDeletedRecord(t *testing.T) {
+	records := newFakeRecords(service.DomainRecord{
+		RecordId:   "1",
+		DomainName: "example.com",
+		RR:         "www",
+		Type:       "A",
+		Value:      "192.0.2.1",
+		TTL:        600,
+		Line:       "default",
+		Status:     service.RecordStatusDisable,
+		Remark:     "主站",
+		Weight:     30,
+	})
+	records.failOn = "192.0.2.9"
+
+	result := NewExecutor(records).Execute(context.Background(), "example.com", &Request{
+		Operations: []Operation{
+			{Action: ActionDelete, RecordId: "1"},
+			{Action: ActionCreate, Record: &service.DomainRecordInput{RR: "api", Type: "A", Value: "192.0.2.9"}},
+		},
+		Atomic:      true,
+		Concurrency: 1,
+	})
+
+	if !result.RolledBack {
+		t.Fatalf("期望回滚，得到 %+v", result)
+	}
+	deleted := result.Results[0]
+	if deleted.Status != StatusRolledBack || deleted.RestoredRecordId == "" {
+		t.Fatalf("删除操作的结果 = %+v", deleted)
+	}
+
+	restored, err := records.get(deleted.RestoredRecordId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.RR != "www" || restored.Value != "192.0.2.1" || restored.TTL != 600 || restored.Line != "default" {
+		t.Errorf("重新创建的记录 = %+v", restored)
+	}
+	if restored.Status != service.RecordStatusDisable {
+		t.Errorf("状态 = %s，期望恢复为暂停", restored.Status)
+	}
+	if restored.Remark != "主站" {
+		t.Errorf("备注 = %q，期望恢复原备注", restored.Remark)
+	}
+	if restored.Weight != 30 {
+		t.Errorf("权重 = %d，期望恢复原权重", restored.Weight)
+	}
+}
@@ -0,0 +1,123 @@
+package batch
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// 异步任务状态
+const (
+	JobStatusRunning  = "running"
+	JobStatusFinished = "finished"
+)
+
+// 异步任务相关的默认值
+const (
+	// AsyncThreshold 超过该数量的操作自动以异步任务执行
+	AsyncThreshold = 50
+	// JobRetention 已完成任务的保留时长
+	JobRetention = time.Hour
+)
+
+// Job 异步批量任务
+type Job struct {
+	Id         string     `json:"id"`
+	Domain     string     `json:"domain"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Result     *Result    `json:"result,omitempty"`
+}
+
+// JobManager 管理异步批量任务，任务只保存在内存中
+type JobManager struct {
+	executor *Executor
+	mu       sync.RWMutex
+	jobs     map[string]*Job
+}
+
+// NewJobManager 创建异步任务管理器
+func NewJobManager(executor *Executor) *JobManager {
+	return &JobManager{
+		executor: executor,
+		jobs:     make(map[string]*Job),
+	}
+}
+
+// Submit 提交异步批量任务并立即返回任务信息，executor 为 nil 时使用管理器的执行器
+func (m *JobManager) Submit(executor *Executor, domain string, req *Request) *Job {
+	if executor == nil {
+		executor = m.executor
+	}
+	job := &Job{
+		Id:        newJobId(),
+		Domain:    domain,
+		Status:    JobStatusRunning,
+		Total:     len(req.Operations),
+		CreatedAt: time.Now(),
+	}
+
+	m.mu.Lock()
+	m.purgeLocked()
+	m.jobs[job.Id] = job
+	m.mu.Unlock()
+
+	go func() {
+		result := executor.Execute(context.Background(), domain, req)
+		finishedAt := time.Now()
+
+		m.mu.Lock()
+		job.Status = JobStatusFinished
+		job.FinishedAt = &finishedAt
+		job.Result = result
+		m.mu.Unlock()
+	}()
+
+	return m.snapshot(job)
+}
+
+// Get 查询异步任务
+func (m *JobManager) Get(id string) (*Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	return m.copyLocked(job), true
+}
+
+// snapshot 返回任务的副本，避免调用方与执行中的任务产生竞争
+func (m *JobManager) snapshot(job *Job) *Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.copyLocked(job)
+}
+
+// copyLocked 复制任务，调用方需持有锁
+func (m *JobManager) copyLocked(job *Job) *Job {
+	c := *job
+	return &c
+}
+
+// purgeLocked 清理超过保留时长的已完成任务，调用方需持有写锁
+func (m *JobManager) purgeLocked() {
+	deadline := time.Now().Add(-JobRetention)
+	for id, job := range m.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(deadline) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// newJobId 生成随机任务ID
+func newJobId() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
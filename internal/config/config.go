@@ -3,16 +3,67 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
 )
 
 // Config 应用配置结构
 type Config struct {
-	Server ServerConfig `mapstructure:"server"`
-	Aliyun AliyunConfig `mapstructure:"aliyun"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Aliyun      AliyunConfig      `mapstructure:"aliyun"`
+	Acme        AcmeConfig        `mapstructure:"acme"`
+	ExternalDNS ExternalDNSConfig `mapstructure:"external_dns"`
+	Kubernetes  KubernetesConfig  `mapstructure:"kubernetes"`
+	Docker      DockerConfig      `mapstructure:"docker"`
+	Notify      NotifyConfig      `mapstructure:"notify"`
+	Failover    FailoverConfig    `mapstructure:"failover"`
+	Storage     StorageConfig     `mapstructure:"storage"`
+	Scheduler   SchedulerConfig   `mapstructure:"scheduler"`
+	Migration   MigrationConfig   `mapstructure:"migration"`
+	Lease       LeaseConfig       `mapstructure:"lease"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Approval    ApprovalConfig    `mapstructure:"approval"`
+	Policy      PolicyConfig      `mapstructure:"policy"`
+}
+
+// LoggingConfig 日志配置
+type LoggingConfig struct {
+	Level    string         `mapstructure:"level"`  // debug、info、warn、error
+	Format   string         `mapstructure:"format"` // json、console
+	Output   []string       `mapstructure:"output"` // stdout、stderr、file、syslog，可以同时输出到多个目标
+	File     LogFileConfig  `mapstructure:"file"`
+	Syslog   SyslogConfig   `mapstructure:"syslog"`
+	Sampling SamplingConfig `mapstructure:"sampling"`
+	Admins   []string       `mapstructure:"admins"` // 允许在运行时调整日志级别的身份，为空表示任意已认证身份
+}
+
+// LogFileConfig 日志文件配置，按大小轮转
+type LogFileConfig struct {
+	Path       string `mapstructure:"path"`
+	MaxSize    int    `mapstructure:"max_size"`    // 单个日志文件的最大大小（MB）
+	MaxBackups int    `mapstructure:"max_backups"` // 保留的旧日志文件数量，0 表示不限制
+	MaxAge     int    `mapstructure:"max_age"`     // 旧日志文件保留的天数，0 表示不限制
+	Compress   bool   `mapstructure:"compress"`    // 是否用 gzip 压缩旧日志文件
+}
+
+// SyslogConfig syslog 输出配置
+type SyslogConfig struct {
+	Network string `mapstructure:"network"` // tcp、udp，为空时连接本机 syslog
+	Address string `mapstructure:"address"`
+	Tag     string `mapstructure:"tag"`
+}
+
+// SamplingConfig 日志采样配置，initial 为 0 时不采样
+type SamplingConfig struct {
+	Initial    int           `mapstructure:"initial"`    // 每个周期内相同内容的日志先记录的条数
+	Thereafter int           `mapstructure:"thereafter"` // 之后每多少条记录一条
+	Tick       time.Duration `mapstructure:"tick"`       // 采样周期
 }
 
 // ServerConfig 服务器配置
@@ -22,9 +73,183 @@ type ServerConfig struct {
 
 // AliyunConfig 阿里云配置
 type AliyunConfig struct {
-	AccessKeyId     string `mapstructure:"access_key_id"`
-	AccessKeySecret string `mapstructure:"access_key_secret"`
-	RegionId        string `mapstructure:"region_id"`
+	AccessKeyId     string  `mapstructure:"access_key_id"`
+	AccessKeySecret string  `mapstructure:"access_key_secret"`
+	RegionId        string  `mapstructure:"region_id"`
+	RateLimit       float64 `mapstructure:"rate_limit"`       // 每秒最多调用接口的次数
+	RateBurst       int     `mapstructure:"rate_burst"`       // 允许的突发调用次数
+	PageConcurrency int     `mapstructure:"page_concurrency"` // 并发获取分页的数量
+}
+
+// AcmeConfig ACME DNS-01 验证接口配置，未配置凭证时不启用
+type AcmeConfig struct {
+	ChallengeTTL int64         `mapstructure:"challenge_ttl"` // 验证记录的TTL
+	StaleAfter   time.Duration `mapstructure:"stale_after"`   // 超过该时长未清理的验证记录会被自动删除
+	Tokens       []AcmeToken   `mapstructure:"tokens"`
+}
+
+// AcmeToken ACME 验证接口的访问凭证
+type AcmeToken struct {
+	Name     string   `mapstructure:"name"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	Domains  []string `mapstructure:"domains"` // 允许申请证书的域名，*.example.com 表示其下任意子域名
+	// acme-dns 客户端账户中的 subdomain 与证书域名的对应关系，lego 等客户端以注册得到的 subdomain 而非域名调用接口
+	Subdomains map[string]string `mapstructure:"subdomains"`
+}
+
+// ExternalDNSConfig external-dns webhook 配置
+type ExternalDNSConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`
+	DomainFilters  []string `mapstructure:"domain_filters"`  // 只管理这些域名及其子域名，为空表示全部
+	ExcludeDomains []string `mapstructure:"exclude_domains"` // 排除的域名
+	MinTTL         int64    `mapstructure:"min_ttl"`         // 低于该值的TTL会被提高
+}
+
+// KubernetesConfig Kubernetes 注解控制器配置
+type KubernetesConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Kubeconfig string        `mapstructure:"kubeconfig"` // 为空时使用集群内配置
+	Namespace  string        `mapstructure:"namespace"`  // 只监听该命名空间，为空表示全部
+	Gateway    bool          `mapstructure:"gateway"`    // 是否监听 Gateway API 的 Gateway 资源
+	TTL        int64         `mapstructure:"ttl"`        // 未设置 dns-update/ttl 注解时的TTL
+	Resync     time.Duration `mapstructure:"resync"`     // 定期全量同步的间隔
+	Workers    int           `mapstructure:"workers"`    // 并发处理的数量
+}
+
+// DockerConfig Docker 容器标签监听配置
+type DockerConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Host    string        `mapstructure:"host"`    // Docker Engine 地址
+	HostIP  string        `mapstructure:"host_ip"` // 解析记录指向的本机IP，为空时自动检测
+	Owner   string        `mapstructure:"owner"`   // 主机标识，为空时使用主机名
+	TTL     int64         `mapstructure:"ttl"`     // 未设置 dns-update.ttl 标签时的TTL
+	Resync  time.Duration `mapstructure:"resync"`  // 定期全量同步的间隔
+}
+
+// NotifyConfig 通知配置
+type NotifyConfig struct {
+	Webhooks []NotifyWebhook `mapstructure:"webhooks"`
+}
+
+// NotifyWebhook 通知的接收地址
+type NotifyWebhook struct {
+	Name   string `mapstructure:"name"`
+	URL    string `mapstructure:"url"`
+	Format string `mapstructure:"format"` // json、dingtalk、wecom 或 slack，默认 json
+}
+
+// FailoverConfig 健康检查故障转移配置
+type FailoverConfig struct {
+	Groups []FailoverGroup `mapstructure:"groups"`
+}
+
+// FailoverGroup 故障转移组
+type FailoverGroup struct {
+	Name     string        `mapstructure:"name"`
+	Domain   string        `mapstructure:"domain"`
+	RR       string        `mapstructure:"rr"`
+	Type     string        `mapstructure:"type"`
+	Mode     string        `mapstructure:"mode"` // switch：切换唯一记录的值；multi：暂停不健康的记录
+	Primary  string        `mapstructure:"primary"`
+	Backups  []string      `mapstructure:"backups"`
+	TTL      int64         `mapstructure:"ttl"`
+	Interval time.Duration `mapstructure:"interval"` // 检查间隔
+	Fall     int           `mapstructure:"fall"`     // 连续失败多少次判定为不健康
+	Rise     int           `mapstructure:"rise"`     // 连续成功多少次判定为恢复
+	Check    FailoverCheck `mapstructure:"check"`
+}
+
+// FailoverCheck 故障转移组的健康检查
+type FailoverCheck struct {
+	Type     string        `mapstructure:"type"` // http、tcp 或 icmp
+	Port     int           `mapstructure:"port"`
+	Scheme   string        `mapstructure:"scheme"`
+	Path     string        `mapstructure:"path"`
+	Host     string        `mapstructure:"host"`
+	Expect   []int         `mapstructure:"expect"` // 期望的状态码，默认 200-399
+	Insecure bool          `mapstructure:"insecure"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// StorageConfig 本地状态存储配置
+type StorageConfig struct {
+	Dir string `mapstructure:"dir"` // 定时任务等状态文件所在的目录
+}
+
+// SchedulerConfig 定时变更配置
+type SchedulerConfig struct {
+	MaxAttempts   int           `mapstructure:"max_attempts"`   // 默认的最多执行次数
+	RetryInterval time.Duration `mapstructure:"retry_interval"` // 第 n 次重试前等待 n 倍的间隔
+	MaxDelay      time.Duration `mapstructure:"max_delay"`      // 超过执行时间该时长仍未执行的任务不再执行
+}
+
+// MigrationConfig TTL 迁移流程配置
+type MigrationConfig struct {
+	LowTTL int64         `mapstructure:"low_ttl"` // 未指定时迁移期间使用的TTL
+	Margin time.Duration `mapstructure:"margin"`  // 等待缓存过期时额外等待的时长
+}
+
+// LeaseConfig 临时记录配置
+type LeaseConfig struct {
+	Interval          time.Duration `mapstructure:"interval"`           // 检查到期记录的间隔
+	ReconcileInterval time.Duration `mapstructure:"reconcile_interval"` // 核对记录是否已被手动删除的间隔
+	MaxDuration       time.Duration `mapstructure:"max_duration"`       // 租约的最长时长
+}
+
+// AuthConfig 接口访问令牌配置，请求通过 Authorization: Bearer <token> 标识调用方
+type AuthConfig struct {
+	Tokens []AuthToken `mapstructure:"tokens"`
+}
+
+// AuthToken 接口访问令牌
+type AuthToken struct {
+	Name  string `mapstructure:"name"` // 调用方身份，记录在审计日志中
+	Token string `mapstructure:"token"`
+}
+
+// ApprovalConfig 变更审批配置，受保护记录的写操作需要由其他人审批后执行
+type ApprovalConfig struct {
+	Rules       []ApprovalRule `mapstructure:"rules"`
+	Approvers   []string       `mapstructure:"approvers"`    // 允许审批的身份，为空表示除提交人以外的任意身份
+	ExpireAfter time.Duration  `mapstructure:"expire_after"` // 待审批的变更请求超过该时长自动失效
+}
+
+// PolicyConfig 写操作策略配置，用于变更冻结和关键记录保护
+type PolicyConfig struct {
+	Rules            []PolicyRule `mapstructure:"rules"`
+	BreakGlassActors []string     `mapstructure:"break_glass_actors"` // 允许紧急变更的身份，为空表示任意已认证身份
+}
+
+// PolicyRule 写操作策略规则
+type PolicyRule struct {
+	Name           string         `mapstructure:"name"`
+	Timezone       string         `mapstructure:"timezone"` // 时间窗口的时区，为空时使用本地时区
+	Windows        []PolicyWindow `mapstructure:"windows"`  // 只在这些时间窗口内生效，为空表示始终生效
+	Domains        []string       `mapstructure:"domains"`
+	RR             []string       `mapstructure:"rr"` // 主机记录通配符
+	Types          []string       `mapstructure:"types"`
+	Actions        []string       `mapstructure:"actions"`        // create、update、delete、enable、disable、remark、slb、custom_line、domain、group
+	AllowedActors  []string       `mapstructure:"allowed_actors"` // 不受该规则限制的身份，system 表示内部组件
+	Deny           bool           `mapstructure:"deny"`
+	ForbidDelete   bool           `mapstructure:"forbid_delete"`
+	MaxTTLDecrease int64          `mapstructure:"max_ttl_decrease"` // 一次最多降低的TTL（秒）
+	Message        string         `mapstructure:"message"`          // 拒绝时附加的说明
+}
+
+// PolicyWindow 策略生效的时间窗口，start/end 为一次性窗口，from/to 为每天或每周重复的窗口
+type PolicyWindow struct {
+	Start string   `mapstructure:"start"` // 格式 2006-01-02 15:04
+	End   string   `mapstructure:"end"`
+	Days  []string `mapstructure:"days"` // 如 mon、sat，为空表示每天
+	From  string   `mapstructure:"from"` // 格式 15:04
+	To    string   `mapstructure:"to"`
+}
+
+// ApprovalRule 受保护的域名和主机记录
+type ApprovalRule struct {
+	Domain string   `mapstructure:"domain"` // 域名，* 表示所有域名
+	RR     []string `mapstructure:"rr"`     // 主机记录通配符，为空表示所有主机记录
 }
 
 // validateConfig 验证配置参数
@@ -40,6 +265,95 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("阿里云RegionId未配置")
 	}
 
+	// 检查日志配置
+	if _, err := zapcore.ParseLevel(config.Logging.Level); err != nil {
+		return fmt.Errorf("日志级别 %s 无效，可选 debug、info、warn、error", config.Logging.Level)
+	}
+	if config.Logging.Format != "json" && config.Logging.Format != "console" {
+		return fmt.Errorf("日志格式 %s 无效，可选 json、console", config.Logging.Format)
+	}
+	for _, output := range config.Logging.Output {
+		if !slices.Contains([]string{"stdout", "stderr", "file", "syslog"}, output) {
+			return fmt.Errorf("日志输出 %s 无效，可选 stdout、stderr、file、syslog", output)
+		}
+	}
+	if config.Logging.File.MaxSize < 0 || config.Logging.File.MaxBackups < 0 || config.Logging.File.MaxAge < 0 {
+		return fmt.Errorf("日志文件的 max_size、max_backups、max_age 不能为负数")
+	}
+	if (config.Logging.Syslog.Network == "") != (config.Logging.Syslog.Address == "") {
+		return fmt.Errorf("syslog 的 network 和 address 需要同时配置")
+	}
+	if config.Logging.Sampling.Initial < 0 || config.Logging.Sampling.Thereafter < 0 {
+		return fmt.Errorf("日志采样的 initial、thereafter 不能为负数")
+	}
+
+	// 检查ACME凭证配置
+	for i, token := range config.Acme.Tokens {
+		if token.Username == "" || token.Password == "" {
+			return fmt.Errorf("ACME凭证[%d]的用户名和密码不能为空", i)
+		}
+		if len(token.Domains) == 0 {
+			return fmt.Errorf("ACME凭证[%d]未配置允许的域名", i)
+		}
+		for subdomain, domain := range token.Subdomains {
+			if domain == "" {
+				return fmt.Errorf("ACME凭证[%d]的subdomain %s未配置证书域名", i, subdomain)
+			}
+		}
+	}
+
+	// 检查访问令牌配置
+	names := make(map[string]bool)
+	for i, token := range config.Auth.Tokens {
+		if token.Name == "" || token.Token == "" {
+			return fmt.Errorf("访问令牌[%d]的名称和令牌不能为空", i)
+		}
+		if names[token.Name] {
+			return fmt.Errorf("访问令牌名称重复: %s", token.Name)
+		}
+		names[token.Name] = true
+	}
+
+	// 检查变更审批配置
+	if len(config.Approval.Rules) > 0 && len(config.Auth.Tokens) < 2 {
+		return fmt.Errorf("启用变更审批需要在auth.tokens中配置至少两个访问令牌")
+	}
+	for i, rule := range config.Approval.Rules {
+		if rule.Domain == "" {
+			return fmt.Errorf("变更审批规则[%d]的域名不能为空", i)
+		}
+	}
+	for _, approver := range config.Approval.Approvers {
+		if !names[approver] {
+			return fmt.Errorf("审批人 %s 未在auth.tokens中配置", approver)
+		}
+	}
+
+	for _, admin := range config.Logging.Admins {
+		if !names[admin] {
+			return fmt.Errorf("日志管理员 %s 未在auth.tokens中配置", admin)
+		}
+	}
+
+	// 检查策略配置
+	for _, actor := range config.Policy.BreakGlassActors {
+		if !names[actor] {
+			return fmt.Errorf("紧急变更身份 %s 未在auth.tokens中配置", actor)
+		}
+	}
+
+	// 检查通知配置
+	for i, webhook := range config.Notify.Webhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("通知地址[%d]的URL不能为空", i)
+		}
+		switch webhook.Format {
+		case "", "json", "dingtalk", "wecom", "slack":
+		default:
+			return fmt.Errorf("通知地址[%d]的格式不支持: %s", i, webhook.Format)
+		}
+	}
+
 	// 检查服务器端口配置
 	if config.Server.Port == "" || config.Server.Port == "${PORT}" {
 		return fmt.Errorf("服务器端口未配置")
@@ -59,6 +373,7 @@ func bindEnvVariables() error {
 		"aliyun.access_key_id":     "ACCESS_KEY_ID",
 		"aliyun.access_key_secret": "ACCESS_KEY_SECRET",
 		"aliyun.region_id":         "REGION_ID",
+		"logging.level":            "LOG_LEVEL",
 	}
 
 	for configKey, envKey := range envVars {
@@ -119,12 +434,94 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 
 	// 设置默认值
+	if config.Logging.Level == "" {
+		config.Logging.Level = "info"
+	}
+	if config.Logging.Format == "" {
+		config.Logging.Format = "json"
+	}
+	if len(config.Logging.Output) == 0 {
+		config.Logging.Output = []string{"stdout"}
+	}
+	if config.Logging.File.Path == "" {
+		config.Logging.File.Path = "logs/app.log"
+	}
+	if config.Logging.File.MaxSize == 0 {
+		config.Logging.File.MaxSize = 100
+	}
+	if config.Logging.Sampling.Tick == 0 {
+		config.Logging.Sampling.Tick = time.Second
+	}
+
 	if config.Server.Port == "" {
 		config.Server.Port = "8080"
 	}
 	if config.Aliyun.RegionId == "" {
 		config.Aliyun.RegionId = "cn-hangzhou"
 	}
+	if config.Aliyun.RateLimit == 0 {
+		config.Aliyun.RateLimit = 10
+	}
+	if config.Aliyun.RateBurst == 0 {
+		config.Aliyun.RateBurst = int(config.Aliyun.RateLimit)
+	}
+	if config.Aliyun.PageConcurrency == 0 {
+		config.Aliyun.PageConcurrency = 5
+	}
+	if config.Acme.ChallengeTTL == 0 {
+		config.Acme.ChallengeTTL = 600
+	}
+	if config.Acme.StaleAfter == 0 {
+		config.Acme.StaleAfter = time.Hour
+	}
+
+	if config.Kubernetes.Resync == 0 {
+		config.Kubernetes.Resync = 10 * time.Minute
+	}
+	if config.Kubernetes.Workers == 0 {
+		config.Kubernetes.Workers = 2
+	}
+
+	if config.Docker.Host == "" {
+		config.Docker.Host = "unix:///var/run/docker.sock"
+	}
+	if config.Docker.Resync == 0 {
+		config.Docker.Resync = 10 * time.Minute
+	}
+
+	if config.Storage.Dir == "" {
+		config.Storage.Dir = "data"
+	}
+	if config.Scheduler.MaxAttempts == 0 {
+		config.Scheduler.MaxAttempts = 3
+	}
+	if config.Scheduler.RetryInterval == 0 {
+		config.Scheduler.RetryInterval = time.Minute
+	}
+	if config.Scheduler.MaxDelay == 0 {
+		config.Scheduler.MaxDelay = time.Hour
+	}
+
+	if config.Migration.LowTTL == 0 {
+		config.Migration.LowTTL = 600
+	}
+	if config.Migration.Margin == 0 {
+		config.Migration.Margin = 30 * time.Second
+	}
+
+	if config.Lease.Interval == 0 {
+		config.Lease.Interval = 30 * time.Second
+	}
+	if config.Lease.ReconcileInterval == 0 {
+		config.Lease.ReconcileInterval = 10 * time.Minute
+	}
+	if config.Lease.MaxDuration == 0 {
+		config.Lease.MaxDuration = 90 * 24 * time.Hour
+	}
+
+	if config.Approval.ExpireAfter == 0 {
+		config.Approval.ExpireAfter = 72 * time.Hour
+	}
 
 	// 验证配置
 	if err := validateConfig(&config); err != nil {
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"dns-update/internal/service"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// 控制器使用的注解和 finalizer
+const (
+	// AnnotationHostname 需要解析的主机名，多个主机名用逗号分隔
+	AnnotationHostname = "dns-update/hostname"
+	// AnnotationTTL 解析记录的TTL
+	AnnotationTTL = "dns-update/ttl"
+	// AnnotationManaged 控制器已创建解析记录的主机名，用于主机名变更后清理旧记录
+	AnnotationManaged = "dns-update/managed-hostnames"
+	// AnnotationCondition 没有 status.conditions 的资源（Ingress）通过该注解报告同步状态
+	AnnotationCondition = "dns-update/condition"
+	// Finalizer 删除资源前清理解析记录
+	Finalizer = "dns-update/finalizer"
+	// ConditionType 同步状态的条件类型
+	ConditionType = "DNSSynced"
+)
+
+// RecordService 控制器依赖的解析记录服务
+type RecordService interface {
+	ResolveZone(fqdn string) (string, string, error)
+	QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error)
+	AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error)
+	UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error
+	SetDomainRecordRemark(recordId, remark string) error
+	DeleteDomainRecord(recordId string) error
+}
+
+// Options 控制器的选项
+type Options struct {
+	Namespace string        // 只监听该命名空间，为空表示全部
+	Gateway   bool          // 是否监听 Gateway API 的 Gateway 资源
+	TTL       int64         // 默认TTL，0 表示使用阿里云的默认值
+	Resync    time.Duration // 定期全量同步的间隔
+	Workers   int           // 并发处理的数量
+	// Protects 判断主机记录是否受变更审批保护，受保护的主机名不同步也不清理
+	Protects func(domain, rr string) bool
+}
+
+// DefaultOptions 默认的控制器选项
+var DefaultOptions = Options{
+	Gateway: true,
+	Resync:  10 * time.Minute,
+	Workers: 2,
+}
+
+// Controller 监听带有 dns-update/hostname 注解的 Ingress、Service 和 Gateway，同步阿里云解析记录
+type Controller struct {
+	kube    kubernetes.Interface
+	dynamic dynamic.Interface
+	records RecordService
+	opts    Options
+	log     *zap.Logger
+
+	kinds map[string]kind
+	queue workqueue.TypedRateLimitingInterface[string]
+}
+
+// New 创建控制器，dynamic 客户端用于 Gateway 资源，不监听 Gateway 时可以为 nil
+func New(kube kubernetes.Interface, dyn dynamic.Interface, records RecordService, opts *Options) *Controller {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	o := *opts
+	if o.Resync <= 0 {
+		o.Resync = DefaultOptions.Resync
+	}
+	if o.Workers <= 0 {
+		o.Workers = DefaultOptions.Workers
+	}
+
+	c := &Controller{
+		kube:    kube,
+		dynamic: dyn,
+		records: records,
+		opts:    o,
+		log:     logger.GetLogger(),
+		kinds:   make(map[string]kind),
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "dns-update"},
+		),
+	}
+
+	c.register(newIngressKind(kube, o.Namespace, o.Resync))
+	c.register(newServiceKind(kube, o.Namespace, o.Resync))
+	if o.Gateway && dyn != nil {
+		if gatewayAvailable(kube) {
+			c.register(newGatewayKind(dyn, o.Namespace, o.Resync))
+		} else {
+			c.log.Warn("集群未安装 Gateway API，不监听 Gateway 资源")
+		}
+	}
+	return c
+}
+
+// Run 启动控制器，直到 ctx 被取消
+func (c *Controller) Run(ctx context.Context) error {
+	defer c.queue.ShutDown()
+
+	var synced []cache.InformerSynced
+	for _, k := range c.kinds {
+		informer := k.informer()
+		go informer.Run(ctx.Done())
+		synced = append(synced, informer.HasSynced)
+	}
+
+	c.log.Info("Kubernetes 控制器启动，等待缓存同步",
+		zap.String("namespace", c.opts.Namespace),
+		zap.Int("kinds", len(c.kinds)),
+	)
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return fmt.Errorf("等待 Kubernetes 缓存同步失败")
+	}
+
+	for i := 0; i < c.opts.Workers; i++ {
+		go wait.UntilWithContext(ctx, c.runWorker, time.Second)
+	}
+
+	c.log.Info("Kubernetes 控制器已启动", zap.Int("workers", c.opts.Workers))
+	<-ctx.Done()
+	c.log.Info("Kubernetes 控制器已停止")
+	return nil
+}
+
+// register 注册资源类型并监听其事件
+func (c *Controller) register(k kind) {
+	c.kinds[k.name()] = k
+
+	enqueue := func(obj any) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		// 只处理带有注解或 finalizer 的资源
+		_, annotated := accessor.GetAnnotations()[AnnotationHostname]
+		if !annotated && !hasFinalizer(accessor) {
+			return
+		}
+		c.queue.Add(k.name() + "/" + accessor.GetNamespace() + "/" + accessor.GetName())
+	}
+
+	_, _ = k.informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueue,
+		UpdateFunc: func(_, obj any) { enqueue(obj) },
+		DeleteFunc: enqueue,
+	})
+}
+
+// runWorker 持续处理队列中的资源
+func (c *Controller) runWorker(ctx context.Context) {
+	for c.processNext(ctx) {
+	}
+}
+
+// processNext 处理队列中的下一个资源，队列关闭时返回 false
+func (c *Controller) processNext(ctx context.Context) bool {
+	key, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.Reconcile(ctx, key); err != nil {
+		c.log.Error("同步解析记录失败，稍后重试",
+			zap.String("key", key),
+			zap.Int("retries", c.queue.NumRequeues(key)),
+			zap.Error(err),
+		)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+	return true
+}
+
+// splitKey 拆分队列中的资源键：类型/命名空间/名称
+func splitKey(key string) (string, string, string, error) {
+	parts := strings.SplitN(key, "/", 3)
+	if len(parts) != 3 {
+		return "", "", "", fmt.Errorf("无效的资源键: %s", key)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
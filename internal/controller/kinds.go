@@ -0,0 +1,324 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// gatewayResource Gateway API 的 Gateway 资源
+var gatewayResource = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "gateways",
+}
+
+// resource 控制器处理的资源，屏蔽不同类型的差异
+type resource struct {
+	obj       runtime.Object
+	meta      metav1.Object
+	ips       []string // 负载均衡的 IP 地址
+	hostnames []string // 负载均衡的主机名
+	notReady  string   // 没有可用地址的原因
+}
+
+// kind 一种被监听的资源类型
+type kind interface {
+	name() string
+	informer() cache.SharedIndexInformer
+	// get 从缓存中获取资源的副本，资源不存在时返回 nil
+	get(namespace, name string) (*resource, error)
+	// update 保存资源的注解和 finalizer
+	update(ctx context.Context, r *resource) error
+	// setCondition 设置同步状态，状态未变化时不写入
+	setCondition(ctx context.Context, r *resource, condition metav1.Condition) error
+}
+
+// ingressKind networking.k8s.io/v1 Ingress
+type ingressKind struct {
+	kube   kubernetes.Interface
+	inf    cache.SharedIndexInformer
+	lister networkinglisters.IngressLister
+}
+
+func newIngressKind(kube kubernetes.Interface, namespace string, resync time.Duration) *ingressKind {
+	ingresses := informers.NewSharedInformerFactoryWithOptions(kube, resync, informers.WithNamespace(namespace)).
+		Networking().V1().Ingresses()
+	return &ingressKind{
+		kube:   kube,
+		inf:    ingresses.Informer(),
+		lister: ingresses.Lister(),
+	}
+}
+
+func (k *ingressKind) name() string                        { return "Ingress" }
+func (k *ingressKind) informer() cache.SharedIndexInformer { return k.inf }
+
+func (k *ingressKind) get(namespace, name string) (*resource, error) {
+	ing, err := k.lister.Ingresses(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ing = ing.DeepCopy()
+
+	r := &resource{obj: ing, meta: ing}
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		r.addAddress(lb.IP, lb.Hostname)
+	}
+	if len(r.ips) == 0 && len(r.hostnames) == 0 {
+		r.notReady = "等待 Ingress 分配负载均衡地址"
+	}
+	return r, nil
+}
+
+func (k *ingressKind) update(ctx context.Context, r *resource) error {
+	ing := r.obj.(*networkingv1.Ingress)
+	updated, err := k.kube.NetworkingV1().Ingresses(ing.Namespace).Update(ctx, ing, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	*ing = *updated
+	return nil
+}
+
+// setCondition Ingress 没有 status.conditions，同步状态写入注解
+func (k *ingressKind) setCondition(ctx context.Context, r *resource, condition metav1.Condition) error {
+	var conditions []metav1.Condition
+	if data, ok := r.meta.GetAnnotations()[AnnotationCondition]; ok {
+		var existing metav1.Condition
+		if json.Unmarshal([]byte(data), &existing) == nil {
+			conditions = append(conditions, existing)
+		}
+	}
+	if !meta.SetStatusCondition(&conditions, condition) {
+		return nil
+	}
+
+	data, err := json.Marshal(meta.FindStatusCondition(conditions, condition.Type))
+	if err != nil {
+		return err
+	}
+	setAnnotation(r.meta, AnnotationCondition, string(data))
+	return k.update(ctx, r)
+}
+
+// serviceKind LoadBalancer 类型的 Service
+type serviceKind struct {
+	kube   kubernetes.Interface
+	inf    cache.SharedIndexInformer
+	lister corelisters.ServiceLister
+}
+
+func newServiceKind(kube kubernetes.Interface, namespace string, resync time.Duration) *serviceKind {
+	services := informers.NewSharedInformerFactoryWithOptions(kube, resync, informers.WithNamespace(namespace)).
+		Core().V1().Services()
+	return &serviceKind{
+		kube:   kube,
+		inf:    services.Informer(),
+		lister: services.Lister(),
+	}
+}
+
+func (k *serviceKind) name() string                        { return "Service" }
+func (k *serviceKind) informer() cache.SharedIndexInformer { return k.inf }
+
+func (k *serviceKind) get(namespace, name string) (*resource, error) {
+	svc, err := k.lister.Services(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	svc = svc.DeepCopy()
+
+	r := &resource{obj: svc, meta: svc}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		r.notReady = "只支持 LoadBalancer 类型的 Service"
+		return r, nil
+	}
+	for _, lb := range svc.Status.LoadBalancer.Ingress {
+		r.addAddress(lb.IP, lb.Hostname)
+	}
+	if len(r.ips) == 0 && len(r.hostnames) == 0 {
+		r.notReady = "等待 Service 分配负载均衡地址"
+	}
+	return r, nil
+}
+
+func (k *serviceKind) update(ctx context.Context, r *resource) error {
+	svc := r.obj.(*corev1.Service)
+	updated, err := k.kube.CoreV1().Services(svc.Namespace).Update(ctx, svc, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	*svc = *updated
+	return nil
+}
+
+func (k *serviceKind) setCondition(ctx context.Context, r *resource, condition metav1.Condition) error {
+	svc := r.obj.(*corev1.Service)
+	condition.ObservedGeneration = svc.Generation
+	if !meta.SetStatusCondition(&svc.Status.Conditions, condition) {
+		return nil
+	}
+
+	updated, err := k.kube.CoreV1().Services(svc.Namespace).UpdateStatus(ctx, svc, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	*svc = *updated
+	return nil
+}
+
+// gatewayKind gateway.networking.k8s.io/v1 Gateway，通过 dynamic 客户端访问
+type gatewayKind struct {
+	dynamic dynamic.Interface
+	inf     cache.SharedIndexInformer
+}
+
+func newGatewayKind(dyn dynamic.Interface, namespace string, resync time.Duration) *gatewayKind {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dyn, resync, namespace, nil)
+	return &gatewayKind{
+		dynamic: dyn,
+		inf:     factory.ForResource(gatewayResource).Informer(),
+	}
+}
+
+func (k *gatewayKind) name() string                        { return "Gateway" }
+func (k *gatewayKind) informer() cache.SharedIndexInformer { return k.inf }
+
+func (k *gatewayKind) get(namespace, name string) (*resource, error) {
+	obj, exists, err := k.inf.GetIndexer().GetByKey(namespace + "/" + name)
+	if err != nil || !exists {
+		return nil, err
+	}
+	gw := obj.(*unstructured.Unstructured).DeepCopy()
+
+	r := &resource{obj: gw, meta: gw}
+	addresses, _, _ := unstructured.NestedSlice(gw.Object, "status", "addresses")
+	for _, item := range addresses {
+		address, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		value, _ := address["value"].(string)
+		switch addressType, _ := address["type"].(string); addressType {
+		case "", "IPAddress":
+			r.addAddress(value, "")
+		case "Hostname":
+			r.addAddress("", value)
+		}
+	}
+	if len(r.ips) == 0 && len(r.hostnames) == 0 {
+		r.notReady = "等待 Gateway 分配地址"
+	}
+	return r, nil
+}
+
+func (k *gatewayKind) update(ctx context.Context, r *resource) error {
+	gw := r.obj.(*unstructured.Unstructured)
+	updated, err := k.dynamic.Resource(gatewayResource).Namespace(gw.GetNamespace()).Update(ctx, gw, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	gw.Object = updated.Object
+	return nil
+}
+
+func (k *gatewayKind) setCondition(ctx context.Context, r *resource, condition metav1.Condition) error {
+	gw := r.obj.(*unstructured.Unstructured)
+	condition.ObservedGeneration = gw.GetGeneration()
+
+	var conditions []metav1.Condition
+	items, _, _ := unstructured.NestedSlice(gw.Object, "status", "conditions")
+	for _, item := range items {
+		var c metav1.Condition
+		if m, ok := item.(map[string]any); ok && runtime.DefaultUnstructuredConverter.FromUnstructured(m, &c) == nil {
+			conditions = append(conditions, c)
+		}
+	}
+	if !meta.SetStatusCondition(&conditions, condition) {
+		return nil
+	}
+
+	items = items[:0]
+	for i := range conditions {
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+		if err != nil {
+			return err
+		}
+		items = append(items, m)
+	}
+	if err := unstructured.SetNestedSlice(gw.Object, items, "status", "conditions"); err != nil {
+		return err
+	}
+
+	updated, err := k.dynamic.Resource(gatewayResource).Namespace(gw.GetNamespace()).UpdateStatus(ctx, gw, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	gw.Object = updated.Object
+	return nil
+}
+
+// gatewayAvailable 判断集群是否安装了 Gateway API
+func gatewayAvailable(kube kubernetes.Interface) bool {
+	resources, err := kube.Discovery().ServerResourcesForGroupVersion(gatewayResource.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(resources.APIResources, func(r metav1.APIResource) bool {
+		return r.Name == gatewayResource.Resource
+	})
+}
+
+// addAddress 记录负载均衡地址
+func (r *resource) addAddress(ip, hostname string) {
+	if ip != "" && !slices.Contains(r.ips, ip) {
+		r.ips = append(r.ips, ip)
+	}
+	if hostname != "" && !slices.Contains(r.hostnames, hostname) {
+		r.hostnames = append(r.hostnames, hostname)
+	}
+}
+
+// setAnnotation 设置注解，值为空时删除
+func setAnnotation(obj metav1.Object, key, value string) {
+	annotations := obj.GetAnnotations()
+	if value == "" {
+		delete(annotations, key)
+		obj.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
+}
+
+// hasFinalizer 判断资源是否带有控制器的 finalizer
+func hasFinalizer(obj metav1.Object) bool {
+	return slices.Contains(obj.GetFinalizers(), Finalizer)
+}
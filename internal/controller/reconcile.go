@@ -0,0 +1,305 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+	"dns-update/internal/validation"
+	"dns-update/pkg/redact"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// remarkPrefix 控制器创建的解析记录的备注前缀，后接资源键，用于识别记录的归属
+const remarkPrefix = "k8s:"
+
+// 同步状态的原因
+const (
+	ReasonSynced          = "Synced"
+	ReasonSyncFailed      = "SyncFailed"
+	ReasonAddressNotReady = "AddressNotReady"
+)
+
+// target 期望的解析记录
+type target struct {
+	Type  string
+	Value string
+}
+
+// Reconcile 同步单个资源的解析记录，key 格式为 类型/命名空间/名称
+func (c *Controller) Reconcile(ctx context.Context, key string) error {
+	kindName, namespace, name, err := splitKey(key)
+	if err != nil {
+		return nil
+	}
+	k, ok := c.kinds[kindName]
+	if !ok {
+		return nil
+	}
+
+	r, err := k.get(namespace, name)
+	if err != nil || r == nil {
+		return err
+	}
+
+	owner := remarkPrefix + key
+	hostnames := parseHostnames(r.meta.GetAnnotations()[AnnotationHostname])
+	managed := parseHostnames(r.meta.GetAnnotations()[AnnotationManaged])
+
+	// 资源被删除或去掉注解时清理解析记录并移除 finalizer
+	if r.meta.GetDeletionTimestamp() != nil || len(hostnames) == 0 {
+		if !hasFinalizer(r.meta) {
+			return nil
+		}
+		for _, hostname := range union(managed, hostnames) {
+			if err := c.cleanup(owner, hostname); err != nil {
+				return err
+			}
+		}
+		r.meta.SetFinalizers(slices.DeleteFunc(r.meta.GetFinalizers(), func(f string) bool { return f == Finalizer }))
+		setAnnotation(r.meta, AnnotationManaged, "")
+		if err := k.update(ctx, r); err != nil {
+			return err
+		}
+		c.log.Info("已清理资源的解析记录", zap.String("key", key), zap.Strings("hostnames", managed))
+		return nil
+	}
+
+	// 先添加 finalizer，确保之后创建的记录在资源删除时能被清理
+	if !hasFinalizer(r.meta) {
+		r.meta.SetFinalizers(append(r.meta.GetFinalizers(), Finalizer))
+		if err := k.update(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	ttl := c.opts.TTL
+	if value, ok := r.meta.GetAnnotations()[AnnotationTTL]; ok {
+		if ttl, err = strconv.ParseInt(value, 10, 64); err != nil || validation.ValidateTTL(ttl) != nil {
+			return c.setCondition(ctx, k, r, metav1.ConditionFalse, ReasonSyncFailed, fmt.Sprintf("%s 注解无效: %s", AnnotationTTL, value))
+		}
+	}
+
+	targets := desiredTargets(r)
+	if len(targets) == 0 {
+		return c.setCondition(ctx, k, r, metav1.ConditionFalse, ReasonAddressNotReady, r.notReady)
+	}
+
+	var errs []error
+	for _, hostname := range hostnames {
+		if err := c.sync(owner, hostname, targets, ttl); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hostname, err))
+		}
+	}
+	// 清理已从注解中移除的主机名
+	for _, hostname := range managed {
+		if slices.Contains(hostnames, hostname) {
+			continue
+		}
+		if err := c.cleanup(owner, hostname); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hostname, err))
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	if joined := strings.Join(hostnames, ","); joined != r.meta.GetAnnotations()[AnnotationManaged] {
+		setAnnotation(r.meta, AnnotationManaged, joined)
+		if err := k.update(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		if condErr := c.setCondition(ctx, k, r, metav1.ConditionFalse, ReasonSyncFailed, redact.String(err.Error())); condErr != nil {
+			c.log.Error("更新同步状态失败", zap.String("key", key), zap.Error(condErr))
+		}
+		return err
+	}
+	return c.setCondition(ctx, k, r, metav1.ConditionTrue, ReasonSynced, fmt.Sprintf("已同步 %d 个主机名", len(hostnames)))
+}
+
+// sync 将主机名的 A/AAAA/CNAME 记录同步为期望的目标
+//
+// 只修改控制器为该资源创建的记录；同名下存在其他来源的记录时报告冲突而不覆盖
+func (c *Controller) sync(owner, hostname string, targets []target, ttl int64) error {
+	zone, rr, err := c.records.ResolveZone(hostname)
+	if err != nil {
+		return err
+	}
+	if err := c.checkProtected(zone, rr, hostname); err != nil {
+		return err
+	}
+	existing, err := c.addressRecords(zone, rr)
+	if err != nil {
+		return err
+	}
+
+	owned := make(map[target]service.DomainRecord)
+	for _, r := range existing {
+		if r.Remark != owner {
+			return apperror.Conflict(fmt.Sprintf("已存在不属于该资源的%s记录(%s)", r.Type, r.Value))
+		}
+		owned[target{Type: r.Type, Value: strings.TrimSuffix(r.Value, ".")}] = r
+	}
+
+	// 先删除不再需要的记录，避免 CNAME 与 A/AAAA 共存
+	for t, r := range owned {
+		if slices.Contains(targets, t) {
+			continue
+		}
+		if err := c.records.DeleteDomainRecord(r.RecordId); err != nil && !apperror.IsNotFound(err) {
+			return err
+		}
+	}
+
+	for _, t := range targets {
+		input := &service.DomainRecordInput{RR: rr, Type: t.Type, Value: t.Value, TTL: ttl}
+		r, ok := owned[t]
+		if ok {
+			if ttl != 0 && r.TTL != ttl {
+				if err := c.records.UpdateDomainRecord(r.RecordId, input); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		recordId, err := c.records.AddDomainRecord(zone, input)
+		if err != nil {
+			return err
+		}
+		if err := c.records.SetDomainRecordRemark(recordId, owner); err != nil {
+			// 没有备注的记录无法识别归属，删除后重试
+			_ = c.records.DeleteDomainRecord(recordId)
+			return err
+		}
+		c.log.Info("已创建解析记录",
+			zap.String("owner", owner),
+			zap.String("hostname", hostname),
+			zap.String("type", t.Type),
+			zap.String("value", t.Value),
+		)
+	}
+	return nil
+}
+
+// cleanup 删除控制器为资源创建的主机名记录
+func (c *Controller) cleanup(owner, hostname string) error {
+	zone, rr, err := c.records.ResolveZone(hostname)
+	if apperror.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	existing, err := c.addressRecords(zone, rr)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range existing {
+		if r.Remark != owner {
+			continue
+		}
+		// 规则在创建记录后才添加时，保留记录并报告错误
+		if err := c.checkProtected(zone, rr, hostname); err != nil {
+			return err
+		}
+		if err := c.records.DeleteDomainRecord(r.RecordId); err != nil && !apperror.IsNotFound(err) {
+			return err
+		}
+		c.log.Info("已删除解析记录",
+			zap.String("owner", owner),
+			zap.String("hostname", hostname),
+			zap.String("type", r.Type),
+			zap.String("value", r.Value),
+		)
+	}
+	return nil
+}
+
+// checkProtected 受变更审批保护的主机名拒绝自动写入
+func (c *Controller) checkProtected(zone, rr, hostname string) error {
+	if c.opts.Protects != nil && c.opts.Protects(zone, rr) {
+		return apperror.Forbidden(hostname + " 受变更审批保护，请通过审批流程修改")
+	}
+	return nil
+}
+
+// setCondition 设置资源的同步状态
+func (c *Controller) setCondition(ctx context.Context, k kind, r *resource, status metav1.ConditionStatus, reason, message string) error {
+	return k.setCondition(ctx, r, metav1.Condition{
+		Type:    ConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// addressRecords 查询主机名下的 A/AAAA/CNAME 记录
+func (c *Controller) addressRecords(zone, rr string) ([]service.DomainRecord, error) {
+	records, err := c.records.QueryDomainRecords(&service.RecordQuery{
+		DomainName: zone,
+		RR:         rr,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(records, func(r service.DomainRecord) bool {
+		return r.Type != validation.TypeA && r.Type != validation.TypeAAAA && r.Type != validation.TypeCNAME
+	}), nil
+}
+
+// desiredTargets 根据负载均衡地址生成期望的记录：有 IP 时使用 A/AAAA，否则 CNAME 到第一个主机名
+func desiredTargets(r *resource) []target {
+	var targets []target
+	for _, ip := range r.ips {
+		parsed := net.ParseIP(ip)
+		switch {
+		case parsed == nil:
+			continue
+		case parsed.To4() != nil:
+			targets = append(targets, target{Type: validation.TypeA, Value: ip})
+		default:
+			targets = append(targets, target{Type: validation.TypeAAAA, Value: ip})
+		}
+	}
+	if len(targets) > 0 || len(r.hostnames) == 0 {
+		return targets
+	}
+
+	hostnames := slices.Clone(r.hostnames)
+	slices.Sort(hostnames)
+	return []target{{Type: validation.TypeCNAME, Value: strings.ToLower(strings.TrimSuffix(hostnames[0], "."))}}
+}
+
+// parseHostnames 解析逗号分隔的主机名列表
+func parseHostnames(value string) []string {
+	var hostnames []string
+	for _, hostname := range strings.Split(value, ",") {
+		hostname = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(hostname), "."))
+		if hostname != "" && !slices.Contains(hostnames, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
+// union 合并两个主机名列表并去重
+func union(a, b []string) []string {
+	result := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
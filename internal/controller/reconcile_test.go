@@ -0,0 +1,441 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fakeRecords 内存中的解析记录服务
+type fakeRecords struct {
+	mu      sync.Mutex
+	zones   []string
+	records map[string]*service.DomainRecord
+	nextId  int
+}
+
+func newFakeRecords(zones ...string) *fakeRecords {
+	return &fakeRecords{zones: zones, records: make(map[string]*service.DomainRecord)}
+}
+
+// seed 添加一条已存在的记录
+func (f *fakeRecords) seed(r service.DomainRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[r.RecordId] = &r
+}
+
+// list 按主机记录、类型、记录值排序返回所有记录
+func (f *fakeRecords) list() []service.DomainRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var records []service.DomainRecord
+	for _, r := range f.records {
+		records = append(records, *r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		return a.DomainName+a.RR+a.Type+a.Value < b.DomainName+b.RR+b.Type+b.Value
+	})
+	return records
+}
+
+func (f *fakeRecords) ResolveZone(fqdn string) (string, string, error) {
+	best := ""
+	for _, zone := range f.zones {
+		if (fqdn == zone || strings.HasSuffix(fqdn, "."+zone)) && len(zone) > len(best) {
+			best = zone
+		}
+	}
+	switch best {
+	case "":
+		return "", "", apperror.NotFound("账户下没有托管 " + fqdn + " 的域名")
+	case fqdn:
+		return best, "@", nil
+	}
+	return best, strings.TrimSuffix(fqdn, "."+best), nil
+}
+
+func (f *fakeRecords) QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error) {
+	var records []service.DomainRecord
+	for _, r := range f.list() {
+		if r.DomainName == query.DomainName && (query.RR == "" || r.RR == query.RR) {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+func (f *fakeRecords) AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextId++
+	id := fmt.Sprintf("new-%d", f.nextId)
+	f.records[id] = &service.DomainRecord{
+		RecordId:   id,
+		DomainName: domainName,
+		RR:         input.RR,
+		Type:       input.Type,
+		Value:      input.Value,
+		TTL:        input.TTL,
+	}
+	return id, nil
+}
+
+func (f *fakeRecords) UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.records[recordId]
+	if !ok {
+		return apperror.NotFound("记录不存在")
+	}
+	r.RR, r.Type, r.Value, r.TTL = input.RR, input.Type, input.Value, input.TTL
+	return nil
+}
+
+func (f *fakeRecords) SetDomainRecordRemark(recordId, remark string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.records[recordId]
+	if !ok {
+		return apperror.NotFound("记录不存在")
+	}
+	r.Remark = remark
+	return nil
+}
+
+func (f *fakeRecords) DeleteDomainRecord(recordId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.records[recordId]; !ok {
+		return apperror.NotFound("记录不存在")
+	}
+	delete(f.records, recordId)
+	return nil
+}
+
+// testEnv 使用 fake 客户端的控制器
+type testEnv struct {
+	t       *testing.T
+	ctx     context.Context
+	kube    *fake.Clientset
+	dynamic *dynamicfake.FakeDynamicClient
+	records *fakeRecords
+	c       *Controller
+}
+
+// newTestEnv 创建控制器并启动各资源的 informer
+func newTestEnv(t *testing.T, records *fakeRecords, objects ...runtime.Object) *testEnv {
+	t.Helper()
+
+	var kubeObjects []runtime.Object
+	var gateways []*unstructured.Unstructured
+	for _, obj := range objects {
+		if gw, ok := obj.(*unstructured.Unstructured); ok {
+			gateways = append(gateways, gw)
+		} else {
+			kubeObjects = append(kubeObjects, obj)
+		}
+	}
+
+	kube := fake.NewClientset(kubeObjects...)
+	kube.Resources = []*metav1.APIResourceList{{
+		GroupVersion: gatewayResource.GroupVersion().String(),
+		APIResources: []metav1.APIResource{{Name: gatewayResource.Resource, Namespaced: true, Kind: "Gateway"}},
+	}}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gatewayResource: "GatewayList"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// 通过客户端创建 Gateway，初始化时传入的对象无法按 GVR 列出
+	for _, gw := range gateways {
+		if _, err := dyn.Resource(gatewayResource).Namespace(gw.GetNamespace()).Create(ctx, gw, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := New(kube, dyn, records, &Options{Gateway: true})
+	var synced []cache.InformerSynced
+	for _, k := range c.kinds {
+		go k.informer().Run(ctx.Done())
+		synced = append(synced, k.informer().HasSynced)
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		t.Fatal("等待缓存同步失败")
+	}
+	return &testEnv{t: t, ctx: ctx, kube: kube, dynamic: dyn, records: records, c: c}
+}
+
+// wait 等待缓存中的资源满足条件，ready 为 nil 时只等待资源存在
+func (e *testEnv) wait(key string, ready func(*resource) bool) *resource {
+	e.t.Helper()
+	kindName, namespace, name, err := splitKey(key)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r, err := e.c.kinds[kindName].get(namespace, name)
+		if err == nil && r != nil && (ready == nil || ready(r)) {
+			return r
+		}
+		if time.Now().After(deadline) {
+			e.t.Fatalf("等待 %s 的缓存更新超时", key)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// reconcile 等待缓存中的资源满足条件后同步
+func (e *testEnv) reconcile(key string, ready func(*resource) bool) error {
+	e.t.Helper()
+	e.wait(key, ready)
+	return e.c.Reconcile(e.ctx, key)
+}
+
+// withFinalizer 缓存中的资源已经添加 finalizer
+func withFinalizer(r *resource) bool {
+	return hasFinalizer(r.meta)
+}
+
+// withIP 缓存中的资源已经分配指定 IP
+func withIP(ip string) func(*resource) bool {
+	return func(r *resource) bool {
+		return hasFinalizer(r.meta) && slices.Contains(r.ips, ip)
+	}
+}
+
+// deleting 缓存中的资源正在删除
+func deleting(r *resource) bool {
+	return r.meta.GetDeletionTimestamp() != nil
+}
+
+func newIngress(name, hostname, ip string) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			Annotations: map[string]string{AnnotationHostname: hostname},
+		},
+		Status: networkingv1.IngressStatus{
+			LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+				Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: ip}},
+			},
+		},
+	}
+}
+
+func newLoadBalancer(name, hostname, ip string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			Annotations: map[string]string{AnnotationHostname: hostname},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: ip}},
+			},
+		},
+	}
+}
+
+func newGateway(name, hostname, address string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata": map[string]any{
+			"namespace":   "default",
+			"name":        name,
+			"annotations": map[string]any{AnnotationHostname: hostname},
+		},
+		"status": map[string]any{
+			"addresses": []any{map[string]any{"type": "Hostname", "value": address}},
+		},
+	}}
+}
+
+// assertRecords 检查记录的类型、主机记录、记录值和备注
+func assertRecords(t *testing.T, got []service.DomainRecord, want ...service.DomainRecord) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("期望 %d 条记录，得到 %d 条: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.DomainName != w.DomainName || g.RR != w.RR || g.Type != w.Type || g.Value != w.Value || g.Remark != w.Remark {
+			t.Errorf("第 %d 条记录 = %+v，期望 %+v", i, g, w)
+		}
+	}
+}
+
+func TestReconcileCreatesRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		obj  runtime.Object
+		want service.DomainRecord
+	}{
+		{
+			name: "Ingress",
+			key:  "Ingress/default/web",
+			obj:  newIngress("web", "app.example.com", "203.0.113.10"),
+			want: service.DomainRecord{DomainName: "example.com", RR: "app", Type: "A", Value: "203.0.113.10"},
+		},
+		{
+			name: "Service",
+			key:  "Service/default/api",
+			obj:  newLoadBalancer("api", "api.example.com", "2001:db8::1"),
+			want: service.DomainRecord{DomainName: "example.com", RR: "api", Type: "AAAA", Value: "2001:db8::1"},
+		},
+		{
+			name: "Gateway",
+			key:  "Gateway/default/edge",
+			obj:  newGateway("edge", "example.com", "lb-123.elb.example.net"),
+			want: service.DomainRecord{DomainName: "example.com", RR: "@", Type: "CNAME", Value: "lb-123.elb.example.net"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := newFakeRecords("example.com")
+			env := newTestEnv(t, records, tt.obj)
+
+			if err := env.reconcile(tt.key, nil); err != nil {
+				t.Fatalf("同步失败: %v", err)
+			}
+			tt.want.Remark = remarkPrefix + tt.key
+			assertRecords(t, records.list(), tt.want)
+
+			// 超时说明资源没有添加 finalizer
+			env.wait(tt.key, withFinalizer)
+		})
+	}
+}
+
+func TestReconcileUpdatesRecordWhenAddressChanges(t *testing.T) {
+	const key = "Service/default/api"
+	records := newFakeRecords("example.com")
+	env := newTestEnv(t, records, newLoadBalancer("api", "api.example.com", "203.0.113.10"))
+
+	if err := env.reconcile(key, nil); err != nil {
+		t.Fatalf("同步失败: %v", err)
+	}
+
+	svc, err := env.kube.CoreV1().Services("default").Get(env.ctx, "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.20"}}
+	if _, err := env.kube.CoreV1().Services("default").UpdateStatus(env.ctx, svc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := env.reconcile(key, withIP("203.0.113.20")); err != nil {
+		t.Fatalf("同步失败: %v", err)
+	}
+	assertRecords(t, records.list(), service.DomainRecord{
+		DomainName: "example.com", RR: "api", Type: "A", Value: "203.0.113.20", Remark: remarkPrefix + key,
+	})
+}
+
+func TestReconcileDeletionRemovesRecordAndFinalizer(t *testing.T) {
+	const key = "Ingress/default/web"
+	records := newFakeRecords("example.com")
+	env := newTestEnv(t, records, newIngress("web", "app.example.com", "203.0.113.10"))
+
+	if err := env.reconcile(key, nil); err != nil {
+		t.Fatalf("同步失败: %v", err)
+	}
+	if len(records.list()) != 1 {
+		t.Fatalf("期望创建 1 条记录，得到 %+v", records.list())
+	}
+
+	// 有 finalizer 的资源删除时只设置 deletionTimestamp
+	ing, err := env.kube.NetworkingV1().Ingresses("default").Get(env.ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := metav1.Now()
+	ing.DeletionTimestamp = &now
+	if _, err := env.kube.NetworkingV1().Ingresses("default").Update(env.ctx, ing, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := env.reconcile(key, deleting); err != nil {
+		t.Fatalf("同步失败: %v", err)
+	}
+	assertRecords(t, records.list())
+
+	ing, err = env.kube.NetworkingV1().Ingresses("default").Get(env.ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(ing.Finalizers, Finalizer) {
+		t.Errorf("删除后仍保留 %s: %v", Finalizer, ing.Finalizers)
+	}
+	if _, ok := ing.Annotations[AnnotationManaged]; ok {
+		t.Errorf("删除后仍保留 %s 注解", AnnotationManaged)
+	}
+}
+
+func TestReconcileNeverTouchesForeignRecords(t *testing.T) {
+	const key = "Ingress/default/web"
+	foreign := []service.DomainRecord{
+		{RecordId: "manual", DomainName: "example.com", RR: "app", Type: "A", Value: "198.51.100.1", Remark: "手工添加"},
+		{RecordId: "other", DomainName: "example.com", RR: "old", Type: "A", Value: "198.51.100.2", Remark: remarkPrefix + "Ingress/default/other"},
+		{RecordId: "prefix", DomainName: "example.com", RR: "old", Type: "AAAA", Value: "2001:db8::2", Remark: remarkPrefix + key + "-canary"},
+	}
+
+	records := newFakeRecords("example.com")
+	for _, r := range foreign {
+		records.seed(r)
+	}
+	ing := newIngress("web", "app.example.com", "203.0.113.10")
+	// 之前管理过 old.example.com，同步时需要清理
+	ing.Annotations[AnnotationManaged] = "old.example.com"
+	env := newTestEnv(t, records, ing)
+
+	// 同名下已有其他来源的记录时报告冲突，不覆盖
+	err := env.reconcile(key, nil)
+	if !apperror.HasCode(err, apperror.CodeConflict) {
+		t.Fatalf("期望冲突错误，得到 %v", err)
+	}
+	assertRecords(t, records.list(), foreign...)
+
+	// 删除时只清理属于该资源的记录
+	obj, err := env.kube.NetworkingV1().Ingresses("default").Get(env.ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := metav1.Now()
+	obj.DeletionTimestamp = &now
+	if _, err := env.kube.NetworkingV1().Ingresses("default").Update(env.ctx, obj, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.reconcile(key, func(r *resource) bool { return deleting(r) && hasFinalizer(r.meta) }); err != nil {
+		t.Fatalf("同步失败: %v", err)
+	}
+	assertRecords(t, records.list(), foreign...)
+}
@@ -0,0 +1,163 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultHost 默认的 Docker Engine 地址
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// Container 容器列表中的容器
+type Container struct {
+	Id     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Labels map[string]string `json:"Labels"`
+	State  string            `json:"State"`
+}
+
+// Name 返回容器名称（去掉开头的 /）
+func (c *Container) Name() string {
+	if len(c.Names) == 0 {
+		return c.Id
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// Event Docker Engine 的事件
+type Event struct {
+	Type   string `json:"Type"`
+	Action string `json:"Action"`
+	Actor  struct {
+		Id         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"` // 容器事件包含容器的标签和名称
+	} `json:"Actor"`
+	TimeNano int64 `json:"timeNano"`
+}
+
+// Client Docker Engine API 的最小客户端，只实现监听容器需要的接口
+type Client struct {
+	http *http.Client
+	base string
+}
+
+// NewClient 创建 Docker Engine 客户端，host 支持 unix:// 和 tcp:// 地址
+func NewClient(host string) (*Client, error) {
+	if host == "" {
+		host = DefaultHost
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("Docker地址格式错误: %w", err)
+	}
+
+	transport := &http.Transport{}
+	base := ""
+	switch u.Scheme {
+	case "unix":
+		path := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}
+		base = "http://docker"
+	case "tcp", "http":
+		base = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("不支持的Docker地址: %s", host)
+	}
+
+	return &Client{
+		http: &http.Client{Transport: transport},
+		base: base,
+	}, nil
+}
+
+// ListContainers 列出带有指定标签的运行中的容器
+func (c *Client) ListContainers(ctx context.Context, label string) ([]Container, error) {
+	filters, err := json.Marshal(map[string][]string{"label": {label}})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.get(ctx, "/containers/json", url.Values{"filters": {string(filters)}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var containers []Container
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("解析容器列表失败: %w", err)
+	}
+	return containers, nil
+}
+
+// Events 监听带有指定标签的容器事件，直到 ctx 被取消或连接断开
+//
+// since 不为零时从该时间开始回放事件，避免重连期间丢失事件
+func (c *Client) Events(ctx context.Context, label string, since time.Time, fn func(*Event)) error {
+	filters, err := json.Marshal(map[string][]string{
+		"type":  {"container"},
+		"label": {label},
+		"event": {"start", "die", "destroy"},
+	})
+	if err != nil {
+		return err
+	}
+	query := url.Values{"filters": {string(filters)}}
+	if !since.IsZero() {
+		query.Set("since", fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()))
+	}
+
+	resp, err := c.get(ctx, "/events", query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(resp.Body))
+	for {
+		var event Event
+		if err := decoder.Decode(&event); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err == io.EOF {
+				return fmt.Errorf("Docker事件流已关闭")
+			}
+			return fmt.Errorf("读取Docker事件失败: %w", err)
+		}
+		fn(&event)
+	}
+}
+
+// get 发送 GET 请求，非 2xx 响应返回错误
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求Docker失败: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var body struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return nil, fmt.Errorf("请求Docker失败: %s %s", resp.Status, body.Message)
+	}
+	return resp, nil
+}
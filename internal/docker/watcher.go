@@ -0,0 +1,467 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+	"dns-update/internal/validation"
+	"dns-update/pkg/idn"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// 容器标签
+const (
+	// LabelHost 需要解析到本机的主机名，多个主机名用逗号分隔
+	LabelHost = "dns-update.host"
+	// LabelTTL 解析记录的TTL
+	LabelTTL = "dns-update.ttl"
+)
+
+// remarkPrefix 监听器创建的解析记录的备注前缀，后接主机标识，用于识别记录的归属
+const remarkPrefix = "docker:"
+
+// maxBackoff 事件流断开后重连的最长等待时间
+const maxBackoff = time.Minute
+
+// RecordService 监听器依赖的解析记录服务
+type RecordService interface {
+	ListZones(refresh bool) ([]string, error)
+	ResolveZone(fqdn string) (string, string, error)
+	QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error)
+	AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error)
+	UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error
+	SetDomainRecordRemark(recordId, remark string) error
+	DeleteDomainRecord(recordId string) error
+}
+
+// Options 监听器的选项
+type Options struct {
+	HostIP string        // 解析记录指向的本机IP，为空时自动检测
+	Owner  string        // 主机标识，写入记录备注，为空时使用主机名
+	TTL    int64         // 未设置 dns-update.ttl 标签时的TTL，0 表示使用阿里云的默认值
+	Resync time.Duration // 定期全量同步的间隔
+	// Protects 判断主机记录是否受变更审批保护，受保护的主机名不发布也不删除
+	Protects func(domain, rr string) bool
+}
+
+// DefaultOptions 默认的监听器选项
+var DefaultOptions = Options{
+	Resync: 10 * time.Minute,
+}
+
+// container 带有主机名标签的运行中容器
+type container struct {
+	name      string
+	hostnames []string
+	ttl       int64
+}
+
+// Watcher 监听 Docker 容器事件，将带有 dns-update.host 标签的容器主机名解析到本机IP
+//
+// 同一主机名可以由多个容器声明，最后一个容器停止后才删除记录
+type Watcher struct {
+	client  *Client
+	records RecordService
+	opts    Options
+	owner   string
+	log     *zap.Logger
+
+	mu         sync.Mutex
+	containers map[string]*container // 按容器ID索引
+}
+
+// NewWatcher 创建容器监听器
+func NewWatcher(client *Client, records RecordService, opts *Options) (*Watcher, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	o := *opts
+	if o.Resync <= 0 {
+		o.Resync = DefaultOptions.Resync
+	}
+
+	if o.HostIP == "" {
+		ip, err := detectHostIP()
+		if err != nil {
+			return nil, fmt.Errorf("检测本机IP失败: %w", err)
+		}
+		o.HostIP = ip
+	}
+	if net.ParseIP(o.HostIP) == nil {
+		return nil, fmt.Errorf("本机IP格式错误: %s", o.HostIP)
+	}
+	if o.Owner == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("获取主机名失败: %w", err)
+		}
+		o.Owner = hostname
+	}
+
+	return &Watcher{
+		client:     client,
+		records:    records,
+		opts:       o,
+		owner:      remarkPrefix + o.Owner,
+		log:        logger.GetLogger(),
+		containers: make(map[string]*container),
+	}, nil
+}
+
+// Run 启动监听，直到 ctx 被取消
+//
+// 启动和每次重连时先全量同步，再从同步前的时间点开始监听事件，避免遗漏
+func (w *Watcher) Run(ctx context.Context) {
+	w.log.Info("Docker 容器监听器启动",
+		zap.String("host_ip", w.opts.HostIP),
+		zap.String("owner", w.owner),
+	)
+
+	go w.resync(ctx)
+
+	backoff := time.Second
+	for {
+		since := time.Now()
+		err := w.Reconcile(ctx)
+		if err == nil {
+			backoff = time.Second
+			err = w.client.Events(ctx, LabelHost, since, func(event *Event) {
+				w.handle(event)
+			})
+		}
+		if ctx.Err() != nil {
+			w.log.Info("Docker 容器监听器已停止")
+			return
+		}
+
+		w.log.Error("监听Docker事件失败，稍后重试", zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// resync 定期全量同步，修复事件处理失败留下的差异
+func (w *Watcher) resync(ctx context.Context) {
+	ticker := time.NewTicker(w.opts.Resync)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Reconcile(ctx); err != nil {
+				w.log.Error("同步容器解析记录失败", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reconcile 全量同步：为运行中容器的主机名创建记录，删除本机创建的其他记录
+func (w *Watcher) Reconcile(ctx context.Context) error {
+	list, err := w.client.ListContainers(ctx, LabelHost)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.containers = make(map[string]*container)
+	for _, c := range list {
+		if c.State != "" && c.State != "running" {
+			continue
+		}
+		w.containers[c.Id] = w.parseContainer(c.Name(), c.Labels)
+	}
+	desired := w.desired()
+
+	// 删除本机创建但不再需要的记录
+	var errs []error
+	domains, err := w.records.ListZones(true)
+	if err != nil {
+		return err
+	}
+	for _, domain := range domains {
+		records, err := w.records.QueryDomainRecords(&service.RecordQuery{DomainName: domain})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, r := range records {
+			if r.Remark != w.owner {
+				continue
+			}
+			if _, ok := desired[fqdn(r.RR, domain)]; ok && r.Value == w.opts.HostIP {
+				continue
+			}
+			if err := w.checkProtected(domain, r.RR, fqdn(r.RR, domain)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if err := w.deleteRecord(&r, fqdn(r.RR, domain)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for hostname, ttl := range desired {
+		if err := w.publish(hostname, ttl); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hostname, err))
+		}
+	}
+
+	w.log.Info("容器解析记录同步完成",
+		zap.Int("containers", len(w.containers)),
+		zap.Int("hostnames", len(desired)),
+	)
+	return errors.Join(errs...)
+}
+
+// handle 处理容器事件，失败时记录日志，由下次全量同步修复
+func (w *Watcher) handle(event *Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := event.Actor.Id
+	switch event.Action {
+	case "start":
+		c := w.parseContainer(event.Actor.Attributes["name"], event.Actor.Attributes)
+		w.containers[id] = c
+		for _, hostname := range c.hostnames {
+			if err := w.publish(hostname, w.desired()[hostname]); err != nil {
+				w.log.Error("发布容器主机名失败",
+					zap.String("container", c.name),
+					zap.String("hostname", hostname),
+					zap.Error(err),
+				)
+			}
+		}
+
+	case "die", "destroy":
+		c, ok := w.containers[id]
+		if !ok {
+			return
+		}
+		delete(w.containers, id)
+
+		desired := w.desired()
+		for _, hostname := range c.hostnames {
+			if _, ok := desired[hostname]; ok {
+				continue
+			}
+			if err := w.unpublish(hostname); err != nil {
+				w.log.Error("删除容器主机名失败",
+					zap.String("container", c.name),
+					zap.String("hostname", hostname),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// parseContainer 解析容器的标签
+func (w *Watcher) parseContainer(name string, labels map[string]string) *container {
+	c := &container{name: name, ttl: w.opts.TTL}
+	for _, hostname := range strings.Split(labels[LabelHost], ",") {
+		hostname = strings.TrimSuffix(strings.TrimSpace(hostname), ".")
+		if hostname == "" {
+			continue
+		}
+		hostname, err := idn.ToASCII(hostname)
+		if err != nil {
+			w.log.Warn("容器的主机名标签无效",
+				zap.String("container", name),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !slices.Contains(c.hostnames, hostname) {
+			c.hostnames = append(c.hostnames, hostname)
+		}
+	}
+
+	if value, ok := labels[LabelTTL]; ok {
+		ttl, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || validation.ValidateTTL(ttl) != nil {
+			w.log.Warn("容器的TTL标签无效，使用默认值",
+				zap.String("container", name),
+				zap.String("ttl", value),
+			)
+		} else {
+			c.ttl = ttl
+		}
+	}
+	return c
+}
+
+// desired 返回运行中容器声明的主机名及其TTL，多个容器声明同一主机名时取最小的TTL
+func (w *Watcher) desired() map[string]int64 {
+	desired := make(map[string]int64)
+	for _, c := range w.containers {
+		for _, hostname := range c.hostnames {
+			if ttl, ok := desired[hostname]; !ok || (c.ttl != 0 && (ttl == 0 || c.ttl < ttl)) {
+				desired[hostname] = c.ttl
+			}
+		}
+	}
+	return desired
+}
+
+// publish 将主机名解析到本机IP
+//
+// 只修改本机创建的记录；同名下存在其他来源的地址记录时报告冲突而不覆盖
+func (w *Watcher) publish(hostname string, ttl int64) error {
+	zone, rr, err := w.records.ResolveZone(hostname)
+	if err != nil {
+		return err
+	}
+	if err := w.checkProtected(zone, rr, hostname); err != nil {
+		return err
+	}
+	existing, err := w.addressRecords(zone, rr)
+	if err != nil {
+		return err
+	}
+
+	recordType := validation.TypeA
+	if net.ParseIP(w.opts.HostIP).To4() == nil {
+		recordType = validation.TypeAAAA
+	}
+	input := &service.DomainRecordInput{RR: rr, Type: recordType, Value: w.opts.HostIP, TTL: ttl}
+
+	var current *service.DomainRecord
+	for i, r := range existing {
+		switch {
+		case r.Remark != w.owner:
+			return apperror.Conflict(fmt.Sprintf("已存在不属于本机的%s记录(%s)", r.Type, r.Value))
+		case current == nil && r.Type == recordType && r.Value == w.opts.HostIP:
+			current = &existing[i]
+		default:
+			if err := w.deleteRecord(&r, hostname); err != nil {
+				return err
+			}
+		}
+	}
+
+	if current != nil {
+		if ttl != 0 && current.TTL != ttl {
+			return w.records.UpdateDomainRecord(current.RecordId, input)
+		}
+		return nil
+	}
+
+	recordId, err := w.records.AddDomainRecord(zone, input)
+	if err != nil {
+		return err
+	}
+	if err := w.records.SetDomainRecordRemark(recordId, w.owner); err != nil {
+		// 没有备注的记录无法识别归属，删除后由下次同步重试
+		_ = w.records.DeleteDomainRecord(recordId)
+		return err
+	}
+	w.log.Info("已发布容器主机名",
+		zap.String("hostname", hostname),
+		zap.String("type", recordType),
+		zap.String("value", w.opts.HostIP),
+	)
+	return nil
+}
+
+// unpublish 删除本机为主机名创建的记录
+func (w *Watcher) unpublish(hostname string) error {
+	zone, rr, err := w.records.ResolveZone(hostname)
+	if apperror.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	existing, err := w.addressRecords(zone, rr)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range existing {
+		if r.Remark != w.owner {
+			continue
+		}
+		if err := w.checkProtected(zone, rr, hostname); err != nil {
+			return err
+		}
+		if err := w.deleteRecord(&r, hostname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkProtected 受变更审批保护的主机名拒绝自动写入
+func (w *Watcher) checkProtected(zone, rr, hostname string) error {
+	if w.opts.Protects != nil && w.opts.Protects(zone, rr) {
+		return apperror.Forbidden(hostname + " 受变更审批保护，请通过审批流程修改")
+	}
+	return nil
+}
+
+// deleteRecord 删除记录，记录已不存在时视为成功
+func (w *Watcher) deleteRecord(r *service.DomainRecord, hostname string) error {
+	if err := w.records.DeleteDomainRecord(r.RecordId); err != nil && !apperror.IsNotFound(err) {
+		return err
+	}
+	w.log.Info("已删除容器主机名记录",
+		zap.String("hostname", hostname),
+		zap.String("type", r.Type),
+		zap.String("value", r.Value),
+	)
+	return nil
+}
+
+// addressRecords 查询主机名下的 A/AAAA/CNAME 记录
+func (w *Watcher) addressRecords(zone, rr string) ([]service.DomainRecord, error) {
+	records, err := w.records.QueryDomainRecords(&service.RecordQuery{
+		DomainName: zone,
+		RR:         rr,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(records, func(r service.DomainRecord) bool {
+		return r.Type != validation.TypeA && r.Type != validation.TypeAAAA && r.Type != validation.TypeCNAME
+	}), nil
+}
+
+// fqdn 由主机记录和域名组成完整名称
+func fqdn(rr, zone string) string {
+	if rr == "@" || rr == "" {
+		return zone
+	}
+	return strings.ToLower(rr) + "." + zone
+}
+
+// detectHostIP 通过默认路由检测本机的出口IP，不会发送数据
+func detectHostIP() (string, error) {
+	conn, err := net.Dial("udp", "223.5.5.5:53")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
@@ -0,0 +1,364 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+)
+
+const (
+	testHostIP = "192.0.2.10"
+	testOwner  = remarkPrefix + "node-1"
+)
+
+// fakeRecords 内存中的解析记录服务
+type fakeRecords struct {
+	mu      sync.Mutex
+	zones   []string
+	records map[string]*service.DomainRecord
+	nextId  int
+}
+
+func newFakeRecords(zones ...string) *fakeRecords {
+	return &fakeRecords{zones: zones, records: make(map[string]*service.DomainRecord)}
+}
+
+// seed 添加一条已存在的记录
+func (f *fakeRecords) seed(r service.DomainRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[r.RecordId] = &r
+}
+
+// list 按主机记录、类型、记录值排序返回所有记录
+func (f *fakeRecords) list() []service.DomainRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var records []service.DomainRecord
+	for _, r := range f.records {
+		records = append(records, *r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		return a.DomainName+a.RR+a.Type+a.Value < b.DomainName+b.RR+b.Type+b.Value
+	})
+	return records
+}
+
+// has 判断是否存在主机记录为 rr 且属于本机的记录
+func (f *fakeRecords) has(rr string) bool {
+	for _, r := range f.list() {
+		if r.RR == rr && r.Remark == testOwner {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeRecords) ListZones(bool) ([]string, error) {
+	return f.zones, nil
+}
+
+func (f *fakeRecords) ResolveZone(fqdn string) (string, string, error) {
+	best := ""
+	for _, zone := range f.zones {
+		if (fqdn == zone || strings.HasSuffix(fqdn, "."+zone)) && len(zone) > len(best) {
+			best = zone
+		}
+	}
+	switch best {
+	case "":
+		return "", "", apperror.NotFound("账户下没有托管 " + fqdn + " 的域名")
+	case fqdn:
+		return best, "@", nil
+	}
+	return best, strings.TrimSuffix(fqdn, "."+best), nil
+}
+
+func (f *fakeRecords) QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error) {
+	var records []service.DomainRecord
+	for _, r := range f.list() {
+		if r.DomainName == query.DomainName && (query.RR == "" || r.RR == query.RR) {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+func (f *fakeRecords) AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextId++
+	id := fmt.Sprintf("new-%d", f.nextId)
+	f.records[id] = &service.DomainRecord{
+		RecordId:   id,
+		DomainName: domainName,
+		RR:         input.RR,
+		Type:       input.Type,
+		Value:      input.Value,
+		TTL:        input.TTL,
+	}
+	return id, nil
+}
+
+func (f *fakeRecords) UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.records[recordId]
+	if !ok {
+		return apperror.NotFound("记录不存在")
+	}
+	r.RR, r.Type, r.Value, r.TTL = input.RR, input.Type, input.Value, input.TTL
+	return nil
+}
+
+func (f *fakeRecords) SetDomainRecordRemark(recordId, remark string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.records[recordId]
+	if !ok {
+		return apperror.NotFound("记录不存在")
+	}
+	r.Remark = remark
+	return nil
+}
+
+func (f *fakeRecords) DeleteDomainRecord(recordId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.records[recordId]; !ok {
+		return apperror.NotFound("记录不存在")
+	}
+	delete(f.records, recordId)
+	return nil
+}
+
+// fakeEngine 通过 unix socket 提供容器列表和事件流的 Docker Engine
+type fakeEngine struct {
+	mu         sync.Mutex
+	containers []Container
+	events     chan Event
+	host       string
+}
+
+// newFakeEngine 在临时目录的 unix socket 上启动 Docker Engine
+func newFakeEngine(t *testing.T, containers ...Container) *fakeEngine {
+	t.Helper()
+
+	// unix socket 路径长度有限，不使用 t.TempDir 的长路径
+	dir, err := os.MkdirTemp("", "docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := &fakeEngine{containers: containers, events: make(chan Event, 16), host: "unix://" + socket}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", e.listContainers)
+	mux.HandleFunc("/events", e.streamEvents)
+
+	server := httptest.NewUnstartedServer(mux)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return e
+}
+
+func (e *fakeEngine) listContainers(w http.ResponseWriter, r *http.Request) {
+	var filters map[string][]string
+	if err := json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters); err != nil || len(filters["label"]) == 0 {
+		http.Error(w, `{"message":"缺少标签过滤条件"}`, http.StatusBadRequest)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(e.containers)
+}
+
+func (e *fakeEngine) streamEvents(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-e.events:
+			_ = encoder.Encode(event)
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+// send 发送容器事件，start 事件带有容器的名称和标签
+func (e *fakeEngine) send(action string, c Container) {
+	event := Event{Type: "container", Action: action}
+	event.Actor.Id = c.Id
+	event.Actor.Attributes = map[string]string{"name": c.Name()}
+	for k, v := range c.Labels {
+		event.Actor.Attributes[k] = v
+	}
+	e.events <- event
+}
+
+// newTestWatcher 创建连接到 fake Docker Engine 的监听器
+func newTestWatcher(t *testing.T, engine *fakeEngine, records *fakeRecords) *Watcher {
+	t.Helper()
+	client, err := NewClient(engine.host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(client, records, &Options{HostIP: testHostIP, Owner: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// waitFor 等待条件满足
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newContainer(id, hostname string) Container {
+	return Container{
+		Id:     id,
+		Names:  []string{"/" + id},
+		Labels: map[string]string{LabelHost: hostname},
+		State:  "running",
+	}
+}
+
+func TestReconcileOnlyTouchesOwnRecords(t *testing.T) {
+	exited := newContainer("old", "old.example.com")
+	exited.State = "exited"
+	engine := newFakeEngine(t,
+		newContainer("web", "web.example.com"),
+		newContainer("api", "api.example.com"),
+		newContainer("taken", "taken.example.com"),
+		exited,
+	)
+
+	records := newFakeRecords("example.com")
+	kept := []service.DomainRecord{
+		// 本机创建且仍然需要的记录
+		{RecordId: "api", DomainName: "example.com", RR: "api", Type: "A", Value: testHostIP, Remark: testOwner},
+		// 其他主机、手工添加的记录
+		{RecordId: "node-2", DomainName: "example.com", RR: "old", Type: "A", Value: "192.0.2.20", Remark: remarkPrefix + "node-2"},
+		{RecordId: "manual", DomainName: "example.com", RR: "taken", Type: "A", Value: "198.51.100.1", Remark: "手工添加"},
+		{RecordId: "prefix", DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.30", Remark: testOwner + "-2"},
+	}
+	for _, r := range kept {
+		records.seed(r)
+	}
+	// 本机创建但容器已停止的记录
+	records.seed(service.DomainRecord{RecordId: "stale", DomainName: "example.com", RR: "old", Type: "A", Value: testHostIP, Remark: testOwner})
+	// 本机创建但IP已变化的记录
+	records.seed(service.DomainRecord{RecordId: "moved", DomainName: "example.com", RR: "web", Type: "A", Value: "192.0.2.99", Remark: testOwner})
+
+	w := newTestWatcher(t, engine, records)
+	err := w.Reconcile(context.Background())
+	if !apperror.HasCode(err, apperror.CodeConflict) {
+		t.Fatalf("期望 taken.example.com 冲突，得到 %v", err)
+	}
+
+	got := make(map[string]service.DomainRecord)
+	for _, r := range records.list() {
+		got[r.RecordId] = r
+	}
+	for _, r := range kept {
+		if got[r.RecordId] != r {
+			t.Errorf("记录 %s 被修改: %+v", r.RecordId, got[r.RecordId])
+		}
+		delete(got, r.RecordId)
+	}
+	if len(got) != 1 {
+		t.Fatalf("期望只新建 web 的记录，得到 %+v", got)
+	}
+	for _, r := range got {
+		if r.RR != "web" || r.Type != "A" || r.Value != testHostIP || r.Remark != testOwner {
+			t.Errorf("新建的记录 = %+v", r)
+		}
+	}
+}
+
+func TestSharedHostnameSurvivesUntilLastContainerDies(t *testing.T) {
+	first := newContainer("first", "shared.example.com")
+	second := newContainer("second", "shared.example.com")
+	engine := newFakeEngine(t, first, second)
+	records := newFakeRecords("example.com")
+	w := newTestWatcher(t, engine, records)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitFor(t, "发布 shared.example.com", func() bool { return records.has("shared") })
+
+	// 事件按顺序处理，标记容器的记录出现说明之前的事件已经处理完
+	engine.send("die", first)
+	marker := newContainer("marker", "marker.example.com")
+	engine.send("start", marker)
+	waitFor(t, "处理 die 事件", func() bool { return records.has("marker") })
+	if !records.has("shared") {
+		t.Fatal("仍有容器声明 shared.example.com 时记录被删除")
+	}
+
+	engine.send("die", second)
+	waitFor(t, "删除 shared.example.com", func() bool { return !records.has("shared") })
+	if !records.has("marker") {
+		t.Error("marker.example.com 的记录被删除")
+	}
+}
+
+func TestProtectedHostnameIsNotPublished(t *testing.T) {
+	engine := newFakeEngine(t, newContainer("web", "www.example.com"), newContainer("api", "api.example.com"))
+	records := newFakeRecords("example.com")
+	w := newTestWatcher(t, engine, records)
+	w.opts.Protects = func(domain, rr string) bool { return domain == "example.com" && rr == "www" }
+
+	err := w.Reconcile(context.Background())
+	if !apperror.HasCode(err, apperror.CodeForbidden) {
+		t.Fatalf("期望 www.example.com 受保护，得到 %v", err)
+	}
+	if records.has("www") {
+		t.Error("受保护的主机名被发布")
+	}
+	if !records.has("api") {
+		t.Error("未受保护的主机名没有发布")
+	}
+}
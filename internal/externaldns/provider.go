@@ -0,0 +1,404 @@
+package externaldns
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+	"dns-update/internal/validation"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// defaultLine 默认解析线路，只有默认线路的记录由 external-dns 管理
+const defaultLine = "default"
+
+// supportedTypes 支持转换的记录类型
+var supportedTypes = map[string]bool{
+	validation.TypeA:     true,
+	validation.TypeAAAA:  true,
+	validation.TypeCNAME: true,
+	validation.TypeNS:    true,
+	validation.TypeMX:    true,
+	validation.TypeTXT:   true,
+	validation.TypeSRV:   true,
+	validation.TypeCAA:   true,
+}
+
+// RecordService webhook 依赖的解析记录服务
+type RecordService interface {
+	ListDomains() ([]service.Domain, error)
+	QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error)
+	AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error)
+	UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error
+	DeleteDomainRecord(recordId string) error
+}
+
+// Options webhook 的选项
+type Options struct {
+	Filter DomainFilter
+	MinTTL int64 // 低于该值的TTL在 adjustendpoints 时被提高，0 表示不调整
+	// Protects 判断主机记录是否受变更审批保护，受保护的记录只能通过审批修改
+	Protects func(domain, rr string) bool
+}
+
+// Provider 将 external-dns 的端点转换为阿里云解析记录
+type Provider struct {
+	records RecordService
+	opts    Options
+	log     *zap.Logger
+}
+
+// NewProvider 创建 external-dns webhook 提供者
+func NewProvider(records RecordService, opts *Options) *Provider {
+	return &Provider{
+		records: records,
+		opts:    *opts,
+		log:     logger.GetLogger(),
+	}
+}
+
+// DomainFilter 返回协商时告知 external-dns 的域名过滤条件
+func (p *Provider) DomainFilter() DomainFilter {
+	return p.opts.Filter
+}
+
+// Records 返回过滤范围内的所有端点，同一名称和类型的多条记录合并为一个端点的多个目标
+func (p *Provider) Records() ([]*Endpoint, error) {
+	zones, err := p.zones()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*Endpoint, 0)
+	for _, zone := range zones {
+		records, err := p.records.QueryDomainRecords(&service.RecordQuery{DomainName: zone})
+		if err != nil {
+			return nil, err
+		}
+
+		index := make(map[string]*Endpoint)
+		for _, r := range records {
+			if !managed(&r) {
+				continue
+			}
+			name := fqdn(r.RR, zone)
+			if !p.opts.Filter.Match(name) {
+				continue
+			}
+
+			key := name + "|" + r.Type
+			ep, ok := index[key]
+			if !ok {
+				ep = &Endpoint{
+					DNSName:    name,
+					RecordType: r.Type,
+					RecordTTL:  r.TTL,
+				}
+				index[key] = ep
+				endpoints = append(endpoints, ep)
+			}
+			ep.Targets = append(ep.Targets, recordTarget(&r))
+		}
+	}
+
+	slices.SortFunc(endpoints, func(a, b *Endpoint) int {
+		return cmp.Or(cmp.Compare(a.DNSName, b.DNSName), cmp.Compare(a.RecordType, b.RecordType))
+	})
+	for _, ep := range endpoints {
+		slices.Sort(ep.Targets)
+	}
+
+	p.log.Info("external-dns 获取记录成功", zap.Int("endpoints", len(endpoints)))
+	return endpoints, nil
+}
+
+// AdjustEndpoints 将端点规范化为与 Records 返回值一致的形式，避免 external-dns 反复计划无效变更
+func (p *Provider) AdjustEndpoints(endpoints []*Endpoint) []*Endpoint {
+	for _, ep := range endpoints {
+		ep.DNSName = normalizeName(ep.DNSName)
+		for i, target := range ep.Targets {
+			ep.Targets[i] = canonicalTarget(ep.RecordType, target)
+		}
+		if ep.RecordTTL > 0 && ep.RecordTTL < p.opts.MinTTL {
+			ep.RecordTTL = p.opts.MinTTL
+		}
+	}
+	return endpoints
+}
+
+// ApplyChanges 按删除、更新、创建的顺序执行变更
+func (p *Provider) ApplyChanges(changes *Changes) error {
+	zones, err := p.zones()
+	if err != nil {
+		return err
+	}
+
+	p.log.Info("external-dns 开始执行变更",
+		zap.Int("create", len(changes.Create)),
+		zap.Int("update", len(changes.UpdateNew)),
+		zap.Int("delete", len(changes.Delete)),
+	)
+
+	for _, ep := range changes.Delete {
+		if err := p.apply(zones, ep, nil); err != nil {
+			return err
+		}
+	}
+
+	// UpdateOld 与 UpdateNew 的顺序不保证一致，按名称和类型配对，找不到旧端点时按创建处理
+	olds := make(map[string]*Endpoint, len(changes.UpdateOld))
+	for _, ep := range changes.UpdateOld {
+		if ep != nil {
+			olds[endpointKey(ep)] = ep
+		}
+	}
+	for _, ep := range changes.UpdateNew {
+		if err := p.apply(zones, olds[endpointKey(ep)], ep); err != nil {
+			return err
+		}
+	}
+
+	for _, ep := range changes.Create {
+		if err := p.apply(zones, nil, ep); err != nil {
+			return err
+		}
+	}
+
+	p.log.Info("external-dns 变更执行完成")
+	return nil
+}
+
+// endpointKey 端点的名称和类型
+func endpointKey(ep *Endpoint) string {
+	if ep == nil {
+		return ""
+	}
+	return normalizeName(ep.DNSName) + "/" + strings.ToUpper(ep.RecordType)
+}
+
+// apply 将名称和类型下的记录从 old 的目标调整为 desired 的目标
+//
+// old 为空表示创建，desired 为空表示删除。只删除 old 中列出的目标，
+// 不在 old 中的已有记录不会被删除；目标已存在时只在TTL不同时更新
+func (p *Provider) apply(zones []string, old, desired *Endpoint) error {
+	ep := desired
+	if ep == nil {
+		ep = old
+	}
+	if ep == nil {
+		return nil
+	}
+	if !supportedTypes[ep.RecordType] {
+		return apperror.BadRequest(fmt.Sprintf("不支持的记录类型: %s", ep.RecordType))
+	}
+
+	name := normalizeName(ep.DNSName)
+	if !p.opts.Filter.Match(name) {
+		return apperror.Forbidden(fmt.Sprintf("%s 不在域名过滤范围内", name))
+	}
+	zone, rr, ok := splitName(zones, name)
+	if !ok {
+		return apperror.NotFound(fmt.Sprintf("账户下没有托管 %s 的域名", name))
+	}
+	if p.opts.Protects != nil && p.opts.Protects(zone, rr) {
+		return apperror.Forbidden(fmt.Sprintf("%s 受变更审批保护，请通过审批流程修改", name))
+	}
+
+	existing, err := p.records.QueryDomainRecords(&service.RecordQuery{
+		DomainName: zone,
+		RR:         rr,
+		Type:       ep.RecordType,
+	})
+	if err != nil {
+		return err
+	}
+	current := make(map[string]service.DomainRecord)
+	for _, r := range existing {
+		if managed(&r) {
+			current[canonicalTarget(r.Type, recordTarget(&r))] = r
+		}
+	}
+
+	wanted := make(map[string]bool)
+	if desired != nil {
+		for _, target := range desired.Targets {
+			wanted[canonicalTarget(ep.RecordType, target)] = true
+		}
+	}
+
+	// 删除不再需要的目标
+	if old != nil {
+		for _, target := range old.Targets {
+			target = canonicalTarget(ep.RecordType, target)
+			r, ok := current[target]
+			if !ok || wanted[target] {
+				continue
+			}
+			if err := p.records.DeleteDomainRecord(r.RecordId); err != nil && !apperror.IsNotFound(err) {
+				return err
+			}
+			p.log.Info("external-dns 删除记录",
+				zap.String("name", name),
+				zap.String("type", ep.RecordType),
+				zap.String("target", target),
+			)
+		}
+	}
+
+	if desired == nil {
+		return nil
+	}
+
+	// 创建缺少的目标，已有目标在TTL变化时更新
+	for _, target := range desired.Targets {
+		input, err := recordInput(rr, desired.RecordType, target, desired.RecordTTL)
+		if err != nil {
+			return err
+		}
+
+		r, ok := current[canonicalTarget(desired.RecordType, target)]
+		switch {
+		case !ok:
+			if _, err := p.records.AddDomainRecord(zone, input); err != nil && !apperror.HasCode(err, apperror.CodeConflict) {
+				return err
+			}
+			p.log.Info("external-dns 创建记录",
+				zap.String("name", name),
+				zap.String("type", desired.RecordType),
+				zap.String("target", target),
+			)
+		case desired.RecordTTL > 0 && r.TTL != desired.RecordTTL:
+			if err := p.records.UpdateDomainRecord(r.RecordId, input); err != nil {
+				return err
+			}
+			p.log.Info("external-dns 更新记录TTL",
+				zap.String("name", name),
+				zap.String("type", desired.RecordType),
+				zap.Int64("ttl", desired.RecordTTL),
+			)
+		}
+	}
+	return nil
+}
+
+// zones 返回过滤范围内的域名（ASCII 形式）
+func (p *Provider) zones() ([]string, error) {
+	domains, err := p.records.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]string, 0, len(domains))
+	for _, d := range domains {
+		zone := d.PunyCode
+		if zone == "" {
+			zone = d.DomainName
+		}
+		zone = normalizeName(zone)
+		if p.opts.Filter.MatchZone(zone) {
+			zones = append(zones, zone)
+		}
+	}
+	return zones, nil
+}
+
+// managed 判断记录是否由 external-dns 管理：只管理支持的类型和默认线路
+func managed(r *service.DomainRecord) bool {
+	return supportedTypes[r.Type] && (r.Line == "" || r.Line == defaultLine)
+}
+
+// splitName 找到托管名称的域名（取最长匹配），返回域名和主机记录
+func splitName(zones []string, name string) (string, string, bool) {
+	best := ""
+	for _, zone := range zones {
+		if (name == zone || strings.HasSuffix(name, "."+zone)) && len(zone) > len(best) {
+			best = zone
+		}
+	}
+	if best == "" {
+		return "", "", false
+	}
+	if name == best {
+		return best, "@", true
+	}
+	return best, strings.TrimSuffix(name, "."+best), true
+}
+
+// fqdn 由主机记录和域名组成完整名称
+func fqdn(rr, zone string) string {
+	if rr == "@" || rr == "" {
+		return zone
+	}
+	return strings.ToLower(rr) + "." + zone
+}
+
+// recordTarget 将解析记录转换为 external-dns 的目标格式
+func recordTarget(r *service.DomainRecord) string {
+	switch r.Type {
+	case validation.TypeMX:
+		return fmt.Sprintf("%d %s", r.Priority, strings.TrimSuffix(r.Value, "."))
+	case validation.TypeTXT:
+		if strings.HasPrefix(r.Value, `"`) {
+			return r.Value
+		}
+		return strconv.Quote(r.Value)
+	}
+	return canonicalTarget(r.Type, r.Value)
+}
+
+// canonicalTarget 将目标规范化，用于比较和 adjustendpoints
+func canonicalTarget(recordType, target string) string {
+	switch recordType {
+	case validation.TypeCNAME, validation.TypeNS:
+		return normalizeName(target)
+	case validation.TypeMX:
+		priority, host, ok := strings.Cut(strings.TrimSpace(target), " ")
+		if !ok {
+			return normalizeName(target)
+		}
+		return priority + " " + normalizeName(host)
+	case validation.TypeTXT:
+		if !strings.HasPrefix(target, `"`) {
+			return strconv.Quote(target)
+		}
+	}
+	return target
+}
+
+// recordInput 将 external-dns 的目标转换为解析记录参数
+func recordInput(rr, recordType, target string, ttl int64) (*service.DomainRecordInput, error) {
+	input := &service.DomainRecordInput{
+		RR:   rr,
+		Type: recordType,
+		TTL:  ttl,
+	}
+
+	switch recordType {
+	case validation.TypeMX:
+		priority, host, ok := strings.Cut(strings.TrimSpace(target), " ")
+		p, err := strconv.ParseInt(priority, 10, 64)
+		if !ok || err != nil {
+			return nil, apperror.BadRequest(fmt.Sprintf("MX目标格式错误，应为\"优先级 主机名\": %s", target))
+		}
+		input.Priority = p
+		input.Value = normalizeName(host)
+	case validation.TypeCNAME, validation.TypeNS:
+		input.Value = normalizeName(target)
+	case validation.TypeTXT:
+		// external-dns 的 TXT 目标带引号，单个分段时去掉引号后写入
+		if unquoted, err := strconv.Unquote(target); err == nil && !strings.Contains(unquoted, `"`) {
+			input.Value = unquoted
+		} else {
+			input.Value = target
+		}
+	default:
+		input.Value = target
+	}
+	return input, nil
+}
@@ -0,0 +1,89 @@
+package externaldns
+
+import "strings"
+
+// MediaType external-dns webhook 协议使用的内容类型
+const MediaType = "application/external.dns.webhook+json;version=1"
+
+// Endpoint external-dns 的端点，对应同一名称和类型下的一组记录
+type Endpoint struct {
+	DNSName          string                     `json:"dnsName,omitempty"`
+	Targets          []string                   `json:"targets,omitempty"`
+	RecordType       string                     `json:"recordType,omitempty"`
+	SetIdentifier    string                     `json:"setIdentifier,omitempty"`
+	RecordTTL        int64                      `json:"recordTTL,omitempty"`
+	Labels           map[string]string          `json:"labels,omitempty"`
+	ProviderSpecific []ProviderSpecificProperty `json:"providerSpecific,omitempty"`
+}
+
+// ProviderSpecificProperty 提供商相关的端点属性
+type ProviderSpecificProperty struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Changes external-dns 计划执行的变更
+type Changes struct {
+	Create    []*Endpoint `json:"Create,omitempty"`
+	UpdateOld []*Endpoint `json:"UpdateOld,omitempty"`
+	UpdateNew []*Endpoint `json:"UpdateNew,omitempty"`
+	Delete    []*Endpoint `json:"Delete,omitempty"`
+}
+
+// DomainFilter 域名过滤条件，协商时返回给 external-dns
+type DomainFilter struct {
+	Include []string `json:"include,omitempty"` // 只管理这些域名及其子域名，为空表示全部
+	Exclude []string `json:"exclude,omitempty"` // 排除这些域名及其子域名
+}
+
+// Match 判断名称是否在过滤范围内
+func (f *DomainFilter) Match(name string) bool {
+	for _, exclude := range f.Exclude {
+		if isSubdomain(name, exclude) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, include := range f.Include {
+		if isSubdomain(name, include) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchZone 判断域名下是否可能存在过滤范围内的名称
+func (f *DomainFilter) MatchZone(zone string) bool {
+	for _, exclude := range f.Exclude {
+		if isSubdomain(zone, exclude) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, include := range f.Include {
+		// 过滤条件可以是域名本身、其子域名或其上级域名
+		if isSubdomain(zone, include) || isSubdomain(include, zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubdomain 判断 name 是否等于 parent 或是其子域名
+func isSubdomain(name, parent string) bool {
+	name = normalizeName(name)
+	parent = normalizeName(parent)
+	if parent == "" {
+		return false
+	}
+	return name == parent || strings.HasSuffix(name, "."+parent)
+}
+
+// normalizeName 去掉末尾的点并转换为小写
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
@@ -0,0 +1,248 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// 健康检查的类型
+const (
+	CheckHTTP = "http"
+	CheckTCP  = "tcp"
+	CheckICMP = "icmp"
+)
+
+// Checker 探测目标是否健康，target 为解析记录的值（IP 或主机名）
+type Checker interface {
+	Check(ctx context.Context, target string) error
+}
+
+// CheckConfig 健康检查的配置
+type CheckConfig struct {
+	Type     string        // http、tcp 或 icmp
+	Port     int           // http 和 tcp 检查的端口，http 默认 80（https 为 443）
+	Scheme   string        // http 检查的协议，http 或 https
+	Path     string        // http 检查的路径，默认 /
+	Host     string        // http 检查的 Host 头和 TLS SNI，默认为解析记录的完整名称
+	Expect   []int         // http 检查期望的状态码，默认 200-399
+	Insecure bool          // https 检查时不校验证书
+	Timeout  time.Duration // 单次检查的超时时间
+}
+
+// NewChecker 根据配置创建健康检查
+func NewChecker(cfg *CheckConfig) (Checker, error) {
+	switch cfg.Type {
+	case CheckHTTP:
+		return newHTTPChecker(cfg), nil
+	case CheckTCP:
+		if cfg.Port <= 0 || cfg.Port > 65535 {
+			return nil, fmt.Errorf("TCP检查的端口无效: %d", cfg.Port)
+		}
+		return &tcpChecker{port: cfg.Port}, nil
+	case CheckICMP:
+		return &icmpChecker{}, nil
+	}
+	return nil, fmt.Errorf("不支持的健康检查类型: %s", cfg.Type)
+}
+
+// httpChecker 请求目标的 HTTP 接口，状态码符合预期时健康
+type httpChecker struct {
+	client *http.Client
+	scheme string
+	port   int
+	path   string
+	host   string
+	expect []int
+}
+
+func newHTTPChecker(cfg *CheckConfig) *httpChecker {
+	scheme := cfg.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 80
+		if scheme == "https" {
+			port = 443
+		}
+	}
+	path := cfg.Path
+	if path == "" {
+		path = "/"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	transport.TLSClientConfig = &tls.Config{
+		ServerName:         cfg.Host,
+		InsecureSkipVerify: cfg.Insecure,
+	}
+
+	return &httpChecker{
+		client: &http.Client{
+			Transport: transport,
+			// 不跟随重定向，3xx 按状态码判断
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		scheme: scheme,
+		port:   port,
+		path:   path,
+		host:   cfg.Host,
+		expect: cfg.Expect,
+	}
+}
+
+func (c *httpChecker) Check(ctx context.Context, target string) error {
+	url := fmt.Sprintf("%s://%s%s", c.scheme, net.JoinHostPort(target, strconv.Itoa(c.port)), c.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if c.host != "" {
+		req.Host = c.host
+	}
+	req.Header.Set("User-Agent", "dns-update-healthcheck")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if len(c.expect) == 0 {
+		if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+			return nil
+		}
+	} else {
+		for _, code := range c.expect {
+			if resp.StatusCode == code {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("状态码不符合预期: %d", resp.StatusCode)
+}
+
+// tcpChecker 能建立 TCP 连接时健康
+type tcpChecker struct {
+	port int
+}
+
+func (c *tcpChecker) Check(ctx context.Context, target string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(target, strconv.Itoa(c.port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// icmpChecker 收到 ICMP Echo 回复时健康
+//
+// 优先使用非特权的 ICMP 套接字（需要 net.ipv4.ping_group_range 允许），失败时使用原始套接字（需要 CAP_NET_RAW）
+type icmpChecker struct {
+	seq atomic.Uint32
+}
+
+func (c *icmpChecker) Check(ctx context.Context, target string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, target)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("无法解析 %s", target)
+	}
+	ip := addrs[0].IP
+
+	var (
+		networks [2]string
+		listen   string
+		proto    int
+		request  icmp.Type
+		reply    icmp.Type
+	)
+	if ip.To4() != nil {
+		networks, listen, proto, request, reply = [2]string{"udp4", "ip4:icmp"}, "0.0.0.0", 1, ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	} else {
+		networks, listen, proto, request, reply = [2]string{"udp6", "ip6:ipv6-icmp"}, "::", 58, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	var (
+		conn    *icmp.PacketConn
+		network string
+	)
+	for _, network = range networks {
+		if conn, err = icmp.ListenPacket(network, listen); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("创建ICMP套接字失败: %w", err)
+	}
+	defer conn.Close()
+
+	seq := int(c.seq.Add(1) & 0xffff)
+	msg := icmp.Message{
+		Type: request,
+		Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: seq, Data: []byte("dns-update")},
+	}
+	data, err := msg.Marshal(nil)
+	if err != nil {
+		return err
+	}
+
+	var dst net.Addr = &net.IPAddr{IP: ip}
+	if network == networks[0] {
+		dst = &net.UDPAddr{IP: ip}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.WriteTo(data, dst); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return fmt.Errorf("ICMP 请求超时")
+			}
+			return err
+		}
+		received, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || received.Type != reply {
+			continue
+		}
+		// 非特权套接字由内核改写 ID，只比较序号和来源
+		if echo, ok := received.Body.(*icmp.Echo); ok && echo.Seq == seq && sameHost(peer, ip) {
+			return nil
+		}
+	}
+}
+
+// sameHost 判断回复是否来自目标地址
+func sameHost(addr net.Addr, ip net.IP) bool {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP.Equal(ip)
+	case *net.IPAddr:
+		return a.IP.Equal(ip)
+	}
+	return false
+}
@@ -0,0 +1,533 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/notify"
+	"dns-update/internal/service"
+	"dns-update/internal/validation"
+	"dns-update/pkg/logger"
+	"dns-update/pkg/redact"
+
+	"go.uber.org/zap"
+)
+
+// 故障转移的模式
+const (
+	// ModeSwitch 只有一条解析记录，在主用和备用值之间切换
+	ModeSwitch = "switch"
+	// ModeMulti 每个值一条解析记录，暂停不健康的记录
+	ModeMulti = "multi"
+)
+
+// 目标的角色
+const (
+	RolePrimary = "primary"
+	RoleBackup  = "backup"
+)
+
+// defaultLine 默认解析线路，只管理默认线路的记录
+const defaultLine = "default"
+
+// RecordService 故障转移依赖的解析记录服务
+type RecordService interface {
+	QueryDomainRecords(query *service.RecordQuery) ([]service.DomainRecord, error)
+	AddDomainRecord(domainName string, input *service.DomainRecordInput) (string, error)
+	UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error
+	SetDomainRecordStatus(recordId, status string) error
+}
+
+// Group 故障转移组：一个解析记录名称的主用值、备用值和健康检查
+type Group struct {
+	Name     string
+	Domain   string
+	RR       string
+	Type     string
+	Mode     string // switch 或 multi，默认 switch
+	Primary  string
+	Backups  []string
+	TTL      int64         // 创建或切换记录时使用的TTL，0 表示保持原值
+	Check    CheckConfig   // 健康检查
+	Interval time.Duration // 检查间隔
+	Fall     int           // 连续失败多少次判定为不健康
+	Rise     int           // 连续成功多少次判定为恢复，大于 Fall 可以避免频繁回切
+}
+
+// 组的默认参数
+const (
+	DefaultInterval = 30 * time.Second
+	DefaultTimeout  = 5 * time.Second
+	DefaultFall     = 3
+	DefaultRise     = 5
+)
+
+// TargetStatus 目标的健康状态
+type TargetStatus struct {
+	Value      string    `json:"value"`
+	Role       string    `json:"role"`
+	Healthy    bool      `json:"healthy"`
+	Successes  int       `json:"consecutive_successes"`
+	Failures   int       `json:"consecutive_failures"`
+	LastError  string    `json:"last_error,omitempty"`
+	LastCheck  time.Time `json:"last_check,omitempty"`
+	LastChange time.Time `json:"last_change,omitempty"`
+}
+
+// GroupStatus 故障转移组的状态
+type GroupStatus struct {
+	Name      string         `json:"name"`
+	Domain    string         `json:"domain"`
+	RR        string         `json:"rr"`
+	Type      string         `json:"type"`
+	Mode      string         `json:"mode"`
+	Active    []string       `json:"active"` // 当前生效的值
+	Targets   []TargetStatus `json:"targets"`
+	LastSync  time.Time      `json:"last_sync,omitempty"`
+	LastError string         `json:"last_error,omitempty"`
+}
+
+// group 运行中的故障转移组
+type group struct {
+	cfg     Group
+	checker Checker
+
+	mu      sync.Mutex
+	status  GroupStatus
+	allDown bool
+}
+
+// Manager 定期检查各故障转移组的目标，根据健康状态调整解析记录
+type Manager struct {
+	records  RecordService
+	notifier notify.Notifier
+	log      *zap.Logger
+	groups   []*group
+}
+
+// NewManager 创建故障转移管理器，校验并补全各组的配置
+func NewManager(records RecordService, notifier notify.Notifier, groups []Group) (*Manager, error) {
+	m := &Manager{
+		records:  records,
+		notifier: notifier,
+		log:      logger.GetLogger(),
+	}
+
+	names := make(map[string]bool)
+	for i := range groups {
+		cfg := groups[i]
+		if err := normalizeGroup(&cfg); err != nil {
+			return nil, fmt.Errorf("故障转移组[%d] %s: %w", i, cfg.Name, err)
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("故障转移组名称重复: %s", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		checker, err := NewChecker(&cfg.Check)
+		if err != nil {
+			return nil, fmt.Errorf("故障转移组 %s: %w", cfg.Name, err)
+		}
+
+		g := &group{
+			cfg:     cfg,
+			checker: checker,
+			status: GroupStatus{
+				Name:   cfg.Name,
+				Domain: cfg.Domain,
+				RR:     cfg.RR,
+				Type:   cfg.Type,
+				Mode:   cfg.Mode,
+			},
+		}
+		// 启动时假定所有目标健康，连续失败 Fall 次后才切换
+		for j, value := range cfg.values() {
+			role := RoleBackup
+			if j == 0 {
+				role = RolePrimary
+			}
+			g.status.Targets = append(g.status.Targets, TargetStatus{Value: value, Role: role, Healthy: true})
+		}
+		m.groups = append(m.groups, g)
+	}
+	return m, nil
+}
+
+// normalizeGroup 校验组的配置并补全默认值
+func normalizeGroup(g *Group) error {
+	if g.Name == "" {
+		return fmt.Errorf("名称不能为空")
+	}
+	if g.Domain == "" || g.RR == "" {
+		return fmt.Errorf("域名和主机记录不能为空")
+	}
+	g.Type = strings.ToUpper(g.Type)
+	if g.Type == "" {
+		g.Type = validation.TypeA
+	}
+	if g.Mode == "" {
+		g.Mode = ModeSwitch
+	}
+	if g.Mode != ModeSwitch && g.Mode != ModeMulti {
+		return fmt.Errorf("不支持的模式: %s", g.Mode)
+	}
+	if g.Primary == "" {
+		return fmt.Errorf("主用值不能为空")
+	}
+	values := g.values()
+	for i, value := range values {
+		if err := validation.ValidateRecord(validation.Record{RR: g.RR, Type: g.Type, Value: value, TTL: g.TTL}); err != nil {
+			return err
+		}
+		if slices.Contains(values[:i], value) {
+			return fmt.Errorf("值重复: %s", value)
+		}
+	}
+	if g.Interval <= 0 {
+		g.Interval = DefaultInterval
+	}
+	if g.Check.Timeout <= 0 {
+		g.Check.Timeout = DefaultTimeout
+	}
+	if g.Check.Type == CheckHTTP && g.Check.Host == "" {
+		g.Check.Host = g.fqdn()
+	}
+	if g.Fall <= 0 {
+		g.Fall = DefaultFall
+	}
+	if g.Rise <= 0 {
+		g.Rise = DefaultRise
+	}
+	return nil
+}
+
+// values 按优先级排列的所有值
+func (g *Group) values() []string {
+	return append([]string{g.Primary}, g.Backups...)
+}
+
+// fqdn 解析记录的完整名称
+func (g *Group) fqdn() string {
+	if g.RR == "@" {
+		return g.Domain
+	}
+	return g.RR + "." + g.Domain
+}
+
+// Run 启动所有组的定期检查，直到 ctx 被取消
+func (m *Manager) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, g := range m.groups {
+		wg.Add(1)
+		go func(g *group) {
+			defer wg.Done()
+			m.runGroup(ctx, g)
+		}(g)
+	}
+	m.log.Info("故障转移检查已启动", zap.Int("groups", len(m.groups)))
+	wg.Wait()
+}
+
+// runGroup 定期检查一个组
+func (m *Manager) runGroup(ctx context.Context, g *group) {
+	ticker := time.NewTicker(g.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.check(ctx, g)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check 检查组的所有目标并同步解析记录
+func (m *Manager) check(ctx context.Context, g *group) {
+	values := g.cfg.values()
+	results := make([]error, len(values))
+
+	var wg sync.WaitGroup
+	for i, value := range values {
+		wg.Add(1)
+		go func(i int, value string) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, g.cfg.Check.Timeout)
+			defer cancel()
+			results[i] = g.checker.Check(checkCtx, value)
+		}(i, value)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	// 持锁只更新健康状态，调用上游接口前释放，避免状态查询等待限流
+	g.mu.Lock()
+	now := time.Now()
+	for i, err := range results {
+		t := &g.status.Targets[i]
+		t.LastCheck = now
+		if err == nil {
+			t.Successes++
+			t.Failures = 0
+			t.LastError = ""
+			if !t.Healthy && t.Successes >= g.cfg.Rise {
+				t.Healthy = true
+				t.LastChange = now
+				m.notifyTarget(g, t, "failover.target_up", "目标已恢复")
+			}
+			continue
+		}
+
+		t.Failures++
+		t.Successes = 0
+		t.LastError = redact.String(err.Error())
+		if t.Healthy && t.Failures >= g.cfg.Fall {
+			t.Healthy = false
+			t.LastChange = now
+			m.notifyTarget(g, t, "failover.target_down", "目标不健康")
+		}
+	}
+
+	allDown := !slices.ContainsFunc(g.status.Targets, func(t TargetStatus) bool { return t.Healthy })
+	if allDown && !g.allDown {
+		m.log.Error("故障转移组的所有目标都不健康，保持当前解析", zap.String("group", g.cfg.Name))
+		m.notifier.Notify(&notify.Event{
+			Kind:    "failover.all_down",
+			Title:   fmt.Sprintf("故障转移组 %s 的所有目标都不健康", g.cfg.Name),
+			Message: "没有可切换的健康目标，保持当前解析",
+			Fields:  map[string]string{"group": g.cfg.Name, "name": g.cfg.fqdn()},
+		})
+	}
+	g.allDown = allDown
+	targets := slices.Clone(g.status.Targets)
+	g.mu.Unlock()
+
+	var (
+		active []string
+		err    error
+	)
+	if g.cfg.Mode == ModeMulti {
+		active, err = m.syncMulti(g, targets, allDown)
+	} else {
+		active, err = m.syncSwitch(g, targets)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if active != nil {
+		g.status.Active = active
+	}
+	g.status.LastSync = now
+	g.status.LastError = ""
+	if err != nil {
+		g.status.LastError = redact.String(err.Error())
+		m.log.Error("同步故障转移记录失败", zap.String("group", g.cfg.Name), zap.Error(err))
+	}
+}
+
+// syncSwitch 将唯一的解析记录切换为优先级最高的健康目标，返回当前生效的值，无法确定时返回 nil
+func (m *Manager) syncSwitch(g *group, targets []TargetStatus) ([]string, error) {
+	records, err := m.groupRecords(g)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 1 {
+		return nil, apperror.Conflict(fmt.Sprintf("%s 有 %d 条%s记录，switch 模式要求只有一条", g.cfg.fqdn(), len(records), g.cfg.Type))
+	}
+
+	desired := ""
+	for _, t := range targets {
+		if t.Healthy {
+			desired = t.Value
+			break
+		}
+	}
+
+	if len(records) == 0 {
+		if desired == "" {
+			desired = g.cfg.Primary
+		}
+		if _, err := m.records.AddDomainRecord(g.cfg.Domain, g.input(desired, 0)); err != nil {
+			return nil, err
+		}
+		m.log.Info("已创建故障转移记录", zap.String("group", g.cfg.Name), zap.String("value", desired))
+		return []string{desired}, nil
+	}
+
+	current := records[0]
+	if desired == "" || current.Value == desired {
+		return []string{current.Value}, nil
+	}
+
+	if err := m.records.UpdateDomainRecord(current.RecordId, g.input(desired, current.TTL)); err != nil {
+		return []string{current.Value}, err
+	}
+
+	m.log.Warn("故障转移已切换解析",
+		zap.String("group", g.cfg.Name),
+		zap.String("from", current.Value),
+		zap.String("to", desired),
+	)
+	m.notifier.Notify(&notify.Event{
+		Kind:    "failover.switched",
+		Title:   fmt.Sprintf("故障转移组 %s 已切换解析", g.cfg.Name),
+		Message: fmt.Sprintf("%s 从 %s 切换到 %s", g.cfg.fqdn(), current.Value, desired),
+		Fields: map[string]string{
+			"group": g.cfg.Name,
+			"name":  g.cfg.fqdn(),
+			"from":  current.Value,
+			"to":    desired,
+		},
+	})
+	return []string{desired}, nil
+}
+
+// syncMulti 启用健康目标的记录，暂停不健康目标的记录；所有目标都不健康时全部启用。
+// 返回已启用的值，出错时只包含出错前处理的目标
+func (m *Manager) syncMulti(g *group, targets []TargetStatus, allDown bool) ([]string, error) {
+	records, err := m.groupRecords(g)
+	if err != nil {
+		return nil, err
+	}
+	byValue := make(map[string]service.DomainRecord)
+	for _, r := range records {
+		byValue[r.Value] = r
+	}
+
+	active := make([]string, 0, len(targets))
+	for _, t := range targets {
+		want := service.RecordStatusEnable
+		if !t.Healthy && !allDown {
+			want = service.RecordStatusDisable
+		}
+
+		r, ok := byValue[t.Value]
+		if !ok {
+			recordId, err := m.records.AddDomainRecord(g.cfg.Domain, g.input(t.Value, 0))
+			if err != nil {
+				return active, err
+			}
+			r = service.DomainRecord{RecordId: recordId, Value: t.Value, Status: service.RecordStatusEnable}
+			m.log.Info("已创建故障转移记录", zap.String("group", g.cfg.Name), zap.String("value", t.Value))
+		}
+
+		if !strings.EqualFold(r.Status, want) {
+			if err := m.records.SetDomainRecordStatus(r.RecordId, want); err != nil {
+				return active, err
+			}
+			kind, action := "failover.record_enabled", "已启用"
+			if want == service.RecordStatusDisable {
+				kind, action = "failover.record_disabled", "已暂停"
+			}
+			m.log.Warn("故障转移"+action+"记录",
+				zap.String("group", g.cfg.Name),
+				zap.String("value", t.Value),
+			)
+			m.notifier.Notify(&notify.Event{
+				Kind:    kind,
+				Title:   fmt.Sprintf("故障转移组 %s %s记录 %s", g.cfg.Name, action, t.Value),
+				Message: fmt.Sprintf("%s 的%s记录 %s %s", g.cfg.fqdn(), g.cfg.Type, t.Value, action),
+				Fields: map[string]string{
+					"group": g.cfg.Name,
+					"name":  g.cfg.fqdn(),
+					"value": t.Value,
+				},
+			})
+		}
+		if want == service.RecordStatusEnable {
+			active = append(active, t.Value)
+		}
+	}
+	return active, nil
+}
+
+// groupRecords 查询组管理的默认线路记录
+func (m *Manager) groupRecords(g *group) ([]service.DomainRecord, error) {
+	records, err := m.records.QueryDomainRecords(&service.RecordQuery{
+		DomainName: g.cfg.Domain,
+		RR:         g.cfg.RR,
+		Type:       g.cfg.Type,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(records, func(r service.DomainRecord) bool {
+		return r.Line != "" && r.Line != defaultLine
+	}), nil
+}
+
+// input 生成组的解析记录参数，组未配置TTL时使用 ttl
+func (g *group) input(value string, ttl int64) *service.DomainRecordInput {
+	if g.cfg.TTL != 0 {
+		ttl = g.cfg.TTL
+	}
+	return &service.DomainRecordInput{
+		RR:    g.cfg.RR,
+		Type:  g.cfg.Type,
+		Value: value,
+		TTL:   ttl,
+	}
+}
+
+// notifyTarget 记录并通知目标健康状态的变化
+func (m *Manager) notifyTarget(g *group, t *TargetStatus, kind, title string) {
+	m.log.Warn("故障转移"+title,
+		zap.String("group", g.cfg.Name),
+		zap.String("value", t.Value),
+		zap.String("error", t.LastError),
+	)
+	fields := map[string]string{
+		"group": g.cfg.Name,
+		"name":  g.cfg.fqdn(),
+		"value": t.Value,
+		"role":  t.Role,
+	}
+	if t.LastError != "" {
+		fields["error"] = t.LastError
+	}
+	m.notifier.Notify(&notify.Event{
+		Kind:    kind,
+		Title:   fmt.Sprintf("故障转移组 %s 的%s", g.cfg.Name, title),
+		Message: fmt.Sprintf("%s 的目标 %s（%s）%s", g.cfg.fqdn(), t.Value, t.Role, title),
+		Fields:  fields,
+	})
+}
+
+// Status 返回所有组的状态
+func (m *Manager) Status() []GroupStatus {
+	statuses := make([]GroupStatus, 0, len(m.groups))
+	for _, g := range m.groups {
+		statuses = append(statuses, g.snapshot())
+	}
+	return statuses
+}
+
+// GroupStatus 返回指定组的状态
+func (m *Manager) GroupStatus(name string) (*GroupStatus, error) {
+	for _, g := range m.groups {
+		if g.cfg.Name == name {
+			status := g.snapshot()
+			return &status, nil
+		}
+	}
+	return nil, apperror.NotFound(fmt.Sprintf("故障转移组不存在: %s", name))
+}
+
+// snapshot 复制组的状态
+func (g *group) snapshot() GroupStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	status := g.status
+	status.Active = slices.Clone(g.status.Active)
+	status.Targets = slices.Clone(g.status.Targets)
+	return status
+}
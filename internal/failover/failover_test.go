@@ -0,0 +1,118 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"dns-update/internal/notify"
+	"dns-update/internal/service"
+)
+
+// blockingRecords 查询记录时阻塞直到 release 关闭，模拟被限流的上游接口
+type blockingRecords struct {
+	entered chan struct{}
+	release chan struct{}
+
+	mu      sync.Mutex
+	records []service.DomainRecord
+}
+
+func (b *blockingRecords) QueryDomainRecords(*service.RecordQuery) ([]service.DomainRecord, error) {
+	close(b.entered)
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]service.DomainRecord(nil), b.records...), nil
+}
+
+func (b *blockingRecords) AddDomainRecord(string, *service.DomainRecordInput) (string, error) {
+	return "", errors.New("不应创建记录")
+}
+
+func (b *blockingRecords) UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := range b.records {
+		if b.records[i].RecordId == recordId {
+			b.records[i].Value = input.Value
+		}
+	}
+	return nil
+}
+
+func (b *blockingRecords) SetDomainRecordStatus(string, string) error {
+	return nil
+}
+
+// stubChecker primary 不健康，其他目标健康
+type stubChecker struct{}
+
+func (stubChecker) Check(_ context.Context, value string) error {
+	if value == "192.0.2.1" {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// nopNotifier 丢弃所有通知
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(*notify.Event) {}
+
+func TestCheckDoesNotHoldLockDuringSync(t *testing.T) {
+	records := &blockingRecords{
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+		records: []service.DomainRecord{{RecordId: "1", RR: "www", Type: "A", Value: "192.0.2.1", Line: defaultLine}},
+	}
+	m, err := NewManager(records, nopNotifier{}, []Group{{
+		Name:    "web",
+		Domain:  "example.com",
+		RR:      "www",
+		Primary: "192.0.2.1",
+		Backups: []string{"192.0.2.2"},
+		Check:   CheckConfig{Type: CheckTCP, Port: 80},
+		Fall:    1,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := m.groups[0]
+	g.checker = stubChecker{}
+
+	done := make(chan struct{})
+	go func() {
+		m.check(context.Background(), g)
+		close(done)
+	}()
+	<-records.entered
+
+	// 同步解析记录期间可以读取健康状态
+	statusRead := make(chan GroupStatus)
+	go func() {
+		status, _ := m.GroupStatus("web")
+		statusRead <- *status
+	}()
+	select {
+	case status := <-statusRead:
+		if status.Targets[0].Healthy || status.Targets[0].LastError == "" {
+			t.Errorf("同步前应已更新健康状态: %+v", status.Targets[0])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("同步解析记录时读取状态被阻塞")
+	}
+
+	close(records.release)
+	<-done
+
+	status, _ := m.GroupStatus("web")
+	if len(status.Active) != 1 || status.Active[0] != "192.0.2.2" {
+		t.Errorf("Active = %v，期望切换到备用值", status.Active)
+	}
+	if status.LastError != "" || status.LastSync.IsZero() {
+		t.Errorf("同步结果未写入状态: %+v", status)
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+
+	"dns-update/internal/acme"
+	"dns-update/internal/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AcmeHandler 处理 ACME DNS-01 验证相关的请求，兼容 lego httpreq 和 acme-dns 接口
+type AcmeHandler struct {
+	manager *acme.Manager
+	tokens  []acme.Token
+}
+
+// NewAcmeHandler 创建 ACME 验证处理器
+func NewAcmeHandler(manager *acme.Manager, tokens []acme.Token) *AcmeHandler {
+	return &AcmeHandler{
+		manager: manager,
+		tokens:  tokens,
+	}
+}
+
+// HTTPReqRequest lego httpreq 的请求体，默认模式使用 fqdn/value，RAW 模式使用 domain/token/keyAuth
+type HTTPReqRequest struct {
+	FQDN    string `json:"fqdn"`
+	Value   string `json:"value"`
+	Domain  string `json:"domain"`
+	Token   string `json:"token"`
+	KeyAuth string `json:"keyAuth"`
+}
+
+// AcmeDNSUpdateRequest acme-dns update 接口的请求体
+type AcmeDNSUpdateRequest struct {
+	Subdomain string `json:"subdomain"` // acme-dns 账户的 subdomain（需在凭证中登记）或证书域名
+	TXT       string `json:"txt"`
+}
+
+// AcmeDNSUpdateResponse acme-dns update 接口的响应
+type AcmeDNSUpdateResponse struct {
+	TXT string `json:"txt"`
+}
+
+// Present godoc
+// @Summary      创建ACME验证记录
+// @Description  兼容 lego httpreq 的 present 接口，使用 Basic 认证，只能为凭证允许的域名创建 _acme-challenge TXT 记录
+// @Tags         acme
+// @Accept       json
+// @Produce      json
+// @Param        request  body      HTTPReqRequest  true  "验证信息"
+// @Success      200
+// @Failure      400      {object}  apperror.Response
+// @Failure      401      {object}  apperror.Response
+// @Failure      403      {object}  apperror.Response
+// @Failure      404      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /acme/httpreq/present [post]
+func (h *AcmeHandler) Present(c *gin.Context) {
+	fqdn, value, ok := h.bindHTTPReq(c)
+	if !ok {
+		return
+	}
+
+	if err := h.manager.Present(fqdn, value); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// Cleanup godoc
+// @Summary      删除ACME验证记录
+// @Description  兼容 lego httpreq 的 cleanup 接口，只删除值相同的验证记录，同名的其他验证记录不受影响
+// @Tags         acme
+// @Accept       json
+// @Produce      json
+// @Param        request  body      HTTPReqRequest  true  "验证信息"
+// @Success      200
+// @Failure      400      {object}  apperror.Response
+// @Failure      401      {object}  apperror.Response
+// @Failure      403      {object}  apperror.Response
+// @Failure      404      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /acme/httpreq/cleanup [post]
+func (h *AcmeHandler) Cleanup(c *gin.Context) {
+	fqdn, value, ok := h.bindHTTPReq(c)
+	if !ok {
+		return
+	}
+
+	if err := h.manager.Cleanup(fqdn, value); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// Update godoc
+// @Summary      设置ACME验证记录（acme-dns）
+// @Description  兼容 acme-dns 的 update 接口，使用 X-Api-User/X-Api-Key 认证。subdomain 为凭证 subdomains 中登记的
+// @Description  acme-dns 账户 subdomain，或直接填写证书域名，验证记录写入 _acme-challenge.<证书域名>，每个名称保留最近的两个值
+// @Tags         acme
+// @Accept       json
+// @Produce      json
+// @Param        X-Api-User  header    string                true  "用户名"
+// @Param        X-Api-Key   header    string                true  "密码"
+// @Param        request     body      AcmeDNSUpdateRequest  true  "验证信息"
+// @Success      200         {object}  AcmeDNSUpdateResponse
+// @Failure      400         {object}  apperror.Response
+// @Failure      401         {object}  apperror.Response
+// @Failure      403         {object}  apperror.Response
+// @Failure      404         {object}  apperror.Response
+// @Failure      500         {object}  apperror.Response
+// @Router       /acme-dns/update [post]
+func (h *AcmeHandler) Update(c *gin.Context) {
+	token := acme.Authenticate(h.tokens, c.GetHeader("X-Api-User"), c.GetHeader("X-Api-Key"))
+	if token == nil {
+		respondError(c, apperror.Unauthorized("认证失败"))
+		return
+	}
+
+	var req AcmeDNSUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+	if req.Subdomain == "" {
+		respondError(c, apperror.BadRequest("subdomain不能为空"))
+		return
+	}
+
+	domain, ok := token.ResolveSubdomain(req.Subdomain)
+	if !ok {
+		respondError(c, apperror.Invalid(apperror.FieldError{
+			Field:   "subdomain",
+			Message: "subdomain未在凭证的subdomains中登记，请配置其对应的证书域名",
+		}))
+		return
+	}
+
+	fqdn := acme.ChallengeFQDN(domain)
+	if !token.Allows(fqdn) {
+		respondError(c, apperror.Forbidden("凭证无权操作该域名"))
+		return
+	}
+
+	if err := h.manager.Update(fqdn, req.TXT); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AcmeDNSUpdateResponse{TXT: req.TXT})
+}
+
+// bindHTTPReq 认证并解析 lego httpreq 请求，返回验证记录名称和值，失败时直接写入错误响应
+func (h *AcmeHandler) bindHTTPReq(c *gin.Context) (string, string, bool) {
+	username, password, _ := c.Request.BasicAuth()
+	token := acme.Authenticate(h.tokens, username, password)
+	if token == nil {
+		c.Header("WWW-Authenticate", `Basic realm="dns-update"`)
+		respondError(c, apperror.Unauthorized("认证失败"))
+		return "", "", false
+	}
+
+	var req HTTPReqRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return "", "", false
+	}
+
+	fqdn, value := req.FQDN, req.Value
+	if fqdn == "" && req.Domain != "" && req.KeyAuth != "" {
+		// RAW 模式由服务端计算验证记录的名称和值
+		fqdn = acme.ChallengeFQDN(req.Domain)
+		value = acme.ChallengeValue(req.KeyAuth)
+	}
+	if fqdn == "" || value == "" {
+		respondError(c, apperror.BadRequest("需要提供fqdn和value，或domain和keyAuth"))
+		return "", "", false
+	}
+
+	if !token.Allows(fqdn) {
+		respondError(c, apperror.Forbidden("凭证无权操作该域名"))
+		return "", "", false
+	}
+	return fqdn, value, true
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"dns-update/internal/approval"
+	"dns-update/internal/middleware"
+	"dns-update/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BreakGlassHeader 紧急变更原因的请求头，携带时写操作可以越过策略限制
+const BreakGlassHeader = "X-Break-Glass"
+
+// actor 获取当前请求的调用方
+func actor(c *gin.Context) *approval.Actor {
+	return &approval.Actor{
+		Identity:  middleware.GetIdentity(c),
+		RequestId: middleware.GetRequestId(c),
+	}
+}
+
+// serviceActor 获取当前请求的调用方，用于策略检查和审计；定时变更等任务保存后以该身份执行
+func serviceActor(c *gin.Context) service.Actor {
+	return service.Actor{
+		Name:       middleware.GetIdentity(c),
+		RequestId:  middleware.GetRequestId(c),
+		BreakGlass: c.GetHeader(BreakGlassHeader),
+	}
+}
+
+// withActor 返回以当前请求的调用方执行写操作的服务，用于策略检查和审计
+func withActor(c *gin.Context, dnsService *service.DNSService) *service.DNSService {
+	return dnsService.WithActor(serviceActor(c))
+}
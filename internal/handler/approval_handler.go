@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/approval"
+	"dns-update/internal/batch"
+	"dns-update/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DecisionRequest 审批或拒绝变更请求的请求
+type DecisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// ApprovalHandler 处理变更审批相关的请求
+type ApprovalHandler struct {
+	manager *approval.Manager
+}
+
+// NewApprovalHandler 创建变更审批处理器
+func NewApprovalHandler(manager *approval.Manager) *ApprovalHandler {
+	return &ApprovalHandler{
+		manager: manager,
+	}
+}
+
+// ListApprovals godoc
+// @Summary      获取变更请求
+// @Description  按提交时间倒序返回受保护记录的变更请求
+// @Tags         approval
+// @Produce      json
+// @Param        status  query     string  false  "状态(pending/applying/applied/failed/rejected/expired)"
+// @Param        domain  query     string  false  "域名"
+// @Success      200     {array}   approval.Change
+// @Router       /approvals [get]
+func (h *ApprovalHandler) ListApprovals(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.List(c.Query("status"), c.Query("domain")))
+}
+
+// GetApproval godoc
+// @Summary      查询变更请求
+// @Description  返回变更请求及提交时相对于当前解析记录的差异
+// @Tags         approval
+// @Produce      json
+// @Param        change_id  path      string  true  "变更请求ID"
+// @Success      200        {object}  approval.Change
+// @Failure      404        {object}  apperror.Response
+// @Router       /approvals/{change_id} [get]
+func (h *ApprovalHandler) GetApproval(c *gin.Context) {
+	change, err := h.manager.Get(c.Param("change_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// ApproveChange godoc
+// @Summary      审批通过变更请求
+// @Description  需要由提交人以外的已授权身份审批，通过后以原子方式执行全部操作；
+// @Description  提交后解析记录已被修改时不执行，变更请求标记为失败
+// @Tags         approval
+// @Accept       json
+// @Produce      json
+// @Param        change_id  path      string           true   "变更请求ID"
+// @Param        request    body      DecisionRequest  false  "审批意见"
+// @Success      200        {object}  approval.Change
+// @Failure      401        {object}  apperror.Response
+// @Failure      403        {object}  apperror.Response
+// @Failure      404        {object}  apperror.Response
+// @Failure      409        {object}  apperror.Response
+// @Router       /approvals/{change_id}/approve [post]
+func (h *ApprovalHandler) ApproveChange(c *gin.Context) {
+	req, ok := bindDecision(c)
+	if !ok {
+		return
+	}
+
+	change, err := h.manager.Approve(c.Request.Context(), c.Param("change_id"), req.Comment, actor(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// RejectChange godoc
+// @Summary      拒绝变更请求
+// @Description  需要由提交人以外的已授权身份操作
+// @Tags         approval
+// @Accept       json
+// @Produce      json
+// @Param        change_id  path      string           true   "变更请求ID"
+// @Param        request    body      DecisionRequest  false  "拒绝原因"
+// @Success      200        {object}  approval.Change
+// @Failure      401        {object}  apperror.Response
+// @Failure      403        {object}  apperror.Response
+// @Failure      404        {object}  apperror.Response
+// @Failure      409        {object}  apperror.Response
+// @Router       /approvals/{change_id}/reject [post]
+func (h *ApprovalHandler) RejectChange(c *gin.Context) {
+	req, ok := bindDecision(c)
+	if !ok {
+		return
+	}
+
+	change, err := h.manager.Reject(c.Param("change_id"), req.Comment, actor(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// Guard 拒绝对受保护记录的其他写操作，这些操作无法转为变更请求
+func (h *ApprovalHandler) Guard(c *gin.Context) {
+	domain := c.Param("domain")
+	if h.manager.Protects(domain, c.Param("rr")) {
+		respondError(c, apperror.Forbidden(fmt.Sprintf("域名 %s 受变更审批保护，请通过解析记录或批量操作接口提交变更", domain)))
+		return
+	}
+	c.Next()
+}
+
+// requestApproval 涉及受保护记录的操作转为待审批的变更请求并返回202，返回是否已写入响应。
+// known 为已查询的修改前记录，避免重复查询
+func requestApproval(c *gin.Context, approvals *approval.Manager, domain string, req *batch.Request, known ...*service.DomainRecord) bool {
+	if approvals == nil {
+		return false
+	}
+
+	change, err := approvals.Submit(domain, req, actor(c), known...)
+	if err != nil {
+		respondError(c, err)
+		return true
+	}
+	if change == nil {
+		return false
+	}
+
+	c.Header("Location", "/api/approvals/"+change.Id)
+	c.JSON(http.StatusAccepted, change)
+	return true
+}
+
+// bindDecision 解析审批意见，请求体可以为空
+func bindDecision(c *gin.Context) (*DecisionRequest, bool) {
+	var req DecisionRequest
+	if c.Request.ContentLength == 0 {
+		return &req, true
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return nil, false
+	}
+	return &req, true
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/audit"
+	"dns-update/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler 处理审计日志查询
+type AuditHandler struct {
+	log *audit.Log
+}
+
+// NewAuditHandler 创建审计日志处理器
+func NewAuditHandler(log *audit.Log) *AuditHandler {
+	return &AuditHandler{
+		log: log,
+	}
+}
+
+// ListAuditEntries godoc
+// @Summary      查询审计日志
+// @Description  按时间倒序返回最近的审计日志
+// @Tags         audit
+// @Produce      json
+// @Param        actor   query     string  false  "操作人"
+// @Param        action  query     string  false  "操作，前缀匹配，如 approval."
+// @Param        domain  query     string  false  "域名"
+// @Param        limit   query     int     false  "条数，默认100，最大1000"
+// @Success      200     {array}   audit.Entry
+// @Failure      400     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /audit [get]
+func (h *AuditHandler) ListAuditEntries(c *gin.Context) {
+	q := &audit.Query{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		Domain: c.Query("domain"),
+	}
+	if s := c.Query("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			respondError(c, apperror.Invalid(apperror.FieldError{Field: "limit", Message: "limit必须是正整数"}))
+			return
+		}
+		q.Limit = limit
+	}
+
+	entries, err := h.log.List(q)
+	if err != nil {
+		respondError(c, apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// recordAudit 以当前请求的调用方记录审计日志
+func recordAudit(c *gin.Context, log *audit.Log, action, domain, target string, detail map[string]string) {
+	log.Record(&audit.Entry{
+		Actor:     middleware.GetIdentity(c),
+		Action:    action,
+		Domain:    domain,
+		Target:    target,
+		RequestId: middleware.GetRequestId(c),
+		Detail:    detail,
+	})
+}
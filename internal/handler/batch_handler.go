@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/approval"
+	"dns-update/internal/batch"
+	"dns-update/internal/service"
+	"dns-update/pkg/idn"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchHandler 处理批量解析记录操作的HTTP请求
+type BatchHandler struct {
+	dnsService *service.DNSService
+	executor   *batch.Executor
+	jobs       *batch.JobManager
+	approvals  *approval.Manager // 未配置受保护记录时为 nil
+}
+
+// NewBatchHandler 创建批量操作处理器
+func NewBatchHandler(dnsService *service.DNSService, executor *batch.Executor, jobs *batch.JobManager, approvals *approval.Manager) *BatchHandler {
+	return &BatchHandler{
+		dnsService: dnsService,
+		executor:   executor,
+		jobs:       jobs,
+		approvals:  approvals,
+	}
+}
+
+// ExecuteBatch godoc
+// @Summary      批量操作解析记录
+// @Description  批量执行 create/update/delete/enable/disable 操作并返回每个操作的结果。
+// @Description  atomic 为 true 时任一操作失败将回滚已成功的操作；操作数超过50或 async=true 时以异步任务执行并返回202。
+// @Description  涉及受保护记录时整批操作转为待审批的变更请求，返回202
+// @Tags         record-management
+// @Accept       json
+// @Produce      json
+// @Param        domain   path      string         true   "域名"
+// @Param        async    query     boolean        false  "是否以异步任务执行"
+// @Param        request  body      batch.Request  true   "批量操作"
+// @Success      200      {object}  batch.Result
+// @Success      202      {object}  batch.Job
+// @Failure      400      {object}  apperror.Response
+// @Router       /domains/{domain}/records/batch [post]
+func (h *BatchHandler) ExecuteBatch(c *gin.Context) {
+	domain := c.Param("domain")
+
+	var req batch.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondError(c, err)
+		return
+	}
+	if requestApproval(c, h.approvals, domain, &req) {
+		return
+	}
+
+	// 以调用方身份执行，写操作经过策略检查
+	executor := h.executor.With(withActor(c, h.dnsService))
+	if c.Query("async") == "true" || len(req.Operations) > batch.AsyncThreshold {
+		job := h.jobs.Submit(executor, domain, &req)
+		c.Header("Location", c.Request.URL.Path+"/"+job.Id)
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	c.JSON(http.StatusOK, executor.Execute(c.Request.Context(), domain, &req))
+}
+
+// GetBatchJob godoc
+// @Summary      查询批量操作任务
+// @Description  查询异步批量操作任务的状态和结果
+// @Tags         record-management
+// @Accept       json
+// @Produce      json
+// @Param        domain  path      string  true  "域名"
+// @Param        job_id  path      string  true  "任务ID"
+// @Success      200     {object}  batch.Job
+// @Failure      404     {object}  apperror.Response
+// @Router       /domains/{domain}/records/batch/{job_id} [get]
+func (h *BatchHandler) GetBatchJob(c *gin.Context) {
+	job, ok := h.jobs.Get(c.Param("job_id"))
+	if !ok || !idn.Equal(job.Domain, c.Param("domain")) {
+		respondError(c, apperror.NotFound("批量操作任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+	"dns-update/pkg/idn"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListCustomLines godoc
+// @Summary      获取自定义线路
+// @Description  获取域名下按地址段定义的自定义线路（需要企业版）
+// @Tags         line
+// @Produce      json
+// @Param        domain  path      string  true  "域名"
+// @Success      200     {array}   service.CustomLine
+// @Failure      400     {object}  apperror.Response
+// @Failure      403     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /domains/{domain}/custom-lines [get]
+func (h *DNSHandler) ListCustomLines(c *gin.Context) {
+	lines, err := h.dnsService.ListCustomLines(c.Param("domain"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if lines == nil {
+		lines = []service.CustomLine{}
+	}
+
+	c.JSON(http.StatusOK, lines)
+}
+
+// GetCustomLine godoc
+// @Summary      查询自定义线路
+// @Tags         line
+// @Produce      json
+// @Param        domain   path      string   true  "域名"
+// @Param        line_id  path      integer  true  "线路ID"
+// @Success      200      {object}  service.CustomLine
+// @Failure      400      {object}  apperror.Response
+// @Failure      404      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /domains/{domain}/custom-lines/{line_id} [get]
+func (h *DNSHandler) GetCustomLine(c *gin.Context) {
+	line, ok := h.getCustomLine(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, line)
+}
+
+// CreateCustomLine godoc
+// @Summary      添加自定义线路
+// @Description  按CIDR、单个地址或起止地址定义线路，地址段不能与其他自定义线路重叠。
+// @Description  返回的线路代码可用于创建和修改解析记录
+// @Tags         line
+// @Accept       json
+// @Produce      json
+// @Param        domain  path      string                   true  "域名"
+// @Param        line    body      service.CustomLineInput  true  "自定义线路"
+// @Success      201     {object}  service.CustomLine
+// @Failure      400     {object}  apperror.Response
+// @Failure      403     {object}  apperror.Response
+// @Failure      409     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /domains/{domain}/custom-lines [post]
+func (h *DNSHandler) CreateCustomLine(c *gin.Context) {
+	var input service.CustomLineInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	line, err := withActor(c, h.dnsService).AddCustomLine(c.Param("domain"), &input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, line)
+}
+
+// UpdateCustomLine godoc
+// @Summary      修改自定义线路
+// @Description  修改线路名称并整体替换地址段，地址段不能与其他自定义线路重叠
+// @Tags         line
+// @Accept       json
+// @Produce      json
+// @Param        domain   path      string                   true  "域名"
+// @Param        line_id  path      integer                  true  "线路ID"
+// @Param        line     body      service.CustomLineInput  true  "自定义线路"
+// @Success      204
+// @Failure      400      {object}  apperror.Response
+// @Failure      404      {object}  apperror.Response
+// @Failure      409      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /domains/{domain}/custom-lines/{line_id} [put]
+func (h *DNSHandler) UpdateCustomLine(c *gin.Context) {
+	var input service.CustomLineInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	line, ok := h.getCustomLine(c)
+	if !ok {
+		return
+	}
+
+	if err := withActor(c, h.dnsService).UpdateCustomLine(c.Param("domain"), line.Id, &input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteCustomLine godoc
+// @Summary      删除自定义线路
+// @Tags         line
+// @Produce      json
+// @Param        domain   path      string   true  "域名"
+// @Param        line_id  path      integer  true  "线路ID"
+// @Success      204
+// @Failure      400      {object}  apperror.Response
+// @Failure      404      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /domains/{domain}/custom-lines/{line_id} [delete]
+func (h *DNSHandler) DeleteCustomLine(c *gin.Context) {
+	line, ok := h.getCustomLine(c)
+	if !ok {
+		return
+	}
+
+	if err := withActor(c, h.dnsService).DeleteCustomLine(c.Param("domain"), line.Id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getCustomLine 查询自定义线路并确认其属于指定域名，失败时直接写入错误响应
+func (h *DNSHandler) getCustomLine(c *gin.Context) (*service.CustomLine, bool) {
+	domain := c.Param("domain")
+	lineId, err := strconv.ParseInt(c.Param("line_id"), 10, 64)
+	if err != nil || lineId <= 0 {
+		respondError(c, apperror.BadRequest("线路ID无效"))
+		return nil, false
+	}
+
+	line, err := h.dnsService.GetCustomLine(lineId)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	if line.DomainName != "" && !idn.Equal(line.DomainName, domain) {
+		respondError(c, apperror.NotFound("自定义线路不属于指定域名"))
+		return nil, false
+	}
+
+	return line, true
+}
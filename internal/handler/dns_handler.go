@@ -3,9 +3,13 @@ package handler
 import (
 	"net/http"
 	"strconv"
-	"strings"
 
+	"dns-update/internal/apperror"
+	"dns-update/internal/approval"
+	"dns-update/internal/lease"
 	"dns-update/internal/service"
+	"dns-update/internal/validation"
+	"dns-update/pkg/idn"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,12 +17,16 @@ import (
 // DNSHandler 处理DNS相关的HTTP请求
 type DNSHandler struct {
 	dnsService *service.DNSService
+	leases     *lease.Manager
+	approvals  *approval.Manager // 未配置受保护记录时为 nil
 }
 
 // NewDNSHandler 创建新的DNS处理器
-func NewDNSHandler(dnsService *service.DNSService) *DNSHandler {
+func NewDNSHandler(dnsService *service.DNSService, leases *lease.Manager, approvals *approval.Manager) *DNSHandler {
 	return &DNSHandler{
 		dnsService: dnsService,
+		leases:     leases,
+		approvals:  approvals,
 	}
 }
 
@@ -29,12 +37,12 @@ func NewDNSHandler(dnsService *service.DNSService) *DNSHandler {
 // @Accept       json
 // @Produce      json
 // @Success      200  {array}   service.Domain
-// @Failure      500  {object}  string
+// @Failure      500  {object}  apperror.Response
 // @Router       /domains [get]
 func (h *DNSHandler) ListDomains(c *gin.Context) {
 	domains, err := h.dnsService.ListDomains()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -43,101 +51,100 @@ func (h *DNSHandler) ListDomains(c *gin.Context) {
 
 // ListDomainRecords godoc
 // @Summary      获取域名解析记录
-// @Description  获取指定域名的所有解析记录
+// @Description  获取指定域名的所有解析记录。默认返回完整数组；指定page/per_page/cursor时返回RecordListResponse分页信封；
+// @Description  format=ndjson或Accept为application/x-ndjson时按行流式输出，中途失败时最后一行为StreamErrorLine
 // @Tags         record-management
 // @Accept       json
 // @Produce      json
+// @Produce      application/x-ndjson
 // @Param        domain     path      string  true   "域名"
-// @Param        page_size  query     integer false  "每页记录数，默认20"  minimum(1)  maximum(500)
+// @Param        page_size  query     integer false  "上游接口每页记录数，默认500"  minimum(1)  maximum(500)
+// @Param        page       query     integer false  "页码，指定后返回分页信封"  minimum(1)
+// @Param        per_page   query     integer false  "每页返回的记录数，默认100"  minimum(1)  maximum(500)
+// @Param        cursor     query     string  false  "上一页返回的next_cursor，需与原查询条件一起使用"
+// @Param        sort       query     string  false  "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl"
+// @Param        format     query     string  false  "输出格式(json/ndjson)，ndjson时逐行流式输出"
 // @Success      200    {array}   service.DomainRecord
-// @Failure      500    {object}  string
+// @Failure      400    {object}  apperror.Response
+// @Failure      500    {object}  apperror.Response
 // @Router       /domains/{domain}/records [get]
 func (h *DNSHandler) ListDomainRecords(c *gin.Context) {
-	domain := c.Param("domain")
-
-	// 解析page_size参数
-	opts := service.DefaultListDomainRecordsOptions
-	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
-		pageSize, err := strconv.ParseInt(pageSizeStr, 10, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size必须是有效的整数"})
-			return
-		}
-		if pageSize < 1 || pageSize > 500 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size必须在1-500之间"})
-			return
-		}
-		opts.PageSize = pageSize
+	query := service.RecordQuery{
+		DomainName: c.Param("domain"),
 	}
 
-	records, err := h.dnsService.ListDomainRecords(domain, &opts)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	pageSize, ok := parsePageSize(c)
+	if !ok {
 		return
 	}
+	query.PageSize = pageSize
 
-	c.JSON(http.StatusOK, records)
+	h.respondRecords(c, &query)
 }
 
 // SearchDomainRecords godoc
 // @Summary      搜索域名解析记录
-// @Description  根据多个条件搜索域名解析记录
+// @Description  根据多个条件搜索域名解析记录，支持 DescribeDomainRecords 的全部过滤参数，条件可任意组合。
+// @Description  rr_keyword/type_keyword/value_keyword 只在 ADVANCED 模式下生效，未指定 search_mode 时自动使用 ADVANCED 模式。
+// @Description  分页、排序和流式输出参数与获取域名解析记录接口相同
 // @Tags         record-query
 // @Accept       json
 // @Produce      json
-// @Param        domain      path      string  true   "域名"
-// @Param        record_id   query     string  false  "解析记录ID"
-// @Param        rr          query     string  false  "主机记录"
-// @Param        type        query     string  false  "记录类型"
-// @Param        status      query     string  false  "状态(Enable/Disable)"
-// @Param        page_size   query     integer false  "每页记录数，默认20"  minimum(1)  maximum(500)
+// @Produce      application/x-ndjson
+// @Param        domain         path      string  true   "域名"
+// @Param        record_id      query     string  false  "解析记录ID"
+// @Param        rr             query     string  false  "主机记录（精确匹配）"
+// @Param        keyword        query     string  false  "关键字，LIKE/EXACT模式下匹配主机记录和记录值"
+// @Param        rr_keyword     query     string  false  "主机记录关键字"
+// @Param        type           query     string  false  "记录类型"
+// @Param        type_keyword   query     string  false  "记录类型关键字"
+// @Param        value_keyword  query     string  false  "记录值关键字"
+// @Param        line           query     string  false  "解析线路"
+// @Param        status         query     string  false  "状态(Enable/Disable)"
+// @Param        search_mode    query     string  false  "搜索模式(LIKE/EXACT/ADVANCED)"
+// @Param        order_by       query     string  false  "排序字段"
+// @Param        direction      query     string  false  "排序方向(ASC/DESC)"
+// @Param        page_size      query     integer false  "上游接口每页记录数，默认500"  minimum(1)  maximum(500)
+// @Param        page           query     integer false  "页码，指定后返回分页信封"  minimum(1)
+// @Param        per_page       query     integer false  "每页返回的记录数，默认100"  minimum(1)  maximum(500)
+// @Param        cursor         query     string  false  "上一页返回的next_cursor，需与原查询条件一起使用"
+// @Param        sort           query     string  false  "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl"
+// @Param        format         query     string  false  "输出格式(json/ndjson)，ndjson时逐行流式输出"
 // @Success      200    {array}   service.DomainRecord
-// @Failure      400    {object}  string
-// @Failure      500    {object}  string
+// @Failure      400    {object}  apperror.Response
+// @Failure      500    {object}  apperror.Response
 // @Router       /domains/{domain}/records/search [get]
 func (h *DNSHandler) SearchDomainRecords(c *gin.Context) {
 	domain := c.Param("domain")
 	if domain == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "域名不能为空"})
+		respondError(c, apperror.BadRequest("域名不能为空"))
 		return
 	}
 
-	// 创建查询选项
-	opts := service.SearchDomainRecordsOptions{
-		DomainName: domain,
-		RecordId:   c.Query("record_id"),
-		RR:         c.Query("rr"),
-		Type:       c.Query("type"),
-		Status:     c.Query("status"),
-	}
-
-	// 解析page_size参数
-	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
-		pageSize, err := strconv.ParseInt(pageSizeStr, 10, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size必须是有效的整数"})
-			return
-		}
-		if pageSize < 1 || pageSize > 500 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size必须在1-500之间"})
-			return
-		}
-		opts.PageSize = pageSize
-	}
-
-	// 验证status参数
-	if opts.Status != "" && opts.Status != "Enable" && opts.Status != "Disable" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "status必须是Enable或Disable"})
-		return
+	// 创建查询条件
+	query := service.RecordQuery{
+		DomainName:   domain,
+		RecordId:     c.Query("record_id"),
+		RR:           c.Query("rr"),
+		KeyWord:      c.Query("keyword"),
+		RRKeyWord:    c.Query("rr_keyword"),
+		Type:         c.Query("type"),
+		TypeKeyWord:  c.Query("type_keyword"),
+		ValueKeyWord: c.Query("value_keyword"),
+		Line:         c.Query("line"),
+		Status:       c.Query("status"),
+		SearchMode:   c.Query("search_mode"),
+		OrderBy:      c.Query("order_by"),
+		Direction:    c.Query("direction"),
 	}
 
-	records, err := h.dnsService.SearchDomainRecords(&opts)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	pageSize, ok := parsePageSize(c)
+	if !ok {
 		return
 	}
+	query.PageSize = pageSize
 
-	c.JSON(http.StatusOK, records)
+	h.respondRecords(c, &query)
 }
 
 // SearchDomainRecordsByRecordId godoc
@@ -149,33 +156,21 @@ func (h *DNSHandler) SearchDomainRecords(c *gin.Context) {
 // @Param        domain      path      string  true   "域名"
 // @Param        record_id   path      string  true   "解析记录ID"
 // @Success      200    {object}   service.DomainRecord
-// @Failure      400    {object}  string
-// @Failure      404    {object}  string
-// @Failure      500    {object}  string
+// @Failure      400    {object}  apperror.Response
+// @Failure      404    {object}  apperror.Response
+// @Failure      500    {object}  apperror.Response
 // @Router       /domains/{domain}/records/id/{record_id} [get]
 func (h *DNSHandler) SearchDomainRecordsByRecordId(c *gin.Context) {
 	domain := c.Param("domain")
 	recordId := c.Param("record_id")
 
 	if domain == "" || recordId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "域名和记录ID不能为空"})
+		respondError(c, apperror.BadRequest("域名和记录ID不能为空"))
 		return
 	}
 
-	record, err := h.dnsService.GetDomainRecordById(recordId)
-	if err != nil {
-		if strings.Contains(err.Error(), "DomainRecordNotFound") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "解析记录不存在"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// 验证记录是否属于指定域名
-	recordDomain := record.RR + "." + domain
-	if !strings.HasSuffix(recordDomain, domain) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "解析记录不属于指定域名"})
+	record, ok := h.getDomainRecord(c, domain, recordId)
+	if !ok {
 		return
 	}
 
@@ -188,39 +183,41 @@ func (h *DNSHandler) SearchDomainRecordsByRecordId(c *gin.Context) {
 // @Tags         record-query
 // @Accept       json
 // @Produce      json
+// @Produce      application/x-ndjson
 // @Param        domain   path      string  true   "域名"
 // @Param        rr       path      string  true   "主机记录"
+// @Param        page     query     integer false  "页码，指定后返回分页信封"  minimum(1)
+// @Param        per_page query     integer false  "每页返回的记录数，默认100"  minimum(1)  maximum(500)
+// @Param        cursor   query     string  false  "上一页返回的next_cursor，需与原查询条件一起使用"
+// @Param        sort     query     string  false  "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl"
+// @Param        format   query     string  false  "输出格式(json/ndjson)，ndjson时逐行流式输出"
 // @Success      200    {array}   service.DomainRecord
-// @Failure      400    {object}  string
-// @Failure      500    {object}  string
+// @Failure      400    {object}  apperror.Response
+// @Failure      500    {object}  apperror.Response
 // @Router       /domains/{domain}/records/rr/{rr} [get]
 func (h *DNSHandler) SearchDomainRecordsByRR(c *gin.Context) {
 	domain := c.Param("domain")
 	rr := c.Param("rr")
 
 	if domain == "" || rr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "域名和主机记录不能为空"})
-		return
-	}
-
-	// 验证RR的格式
-	if len(rr) > 255 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "主机记录长度不能超过255个字符"})
+		respondError(c, apperror.BadRequest("域名和主机记录不能为空"))
 		return
 	}
 
-	opts := service.SearchDomainRecordsOptions{
-		DomainName: domain,
-		RR:         rr,
+	// 验证RR的格式，国际化主机记录按 ASCII 形式校验
+	asciiRR, err := idn.ToASCII(rr)
+	if err == nil {
+		err = validation.ValidateRR(asciiRR)
 	}
-
-	records, err := h.dnsService.SearchDomainRecords(&opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperror.BadRequest(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, records)
+	h.respondRecords(c, &service.RecordQuery{
+		DomainName: domain,
+		RR:         rr,
+	})
 }
 
 // SearchDomainRecordsByType godoc
@@ -229,44 +226,43 @@ func (h *DNSHandler) SearchDomainRecordsByRR(c *gin.Context) {
 // @Tags         record-query
 // @Accept       json
 // @Produce      json
+// @Produce      application/x-ndjson
 // @Param        domain     path      string  true   "域名"
 // @Param        type       path      string  true   "记录类型"
-// @Param        page_size  query     integer false  "每页记录数，默认20"  minimum(1)  maximum(500)
+// @Param        page_size  query     integer false  "上游接口每页记录数，默认500"  minimum(1)  maximum(500)
+// @Param        page       query     integer false  "页码，指定后返回分页信封"  minimum(1)
+// @Param        per_page   query     integer false  "每页返回的记录数，默认100"  minimum(1)  maximum(500)
+// @Param        cursor     query     string  false  "上一页返回的next_cursor，需与原查询条件一起使用"
+// @Param        sort       query     string  false  "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl"
+// @Param        format     query     string  false  "输出格式(json/ndjson)，ndjson时逐行流式输出"
 // @Success      200    {array}   service.DomainRecord
-// @Failure      400    {object}  string
-// @Failure      500    {object}  string
+// @Failure      400    {object}  apperror.Response
+// @Failure      500    {object}  apperror.Response
 // @Router       /domains/{domain}/records/type/{type} [get]
 func (h *DNSHandler) SearchDomainRecordsByType(c *gin.Context) {
 	domain := c.Param("domain")
 	recordType := c.Param("type")
 
 	if domain == "" || recordType == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "域名和记录类型不能为空"})
+		respondError(c, apperror.BadRequest("域名和记录类型不能为空"))
 		return
 	}
 
-	// 解析page_size参数
-	var pageSize int64 = 0
-	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
-		var err error
-		pageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size必须是有效的整数"})
-			return
-		}
-		if pageSize < 1 || pageSize > 500 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size必须在1-500之间"})
-			return
-		}
+	if err := validation.ValidateQueryType(recordType); err != nil {
+		respondError(c, apperror.BadRequest(err.Error()))
+		return
 	}
 
-	records, err := h.dnsService.GetDomainRecordsByType(domain, recordType, pageSize)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	pageSize, ok := parsePageSize(c)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, records)
+	h.respondRecords(c, &service.RecordQuery{
+		DomainName: domain,
+		Type:       recordType,
+		PageSize:   pageSize,
+	})
 }
 
 // SearchDomainRecordsByStatus godoc
@@ -275,47 +271,103 @@ func (h *DNSHandler) SearchDomainRecordsByType(c *gin.Context) {
 // @Tags         record-query
 // @Accept       json
 // @Produce      json
+// @Produce      application/x-ndjson
 // @Param        domain     path      string  true   "域名"
 // @Param        status     path      string  true   "状态(Enable/Disable)"
-// @Param        page_size  query     integer false  "每页记录数，默认20"  minimum(1)  maximum(500)
+// @Param        page_size  query     integer false  "上游接口每页记录数，默认500"  minimum(1)  maximum(500)
+// @Param        page       query     integer false  "页码，指定后返回分页信封"  minimum(1)
+// @Param        per_page   query     integer false  "每页返回的记录数，默认100"  minimum(1)  maximum(500)
+// @Param        cursor     query     string  false  "上一页返回的next_cursor，需与原查询条件一起使用"
+// @Param        sort       query     string  false  "排序字段，逗号分隔，前缀-表示降序，可选rr/type/ttl/value，如rr,-ttl"
+// @Param        format     query     string  false  "输出格式(json/ndjson)，ndjson时逐行流式输出"
 // @Success      200    {array}   service.DomainRecord
-// @Failure      400    {object}  string
-// @Failure      500    {object}  string
+// @Failure      400    {object}  apperror.Response
+// @Failure      500    {object}  apperror.Response
 // @Router       /domains/{domain}/records/status/{status} [get]
 func (h *DNSHandler) SearchDomainRecordsByStatus(c *gin.Context) {
 	domain := c.Param("domain")
 	status := c.Param("status")
 
 	if domain == "" || status == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "域名和状态不能为空"})
+		respondError(c, apperror.BadRequest("域名和状态不能为空"))
 		return
 	}
 
 	if status != "Enable" && status != "Disable" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "状态必须是Enable或Disable"})
+		respondError(c, apperror.BadRequest("状态必须是Enable或Disable"))
+		return
+	}
+
+	pageSize, ok := parsePageSize(c)
+	if !ok {
 		return
 	}
 
-	// 解析page_size参数
-	var pageSize int64 = 0
-	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
-		var err error
-		pageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+	h.respondRecords(c, &service.RecordQuery{
+		DomainName: domain,
+		Status:     status,
+		PageSize:   pageSize,
+	})
+}
+
+// SearchAllDomainRecords godoc
+// @Summary      跨域名搜索解析记录
+// @Description  在账户下所有域名中搜索解析记录，结果按域名分组，单个域名查询失败时在该域名的结果中返回错误
+// @Tags         record-query
+// @Accept       json
+// @Produce      json
+// @Param        value        query     string   false  "记录值关键字"
+// @Param        value_exact  query     boolean  false  "记录值是否精确匹配"
+// @Param        type         query     string   false  "记录类型"
+// @Param        rr           query     string   false  "主机记录，支持*和?通配符"
+// @Param        status       query     string   false  "状态(Enable/Disable)"
+// @Param        concurrency  query     integer  false  "并发查询的域名数，默认5"  minimum(1)  maximum(20)
+// @Success      200    {object}  service.CrossDomainResult
+// @Failure      400    {object}  apperror.Response
+// @Failure      500    {object}  apperror.Response
+// @Router       /records/search [get]
+func (h *DNSHandler) SearchAllDomainRecords(c *gin.Context) {
+	query := service.CrossDomainQuery{
+		Value:      c.Query("value"),
+		ValueExact: c.Query("value_exact") == "true",
+		Type:       c.Query("type"),
+		RRPattern:  c.Query("rr"),
+		Status:     c.Query("status"),
+	}
+
+	if concurrencyStr := c.Query("concurrency"); concurrencyStr != "" {
+		concurrency, err := strconv.Atoi(concurrencyStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size必须是有效的整数"})
-			return
-		}
-		if pageSize < 1 || pageSize > 500 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size必须在1-500之间"})
+			respondError(c, apperror.BadRequest("concurrency必须是有效的整数"))
 			return
 		}
+		query.Concurrency = concurrency
 	}
 
-	records, err := h.dnsService.GetDomainRecordsByStatus(domain, status, pageSize)
+	result, err := h.dnsService.SearchAllDomains(&query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, records)
+	c.JSON(http.StatusOK, result)
+}
+
+// parsePageSize 解析上游接口每页记录数 page_size，失败时直接写入错误响应
+func parsePageSize(c *gin.Context) (int64, bool) {
+	pageSizeStr := c.Query("page_size")
+	if pageSizeStr == "" {
+		return 0, true
+	}
+
+	pageSize, err := strconv.ParseInt(pageSizeStr, 10, 64)
+	if err != nil {
+		respondError(c, apperror.BadRequest("page_size必须是有效的整数"))
+		return 0, false
+	}
+	if pageSize < 1 || pageSize > service.MaxRecordQueryPageSize {
+		respondError(c, apperror.BadRequest("page_size必须在1-500之间"))
+		return 0, false
+	}
+	return pageSize, true
 }
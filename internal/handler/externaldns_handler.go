@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/externaldns"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExternalDNSHandler 实现 external-dns 的 webhook 协议
+type ExternalDNSHandler struct {
+	provider *externaldns.Provider
+}
+
+// NewExternalDNSHandler 创建 external-dns webhook 处理器
+func NewExternalDNSHandler(provider *externaldns.Provider) *ExternalDNSHandler {
+	return &ExternalDNSHandler{
+		provider: provider,
+	}
+}
+
+// Negotiate godoc
+// @Summary      external-dns 协商
+// @Description  返回 webhook 管理的域名过滤条件
+// @Tags         external-dns
+// @Produce      json
+// @Success      200  {object}  externaldns.DomainFilter
+// @Router       /external-dns [get]
+func (h *ExternalDNSHandler) Negotiate(c *gin.Context) {
+	h.respond(c, http.StatusOK, h.provider.DomainFilter())
+}
+
+// Records godoc
+// @Summary      external-dns 获取记录
+// @Description  返回过滤范围内的所有端点，同一名称和类型的多条记录合并为一个端点
+// @Tags         external-dns
+// @Produce      json
+// @Success      200  {array}   externaldns.Endpoint
+// @Failure      500  {object}  apperror.Response
+// @Router       /external-dns/records [get]
+func (h *ExternalDNSHandler) Records(c *gin.Context) {
+	endpoints, err := h.provider.Records()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, endpoints)
+}
+
+// ApplyChanges godoc
+// @Summary      external-dns 执行变更
+// @Description  按删除、更新、创建的顺序执行 external-dns 计划的变更
+// @Tags         external-dns
+// @Accept       json
+// @Produce      json
+// @Param        changes  body      externaldns.Changes  true  "变更"
+// @Success      204
+// @Failure      400      {object}  apperror.Response
+// @Failure      403      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /external-dns/records [post]
+func (h *ExternalDNSHandler) ApplyChanges(c *gin.Context) {
+	var changes externaldns.Changes
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	if err := h.provider.ApplyChanges(&changes); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AdjustEndpoints godoc
+// @Summary      external-dns 规范化端点
+// @Description  将期望的端点规范化为与获取记录接口一致的形式
+// @Tags         external-dns
+// @Accept       json
+// @Produce      json
+// @Param        endpoints  body      []externaldns.Endpoint  true  "端点"
+// @Success      200        {array}   externaldns.Endpoint
+// @Failure      400        {object}  apperror.Response
+// @Router       /external-dns/adjustendpoints [post]
+func (h *ExternalDNSHandler) AdjustEndpoints(c *gin.Context) {
+	var endpoints []*externaldns.Endpoint
+	if err := c.ShouldBindJSON(&endpoints); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	h.respond(c, http.StatusOK, h.provider.AdjustEndpoints(endpoints))
+}
+
+// respond 以 webhook 协议的内容类型返回响应
+func (h *ExternalDNSHandler) respond(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", externaldns.MediaType)
+	c.Header("Vary", "Content-Type")
+	c.JSON(status, body)
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"dns-update/internal/failover"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FailoverHandler 查询健康检查故障转移的状态
+type FailoverHandler struct {
+	manager *failover.Manager
+}
+
+// NewFailoverHandler 创建故障转移处理器
+func NewFailoverHandler(manager *failover.Manager) *FailoverHandler {
+	return &FailoverHandler{
+		manager: manager,
+	}
+}
+
+// ListGroups godoc
+// @Summary      获取故障转移组状态
+// @Description  返回所有故障转移组的目标健康状态和当前生效的值
+// @Tags         failover
+// @Produce      json
+// @Success      200  {array}  failover.GroupStatus
+// @Router       /failover [get]
+func (h *FailoverHandler) ListGroups(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.Status())
+}
+
+// GetGroup godoc
+// @Summary      获取单个故障转移组状态
+// @Tags         failover
+// @Produce      json
+// @Param        name  path      string  true  "故障转移组名称"
+// @Success      200   {object}  failover.GroupStatus
+// @Failure      404   {object}  apperror.Response
+// @Router       /failover/{name} [get]
+func (h *FailoverHandler) GetGroup(c *gin.Context) {
+	status, err := h.manager.GroupStatus(c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
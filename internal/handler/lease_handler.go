@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/lease"
+	"dns-update/pkg/idn"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListLeases godoc
+// @Summary      获取临时记录
+// @Description  按到期时间返回所有临时记录的租约
+// @Tags         lease
+// @Produce      json
+// @Success      200  {array}  lease.Lease
+// @Router       /leases [get]
+func (h *DNSHandler) ListLeases(c *gin.Context) {
+	c.JSON(http.StatusOK, h.leases.List())
+}
+
+// GetRecordLease godoc
+// @Summary      查询解析记录的租约
+// @Tags         lease
+// @Produce      json
+// @Param        domain     path      string  true  "域名"
+// @Param        record_id  path      string  true  "解析记录ID"
+// @Success      200        {object}  lease.Lease
+// @Failure      404        {object}  apperror.Response
+// @Router       /domains/{domain}/records/id/{record_id}/lease [get]
+func (h *DNSHandler) GetRecordLease(c *gin.Context) {
+	l, ok := h.getRecordLease(c, c.Param("domain"), c.Param("record_id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, l)
+}
+
+// SetRecordLease godoc
+// @Summary      设置解析记录的租约
+// @Description  延长或缩短临时记录的租约；记录没有租约时将其设为临时记录。
+// @Description  到期时以设置租约的调用方身份删除记录，设置时先检查策略是否允许删除
+// @Tags         lease
+// @Accept       json
+// @Produce      json
+// @Param        domain     path      string        true  "域名"
+// @Param        record_id  path      string        true  "解析记录ID"
+// @Param        expiry     body      lease.Expiry  true  "到期时间"
+// @Success      200        {object}  lease.Lease
+// @Failure      400        {object}  apperror.Response
+// @Failure      403        {object}  apperror.Response
+// @Failure      404        {object}  apperror.Response
+// @Failure      500        {object}  apperror.Response
+// @Router       /domains/{domain}/records/id/{record_id}/lease [put]
+func (h *DNSHandler) SetRecordLease(c *gin.Context) {
+	domain := c.Param("domain")
+	recordId := c.Param("record_id")
+
+	var expiry lease.Expiry
+	if err := c.ShouldBindJSON(&expiry); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+	expiresAt, err := h.leases.Resolve(&expiry)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	record, ok := h.getDomainRecord(c, domain, recordId)
+	if !ok {
+		return
+	}
+	if h.approvals != nil && h.approvals.Protects(domain, record.RR) {
+		respondError(c, apperror.Forbidden("受变更审批保护的记录不支持设置到期时间"))
+		return
+	}
+
+	l, err := h.leases.Track(domain, record, expiresAt, serviceActor(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, l)
+}
+
+// DeleteRecordLease godoc
+// @Summary      移除解析记录的租约
+// @Description  移除后记录不再自动删除
+// @Tags         lease
+// @Param        domain     path  string  true  "域名"
+// @Param        record_id  path  string  true  "解析记录ID"
+// @Success      204
+// @Failure      404  {object}  apperror.Response
+// @Router       /domains/{domain}/records/id/{record_id}/lease [delete]
+func (h *DNSHandler) DeleteRecordLease(c *gin.Context) {
+	l, ok := h.getRecordLease(c, c.Param("domain"), c.Param("record_id"))
+	if !ok {
+		return
+	}
+	if !h.leases.Release(l.RecordId) {
+		respondError(c, apperror.NotFound("解析记录没有租约"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getRecordLease 查询租约并确认其属于指定域名，失败时直接写入错误响应
+func (h *DNSHandler) getRecordLease(c *gin.Context, domain, recordId string) (*lease.Lease, bool) {
+	l, err := h.leases.Get(recordId)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	if !idn.Equal(l.Domain, domain) {
+		respondError(c, apperror.NotFound("解析记录不属于指定域名"))
+		return nil, false
+	}
+
+	return l, true
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRecordLines godoc
+// @Summary      获取解析线路
+// @Description  获取域名版本支持的解析线路（包括自定义线路），tree=true时按父线路组织为树
+// @Tags         line
+// @Produce      json
+// @Param        domain  path      string   true   "域名"
+// @Param        tree    query     boolean  false  "是否按树形结构返回"
+// @Success      200     {array}   service.RecordLine
+// @Failure      400     {object}  apperror.Response
+// @Failure      404     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /domains/{domain}/lines [get]
+func (h *DNSHandler) ListRecordLines(c *gin.Context) {
+	domain := c.Param("domain")
+
+	tree, err := strconv.ParseBool(c.DefaultQuery("tree", "false"))
+	if err != nil {
+		respondError(c, apperror.BadRequest("tree必须是布尔值"))
+		return
+	}
+
+	if tree {
+		lines, err := h.dnsService.RecordLineTree(domain)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if lines == nil {
+			lines = []*service.RecordLine{}
+		}
+		c.JSON(http.StatusOK, lines)
+		return
+	}
+
+	lines, err := h.dnsService.ListRecordLines(domain)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if lines == nil {
+		lines = []service.RecordLine{}
+	}
+	c.JSON(http.StatusOK, lines)
+}
+
+// GetLineRecordSet godoc
+// @Summary      获取分线路解析记录
+// @Description  将主机记录和类型下的解析记录按线路归并为一条逻辑记录
+// @Tags         line
+// @Produce      json
+// @Param        domain  path      string  true  "域名"
+// @Param        rr      path      string  true  "主机记录"
+// @Param        type    path      string  true  "记录类型"
+// @Success      200     {object}  service.LineRecordSet
+// @Failure      400     {object}  apperror.Response
+// @Failure      404     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /domains/{domain}/line-records/{rr}/{type} [get]
+func (h *DNSHandler) GetLineRecordSet(c *gin.Context) {
+	set, err := h.dnsService.GetLineRecordSet(c.Param("domain"), c.Param("rr"), c.Param("type"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+// ApplyLineRecordSet godoc
+// @Summary      设置分线路解析记录
+// @Description  按线路指定记录值，展开为各线路的解析记录并与现有记录比对后增删改，必须包含默认线路。
+// @Description  未列出的线路上的记录会被删除；中途失败时已完成的修改不会回滚，重新提交即可继续
+// @Tags         line
+// @Accept       json
+// @Produce      json
+// @Param        domain  path      string                      true  "域名"
+// @Param        rr      path      string                      true  "主机记录"
+// @Param        type    path      string                      true  "记录类型"
+// @Param        set     body      service.LineRecordSetInput  true  "各线路的记录值"
+// @Success      200     {object}  service.LineRecordSetResult
+// @Failure      400     {object}  apperror.Response
+// @Failure      404     {object}  apperror.Response
+// @Failure      409     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /domains/{domain}/line-records/{rr}/{type} [put]
+func (h *DNSHandler) ApplyLineRecordSet(c *gin.Context) {
+	var input service.LineRecordSetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	result, err := withActor(c, h.dnsService).ApplyLineRecordSet(c.Param("domain"), c.Param("rr"), c.Param("type"), &input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/lint"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LintDomain godoc
+// @Summary      检查域名解析配置
+// @Description  分析域名的解析记录，检查CNAME冲突、悬空CNAME、重复记录、缺失的SPF/DMARC/CAA以及TTL问题
+// @Tags         record-management
+// @Accept       json
+// @Produce      json
+// @Param        domain   path      string   true   "域名"
+// @Param        min_ttl  query     integer  false  "低于该值的TTL视为过低，默认60"
+// @Success      200      {object}  lint.Report
+// @Failure      400      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /domains/{domain}/lint [get]
+func (h *DNSHandler) LintDomain(c *gin.Context) {
+	domain := c.Param("domain")
+
+	opts := lint.DefaultOptions
+	if minTTLStr := c.Query("min_ttl"); minTTLStr != "" {
+		minTTL, err := strconv.ParseInt(minTTLStr, 10, 64)
+		if err != nil || minTTL < 1 {
+			respondError(c, apperror.BadRequest("min_ttl必须是正整数"))
+			return
+		}
+		opts.MinTTL = minTTL
+	}
+
+	records, err := h.dnsService.ListDomainRecords(domain, nil)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lint.Analyze(domain, records, &opts))
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/audit"
+	"dns-update/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxLevelDuration 临时调整日志级别的最长时长
+const maxLevelDuration = 24 * time.Hour
+
+// SetLogLevelRequest 设置日志级别的请求
+type SetLogLevelRequest struct {
+	Level    string `json:"level" binding:"required" example:"debug"` // debug、info、warn、error
+	Duration string `json:"duration,omitempty" example:"15m"`         // 临时调整的时长，到期后自动恢复，为空表示一直有效
+}
+
+// LogLevelHandler 处理运行时日志级别的查询和调整
+type LogLevelHandler struct {
+	audit *audit.Log
+}
+
+// NewLogLevelHandler 创建日志级别处理器
+func NewLogLevelHandler(auditLog *audit.Log) *LogLevelHandler {
+	return &LogLevelHandler{
+		audit: auditLog,
+	}
+}
+
+// ListLogLevels godoc
+// @Summary      获取日志级别
+// @Description  返回全局（root）和各组件的日志级别，组件未单独设置时跟随全局级别
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   logger.LevelStatus
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /admin/log-levels [get]
+func (h *LogLevelHandler) ListLogLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, logger.Levels())
+}
+
+// SetLogLevel godoc
+// @Summary      设置日志级别
+// @Description  设置全局（root）或组件的日志级别，指定 duration 时为临时调整，到期后自动恢复到调整前的级别
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        component  path      string              true  "组件(root/handler/service/scheduler)"
+// @Param        request    body      SetLogLevelRequest  true  "日志级别"
+// @Success      200        {object}  logger.LevelStatus
+// @Failure      400        {object}  apperror.Response
+// @Failure      401        {object}  apperror.Response
+// @Failure      403        {object}  apperror.Response
+// @Failure      404        {object}  apperror.Response
+// @Router       /admin/log-levels/{component} [put]
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		respondError(c, apperror.Invalid(apperror.FieldError{Field: "level", Message: "日志级别无效，可选 debug、info、warn、error"}))
+		return
+	}
+	var duration time.Duration
+	if req.Duration != "" {
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 || duration > maxLevelDuration {
+			respondError(c, apperror.Invalid(apperror.FieldError{
+				Field:   "duration",
+				Message: fmt.Sprintf("时长必须是 0 到 %s 之间的时间，如 15m", maxLevelDuration),
+			}))
+			return
+		}
+	}
+
+	status, err := logger.SetLevel(c.Param("component"), level, duration)
+	if err != nil {
+		respondLevelError(c, err)
+		return
+	}
+
+	detail := map[string]string{"level": status.Level}
+	if status.Until != nil {
+		detail["until"] = status.Until.Format(time.RFC3339)
+	}
+	recordAudit(c, h.audit, "logging.level", "", status.Component, detail)
+	c.JSON(http.StatusOK, status)
+}
+
+// ResetLogLevel godoc
+// @Summary      重置日志级别
+// @Description  取消临时调整，组件恢复为跟随全局级别，全局级别恢复为配置文件中的级别
+// @Tags         admin
+// @Produce      json
+// @Param        component  path      string  true  "组件(root/handler/service/scheduler)"
+// @Success      200        {object}  logger.LevelStatus
+// @Failure      401        {object}  apperror.Response
+// @Failure      403        {object}  apperror.Response
+// @Failure      404        {object}  apperror.Response
+// @Router       /admin/log-levels/{component} [delete]
+func (h *LogLevelHandler) ResetLogLevel(c *gin.Context) {
+	status, err := logger.ResetLevel(c.Param("component"))
+	if err != nil {
+		respondLevelError(c, err)
+		return
+	}
+
+	recordAudit(c, h.audit, "logging.level_reset", "", status.Component, map[string]string{"level": status.Level})
+	c.JSON(http.StatusOK, status)
+}
+
+// respondLevelError 组件不存在时返回404
+func respondLevelError(c *gin.Context, err error) {
+	if errors.Is(err, logger.ErrUnknownComponent) {
+		respondError(c, apperror.NotFound(err.Error()))
+		return
+	}
+	respondError(c, err)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/audit"
+	"dns-update/internal/migration"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationHandler 处理 TTL 迁移流程相关的请求
+type MigrationHandler struct {
+	manager *migration.Manager
+	audit   *audit.Log
+}
+
+// NewMigrationHandler 创建迁移处理器
+func NewMigrationHandler(manager *migration.Manager, auditLog *audit.Log) *MigrationHandler {
+	return &MigrationHandler{
+		manager: manager,
+		audit:   auditLog,
+	}
+}
+
+// CreateMigration godoc
+// @Summary      创建迁移
+// @Description  按顺序执行：降低TTL -> 等待原TTL的缓存过期 -> 修改记录值 -> 等待新值生效并观察 -> 恢复TTL。
+// @Description  状态保存在数据目录中，服务重启后从当前阶段继续。
+// @Description  迁移以创建者的身份执行，创建时先做策略检查，不允许修改的记录返回403
+// @Tags         migration
+// @Accept       json
+// @Produce      json
+// @Param        domain   path      string                   true  "域名"
+// @Param        request  body      migration.CreateRequest  true  "迁移计划"
+// @Success      201      {object}  migration.Migration
+// @Failure      400      {object}  apperror.Response
+// @Failure      403      {object}  apperror.Response
+// @Failure      404      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /domains/{domain}/migrations [post]
+func (h *MigrationHandler) CreateMigration(c *gin.Context) {
+	var req migration.CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	mig, err := h.manager.Create(c.Param("domain"), &req, serviceActor(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/migrations/"+mig.Id)
+	c.JSON(http.StatusCreated, mig)
+}
+
+// ListMigrations godoc
+// @Summary      获取迁移
+// @Tags         migration
+// @Produce      json
+// @Success      200  {array}  migration.Migration
+// @Router       /migrations [get]
+func (h *MigrationHandler) ListMigrations(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.List())
+}
+
+// GetMigration godoc
+// @Summary      查询迁移
+// @Tags         migration
+// @Produce      json
+// @Param        migration_id  path      string  true  "迁移ID"
+// @Success      200           {object}  migration.Migration
+// @Failure      404           {object}  apperror.Response
+// @Router       /migrations/{migration_id} [get]
+func (h *MigrationHandler) GetMigration(c *gin.Context) {
+	mig, err := h.manager.Get(c.Param("migration_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mig)
+}
+
+// PauseMigration godoc
+// @Summary      暂停迁移
+// @Description  暂停后不再进入下一阶段，正在等待的时间照常计算
+// @Tags         migration
+// @Produce      json
+// @Param        migration_id  path      string  true  "迁移ID"
+// @Success      200           {object}  migration.Migration
+// @Failure      401           {object}  apperror.Response
+// @Failure      404           {object}  apperror.Response
+// @Failure      409           {object}  apperror.Response
+// @Router       /migrations/{migration_id}/pause [post]
+func (h *MigrationHandler) PauseMigration(c *gin.Context) {
+	mig, err := h.manager.Pause(c.Param("migration_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	recordAudit(c, h.audit, "migration.pause", mig.Domain, mig.Id, nil)
+
+	c.JSON(http.StatusOK, mig)
+}
+
+// ResumeMigration godoc
+// @Summary      恢复迁移
+// @Description  恢复暂停或失败的迁移，失败的步骤会重新执行
+// @Tags         migration
+// @Produce      json
+// @Param        migration_id  path      string  true  "迁移ID"
+// @Success      200           {object}  migration.Migration
+// @Failure      401           {object}  apperror.Response
+// @Failure      404           {object}  apperror.Response
+// @Failure      409           {object}  apperror.Response
+// @Router       /migrations/{migration_id}/resume [post]
+func (h *MigrationHandler) ResumeMigration(c *gin.Context) {
+	mig, err := h.manager.Resume(c.Param("migration_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	recordAudit(c, h.audit, "migration.resume", mig.Domain, mig.Id, nil)
+
+	c.JSON(http.StatusOK, mig)
+}
+
+// AbortMigration godoc
+// @Summary      中止迁移
+// @Description  记录值尚未修改时恢复原TTL；已修改记录值时保持当前状态
+// @Tags         migration
+// @Produce      json
+// @Param        migration_id  path      string  true  "迁移ID"
+// @Success      200           {object}  migration.Migration
+// @Failure      401           {object}  apperror.Response
+// @Failure      404           {object}  apperror.Response
+// @Failure      409           {object}  apperror.Response
+// @Router       /migrations/{migration_id}/abort [post]
+func (h *MigrationHandler) AbortMigration(c *gin.Context) {
+	mig, err := h.manager.Abort(c.Param("migration_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	recordAudit(c, h.audit, "migration.abort", mig.Domain, mig.Id, nil)
+
+	c.JSON(http.StatusOK, mig)
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/middleware"
+	"dns-update/internal/service"
+	"dns-update/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 流式输出的内容类型
+const contentTypeNDJSON = "application/x-ndjson"
+
+// 默认每页返回的记录数
+const defaultPerPage int64 = 100
+
+// RecordListResponse 分页返回的解析记录列表
+type RecordListResponse struct {
+	Records    []service.DomainRecord `json:"records"`
+	Page       int64                  `json:"page"`
+	PerPage    int64                  `json:"per_page"`
+	Total      int64                  `json:"total"`
+	NextCursor string                 `json:"next_cursor,omitempty"` // 没有下一页时为空
+}
+
+// StreamErrorLine 流式输出中途失败时追加的最后一行
+type StreamErrorLine struct {
+	Error apperror.Response `json:"error"`
+}
+
+// listOptions 列表接口的分页、排序和输出方式
+type listOptions struct {
+	paged   bool // 是否使用分页信封返回
+	page    int64
+	perPage int64
+	sort    []service.RecordSortKey
+	stream  bool // 是否以 NDJSON 流式输出
+}
+
+// pageCursor 游标中保存的分页位置
+type pageCursor struct {
+	Page        int64  `json:"p"`
+	PerPage     int64  `json:"n"`
+	Fingerprint uint64 `json:"f"` // 查询条件指纹，防止游标用于不同的查询
+}
+
+// parseListOptions 解析 page/per_page/cursor/sort/format 参数
+func parseListOptions(c *gin.Context) (*listOptions, error) {
+	opts := &listOptions{page: 1, perPage: defaultPerPage}
+
+	sort, err := service.ParseRecordSort(c.Query("sort"))
+	if err != nil {
+		return nil, err
+	}
+	opts.sort = sort
+
+	format := c.Query("format")
+	switch {
+	case format == "ndjson":
+		opts.stream = true
+	case format == "" || format == "json":
+		opts.stream = format == "" && strings.Contains(c.GetHeader("Accept"), contentTypeNDJSON)
+	default:
+		return nil, apperror.BadRequest("format必须是json或ndjson")
+	}
+
+	cursor := c.Query("cursor")
+	pageStr := c.Query("page")
+	perPageStr := c.Query("per_page")
+	if cursor != "" && pageStr != "" {
+		return nil, apperror.BadRequest("cursor和page不能同时指定")
+	}
+
+	if perPageStr != "" {
+		perPage, err := strconv.ParseInt(perPageStr, 10, 64)
+		if err != nil || perPage < 1 || perPage > service.MaxRecordQueryPageSize {
+			return nil, apperror.BadRequest("per_page必须在1-500之间")
+		}
+		opts.perPage = perPage
+		opts.paged = true
+	}
+	if pageStr != "" {
+		page, err := strconv.ParseInt(pageStr, 10, 64)
+		if err != nil || page < 1 || page > service.MaxRecordQueryPage {
+			return nil, apperror.BadRequest(fmt.Sprintf("page必须是1-%d之间的整数", service.MaxRecordQueryPage))
+		}
+		opts.page = page
+		opts.paged = true
+	}
+	if cursor != "" {
+		pc, err := decodeCursor(cursor)
+		if err != nil || pc.Fingerprint != queryFingerprint(c) {
+			return nil, apperror.BadRequest("cursor无效或与当前查询条件不匹配")
+		}
+		if perPageStr != "" && pc.PerPage != opts.perPage {
+			return nil, apperror.BadRequest("per_page与cursor不一致")
+		}
+		opts.page = pc.Page
+		opts.perPage = pc.PerPage
+		opts.paged = true
+	}
+
+	if opts.stream && (opts.paged || len(opts.sort) > 0) {
+		return nil, apperror.BadRequest("流式输出不支持分页和排序")
+	}
+	return opts, nil
+}
+
+// respondRecords 按列表参数返回解析记录：
+// 默认返回完整数组；指定 page/per_page/cursor 时返回分页信封；指定 format=ndjson 时流式输出
+func (h *DNSHandler) respondRecords(c *gin.Context, query *service.RecordQuery) {
+	opts, err := parseListOptions(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	switch {
+	case opts.stream:
+		h.streamRecords(c, query)
+
+	case opts.paged:
+		page, err := h.dnsService.QueryDomainRecordsPage(query, opts.page, opts.perPage, opts.sort)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		resp := RecordListResponse{
+			Records: page.Records,
+			Page:    opts.page,
+			PerPage: opts.perPage,
+			Total:   page.Total,
+		}
+		if opts.page*opts.perPage < page.Total {
+			resp.NextCursor = encodeCursor(pageCursor{
+				Page:        opts.page + 1,
+				PerPage:     opts.perPage,
+				Fingerprint: queryFingerprint(c),
+			})
+		}
+		c.JSON(http.StatusOK, resp)
+
+	default:
+		records, err := h.dnsService.QueryDomainRecords(query)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		service.SortRecords(records, opts.sort)
+		c.JSON(http.StatusOK, records)
+	}
+}
+
+// streamRecords 以 NDJSON 格式逐条输出解析记录
+//
+// 第一条记录到达前失败时返回普通的错误响应；输出开始后失败时追加一行错误信息
+func (h *DNSHandler) streamRecords(c *gin.Context, query *service.RecordQuery) {
+	started := false
+	start := func() {
+		if !started {
+			c.Header("Content-Type", contentTypeNDJSON)
+			c.Status(http.StatusOK)
+			started = true
+		}
+	}
+
+	encoder := json.NewEncoder(c.Writer)
+	err := h.dnsService.StreamDomainRecords(query, func(record *service.DomainRecord) error {
+		// 客户端断开后停止获取后续分页
+		if err := c.Request.Context().Err(); err != nil {
+			return err
+		}
+		start()
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+
+	if err == nil {
+		start()
+		return
+	}
+	if !started {
+		respondError(c, err)
+		return
+	}
+
+	appErr := apperror.From(err)
+	requestId := middleware.GetRequestId(c)
+	logger.Named(logger.ComponentHandler).Error("流式输出解析记录中断",
+		zap.String("request_id", requestId),
+		zap.String("path", c.Request.URL.Path),
+		zap.Error(err),
+	)
+	_ = encoder.Encode(StreamErrorLine{Error: appErr.ToResponse(requestId)})
+	c.Writer.Flush()
+}
+
+// queryFingerprint 计算除分页参数外的查询条件指纹
+func queryFingerprint(c *gin.Context) uint64 {
+	values := url.Values{}
+	for key, v := range c.Request.URL.Query() {
+		switch key {
+		case "cursor", "page", "per_page":
+			continue
+		}
+		values[key] = v
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(c.Request.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(values.Encode()))
+	return h.Sum64()
+}
+
+// encodeCursor 将分页位置编码为不透明的游标
+func encodeCursor(pc pageCursor) string {
+	data, _ := json.Marshal(pc)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 解析游标
+func decodeCursor(cursor string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var pc pageCursor
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return nil, err
+	}
+	if pc.Page < 1 || pc.Page > service.MaxRecordQueryPage || pc.PerPage < 1 || pc.PerPage > service.MaxRecordQueryPageSize {
+		return nil, apperror.BadRequest("cursor无效")
+	}
+	return &pc, nil
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/batch"
+	"dns-update/internal/lease"
+	"dns-update/internal/service"
+	"dns-update/pkg/idn"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateRecordRequest 添加解析记录的请求，指定到期时间时记录到期后自动删除
+type CreateRecordRequest struct {
+	service.DomainRecordInput
+	lease.Expiry
+}
+
+// CreateRecordResponse 添加解析记录的响应
+type CreateRecordResponse struct {
+	RecordId  string     `json:"record_id"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// SetRecordStatusRequest 设置解析记录状态的请求
+type SetRecordStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// statusActions 解析记录状态对应的批量操作类型，用于提交变更请求
+var statusActions = map[string]batch.Action{
+	service.RecordStatusEnable:  batch.ActionEnable,
+	service.RecordStatusDisable: batch.ActionDisable,
+}
+
+// CreateDomainRecord godoc
+// @Summary      添加解析记录
+// @Description  为指定域名添加解析记录，提交前按记录类型校验记录值和解析线路。
+// @Description  指定 expires_at 或 ttl_minutes 时记录为临时记录，到期后自动删除。
+// @Description  受变更审批保护的记录转为待审批的变更请求，返回202
+// @Tags         record-management
+// @Accept       json
+// @Produce      json
+// @Param        domain  path      string               true  "域名"
+// @Param        record  body      CreateRecordRequest  true  "解析记录"
+// @Success      201     {object}  CreateRecordResponse
+// @Success      202     {object}  approval.Change
+// @Failure      400     {object}  apperror.Response
+// @Failure      409     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /domains/{domain}/records [post]
+func (h *DNSHandler) CreateDomainRecord(c *gin.Context) {
+	domain := c.Param("domain")
+
+	var req CreateRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	// 先校验到期时间，避免添加记录后才发现租约无效
+	var expiresAt time.Time
+	if !req.Expiry.IsZero() {
+		if h.approvals != nil && h.approvals.Protects(domain, req.RR) {
+			respondError(c, apperror.BadRequest("受变更审批保护的记录不支持设置到期时间"))
+			return
+		}
+		var err error
+		if expiresAt, err = h.leases.Resolve(&req.Expiry); err != nil {
+			respondError(c, err)
+			return
+		}
+		// 到期时以调用方的身份删除记录，先检查是否允许，避免添加后才发现无法设置租约
+		if err := withActor(c, h.dnsService).CheckWrite(service.WriteDelete, domain, "", &req.DomainRecordInput); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+
+	input := &req.DomainRecordInput
+	if requestApproval(c, h.approvals, domain, &batch.Request{
+		Operations: []batch.Operation{{Action: batch.ActionCreate, Record: input}},
+	}) {
+		return
+	}
+
+	recordId, err := withActor(c, h.dnsService).AddDomainRecord(domain, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp := CreateRecordResponse{RecordId: recordId}
+	if !expiresAt.IsZero() {
+		record := &service.DomainRecord{
+			RecordId: recordId,
+			RR:       input.RR,
+			Type:     input.Type,
+			Value:    input.Value,
+		}
+		if _, err := h.leases.Track(domain, record, expiresAt, serviceActor(c)); err != nil {
+			// 租约保存失败时删除记录，避免留下不会被清理的临时记录
+			if delErr := withActor(c, h.dnsService).DeleteDomainRecord(recordId); delErr != nil {
+				respondError(c, delErr)
+				return
+			}
+			respondError(c, err)
+			return
+		}
+		resp.ExpiresAt = &expiresAt
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// UpdateDomainRecord godoc
+// @Summary      修改解析记录
+// @Description  修改指定的解析记录，提交前按记录类型校验记录值，未指定线路时保持原线路。
+// @Description  受变更审批保护的记录转为待审批的变更请求，返回202
+// @Tags         record-management
+// @Accept       json
+// @Produce      json
+// @Param        domain     path      string                     true  "域名"
+// @Param        record_id  path      string                     true  "解析记录ID"
+// @Param        record     body      service.DomainRecordInput  true  "解析记录"
+// @Success      204
+// @Success      202        {object}  approval.Change
+// @Failure      400        {object}  apperror.Response
+// @Failure      404        {object}  apperror.Response
+// @Failure      409        {object}  apperror.Response
+// @Failure      500        {object}  apperror.Response
+// @Router       /domains/{domain}/records/id/{record_id} [put]
+func (h *DNSHandler) UpdateDomainRecord(c *gin.Context) {
+	domain := c.Param("domain")
+	recordId := c.Param("record_id")
+
+	var input service.DomainRecordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	record, ok := h.getDomainRecord(c, domain, recordId)
+	if !ok {
+		return
+	}
+	if input.Line == "" {
+		// 未指定线路时保持原线路，避免被接口重置为默认线路
+		input.Line = record.Line
+	}
+	if requestApproval(c, h.approvals, domain, &batch.Request{
+		Operations: []batch.Operation{{Action: batch.ActionUpdate, RecordId: recordId, Record: &input}},
+	}, record) {
+		return
+	}
+
+	if err := withActor(c, h.dnsService).UpdateDomainRecordFrom(record, &input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetDomainRecordStatus godoc
+// @Summary      设置解析记录状态
+// @Description  启用或暂停指定的解析记录，受变更审批保护的记录转为待审批的变更请求，返回202
+// @Tags         record-management
+// @Accept       json
+// @Produce      json
+// @Param        domain     path      string                  true  "域名"
+// @Param        record_id  path      string                  true  "解析记录ID"
+// @Param        status     body      SetRecordStatusRequest  true  "状态(Enable/Disable)"
+// @Success      204
+// @Success      202        {object}  approval.Change
+// @Failure      400        {object}  apperror.Response
+// @Failure      404        {object}  apperror.Response
+// @Failure      500        {object}  apperror.Response
+// @Router       /domains/{domain}/records/id/{record_id}/status [put]
+func (h *DNSHandler) SetDomainRecordStatus(c *gin.Context) {
+	domain := c.Param("domain")
+	recordId := c.Param("record_id")
+
+	var req SetRecordStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	record, ok := h.getDomainRecord(c, domain, recordId)
+	if !ok {
+		return
+	}
+	if action, ok := statusActions[req.Status]; ok && requestApproval(c, h.approvals, domain, &batch.Request{
+		Operations: []batch.Operation{{Action: action, RecordId: recordId}},
+	}, record) {
+		return
+	}
+
+	if err := withActor(c, h.dnsService).SetDomainRecordStatus(recordId, req.Status); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteDomainRecord godoc
+// @Summary      删除解析记录
+// @Description  删除指定的解析记录，受变更审批保护的记录转为待审批的变更请求，返回202
+// @Tags         record-management
+// @Accept       json
+// @Produce      json
+// @Param        domain     path      string  true  "域名"
+// @Param        record_id  path      string  true  "解析记录ID"
+// @Success      204
+// @Success      202        {object}  approval.Change
+// @Failure      400        {object}  apperror.Response
+// @Failure      404        {object}  apperror.Response
+// @Failure      500        {object}  apperror.Response
+// @Router       /domains/{domain}/records/id/{record_id} [delete]
+func (h *DNSHandler) DeleteDomainRecord(c *gin.Context) {
+	domain := c.Param("domain")
+	recordId := c.Param("record_id")
+
+	record, ok := h.getDomainRecord(c, domain, recordId)
+	if !ok {
+		return
+	}
+	if requestApproval(c, h.approvals, domain, &batch.Request{
+		Operations: []batch.Operation{{Action: batch.ActionDelete, RecordId: recordId}},
+	}, record) {
+		return
+	}
+
+	if err := withActor(c, h.dnsService).DeleteDomainRecord(recordId); err != nil {
+		respondError(c, err)
+		return
+	}
+	h.leases.Release(recordId)
+
+	c.Status(http.StatusNoContent)
+}
+
+// getDomainRecord 查询解析记录并确认其属于指定域名，失败时直接写入错误响应
+func (h *DNSHandler) getDomainRecord(c *gin.Context, domain, recordId string) (*service.DomainRecord, bool) {
+	if domain == "" || recordId == "" {
+		respondError(c, apperror.BadRequest("域名和记录ID不能为空"))
+		return nil, false
+	}
+
+	record, err := h.dnsService.GetDomainRecordById(recordId)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	if record.DomainName != "" && !idn.Equal(record.DomainName, domain) {
+		respondError(c, apperror.NotFound("解析记录不属于指定域名"))
+		return nil, false
+	}
+
+	return record, true
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"dns-update/internal/apperror"
+	"dns-update/internal/middleware"
+	"dns-update/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// respondError 以统一的错误结构返回响应
+func respondError(c *gin.Context, err error) {
+	appErr := apperror.From(err)
+	requestId := middleware.GetRequestId(c)
+
+	if appErr.Status >= 500 {
+		logger.Named(logger.ComponentHandler).Error("请求处理失败",
+			zap.String("request_id", requestId),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("code", appErr.Code),
+			zap.Error(err),
+		)
+	}
+
+	c.AbortWithStatusJSON(appErr.Status, appErr.ToResponse(requestId))
+}
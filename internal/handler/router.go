@@ -2,20 +2,50 @@ package handler
 
 import (
 	"dns-update/docs"
+	"dns-update/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-// InitRouter 初始化路由配置
-func InitRouter(dnsHandler *DNSHandler) *gin.Engine {
+// Handlers 路由使用的处理器集合
+type Handlers struct {
+	DNS         *DNSHandler
+	Batch       *BatchHandler
+	SLB         *SLBHandler
+	Schedule    *ScheduleHandler
+	Migration   *MigrationHandler
+	Audit       *AuditHandler
+	LogLevel    *LogLevelHandler
+	Approval    *ApprovalHandler    // 未配置受保护记录时为 nil
+	Acme        *AcmeHandler        // 未配置 ACME 凭证时为 nil
+	ExternalDNS *ExternalDNSHandler // 未启用 external-dns webhook 时为 nil
+	Failover    *FailoverHandler    // 未配置故障转移组时为 nil
+}
+
+// InitRouter 初始化路由配置，tokens 为空时不识别调用方身份，admins 为允许访问管理接口的身份
+func InitRouter(handlers *Handlers, tokens []middleware.Token, admins []string) *gin.Engine {
+	dnsHandler := handlers.DNS
+
+	// 受保护记录的其他写操作无法转为变更请求，直接拒绝
+	guard := func(c *gin.Context) { c.Next() }
+	if handlers.Approval != nil {
+		guard = handlers.Approval.Guard
+	}
+
+	// 配置了访问令牌时，中止定时变更、迁移和流量切换任务需要已认证身份
+	authenticated := func(c *gin.Context) { c.Next() }
+	if len(tokens) > 0 {
+		authenticated = middleware.RequireIdentity(nil)
+	}
+
 	// 设置生产模式
 	gin.SetMode(gin.ReleaseMode)
 
 	// 创建 Gin 路由
 	r := gin.New()
-	r.Use(gin.Recovery())
+	r.Use(gin.Recovery(), middleware.RequestID(), middleware.Identity(tokens))
 
 	// 初始化Swagger文档
 	docs.SwaggerInfo.BasePath = "/api"
@@ -28,7 +58,9 @@ func InitRouter(dnsHandler *DNSHandler) *gin.Engine {
 		domainMgmt := api.Group("/domains")
 		{
 			// 主域名操作
-			domainMgmt.GET("", dnsHandler.ListDomains) // 获取所有域名列表
+			domainMgmt.GET("", dnsHandler.ListDomains)                   // 获取所有域名列表
+			domainMgmt.GET("/:domain/lint", dnsHandler.LintDomain)       // 检查域名解析配置
+			domainMgmt.GET("/:domain/lines", dnsHandler.ListRecordLines) // 获取解析线路
 			// TODO: 后续可以添加其他主域名相关操作，如：
 			// - 添加域名
 			// - 删除域名
@@ -36,6 +68,75 @@ func InitRouter(dnsHandler *DNSHandler) *gin.Engine {
 			// - 获取域名信息
 		}
 
+		// 自定义线路
+		customLineMgmt := domainMgmt.Group("/:domain/custom-lines")
+		{
+			customLineMgmt.GET("", dnsHandler.ListCustomLines)                     // 获取自定义线路
+			customLineMgmt.POST("", guard, dnsHandler.CreateCustomLine)            // 添加自定义线路
+			customLineMgmt.GET("/:line_id", dnsHandler.GetCustomLine)              // 查询自定义线路
+			customLineMgmt.PUT("/:line_id", guard, dnsHandler.UpdateCustomLine)    // 修改自定义线路
+			customLineMgmt.DELETE("/:line_id", guard, dnsHandler.DeleteCustomLine) // 删除自定义线路
+		}
+
+		// 分线路解析记录
+		lineMgmt := domainMgmt.Group("/:domain/line-records")
+		{
+			lineMgmt.GET("/:rr/:type", dnsHandler.GetLineRecordSet)          // 获取分线路解析记录
+			lineMgmt.PUT("/:rr/:type", guard, dnsHandler.ApplyLineRecordSet) // 设置分线路解析记录
+		}
+
+		// 权重负载均衡
+		slbMgmt := domainMgmt.Group("/:domain/slb")
+		{
+			slbMgmt.GET("", handlers.SLB.ListSubDomains)                      // 获取负载均衡子域名
+			slbMgmt.GET("/:rr/:type", handlers.SLB.GetMembers)                // 获取负载均衡成员
+			slbMgmt.PUT("/:rr/:type/status", guard, handlers.SLB.SetStatus)   // 开启或关闭负载均衡
+			slbMgmt.PUT("/:rr/:type/weights", guard, handlers.SLB.SetWeights) // 设置负载均衡权重
+			slbMgmt.POST("/:rr/:type/shifts", guard, handlers.SLB.StartShift) // 创建流量切换任务
+		}
+		api.GET("/slb/shifts", handlers.SLB.ListShifts)                             // 获取流量切换任务
+		api.GET("/slb/shifts/:shift_id", handlers.SLB.GetShift)                     // 查询流量切换任务
+		api.DELETE("/slb/shifts/:shift_id", authenticated, handlers.SLB.AbortShift) // 中止流量切换任务
+
+		// 定时变更
+		domainMgmt.POST("/:domain/schedules", guard, handlers.Schedule.CreateSchedule)    // 创建定时变更
+		api.GET("/schedules", handlers.Schedule.ListSchedules)                            // 获取定时变更
+		api.GET("/schedules/:job_id", handlers.Schedule.GetSchedule)                      // 查询定时变更
+		api.DELETE("/schedules/:job_id", authenticated, handlers.Schedule.CancelSchedule) // 取消定时变更
+
+		// TTL 迁移
+		domainMgmt.POST("/:domain/migrations", guard, handlers.Migration.CreateMigration)               // 创建迁移
+		api.GET("/migrations", handlers.Migration.ListMigrations)                                       // 获取迁移
+		api.GET("/migrations/:migration_id", handlers.Migration.GetMigration)                           // 查询迁移
+		api.POST("/migrations/:migration_id/pause", authenticated, handlers.Migration.PauseMigration)   // 暂停迁移
+		api.POST("/migrations/:migration_id/resume", authenticated, handlers.Migration.ResumeMigration) // 恢复迁移
+		api.POST("/migrations/:migration_id/abort", authenticated, handlers.Migration.AbortMigration)   // 中止迁移
+
+		// 临时记录
+		api.GET("/leases", dnsHandler.ListLeases) // 获取临时记录
+
+		// 变更审批
+		if handlers.Approval != nil {
+			api.GET("/approvals", handlers.Approval.ListApprovals)                     // 获取变更请求
+			api.GET("/approvals/:change_id", handlers.Approval.GetApproval)            // 查询变更请求
+			api.POST("/approvals/:change_id/approve", handlers.Approval.ApproveChange) // 审批通过
+			api.POST("/approvals/:change_id/reject", handlers.Approval.RejectChange)   // 拒绝
+		}
+
+		// 审计日志
+		api.GET("/audit", handlers.Audit.ListAuditEntries) // 查询审计日志
+
+		// 运行时管理，需要已认证身份
+		admin := api.Group("/admin", middleware.RequireIdentity(admins))
+		{
+			admin.GET("/log-levels", handlers.LogLevel.ListLogLevels)               // 获取日志级别
+			admin.PUT("/log-levels/:component", handlers.LogLevel.SetLogLevel)      // 设置日志级别
+			admin.DELETE("/log-levels/:component", handlers.LogLevel.ResetLogLevel) // 重置日志级别
+		}
+
+		// 跨域名查询
+		api.GET("/records/search", dnsHandler.SearchAllDomainRecords) // 跨域名搜索解析记录
+
 		// 解析记录管理路由组
 		recordMgmt := domainMgmt.Group("/:domain/records")
 		{
@@ -57,11 +158,44 @@ func InitRouter(dnsHandler *DNSHandler) *gin.Engine {
 				attrQuery.GET("/status/:status", dnsHandler.SearchDomainRecordsByStatus) // 按记录状态查询
 			}
 
-			// TODO: 后续可以添加解析记录的修改操作，如：
-			// - 添加解析记录
-			// - 修改解析记录
-			// - 删除解析记录
-			// - 设置解析记录状态
+			// 修改操作
+			recordMgmt.POST("", dnsHandler.CreateDomainRecord)                        // 添加解析记录
+			recordMgmt.PUT("/id/:record_id", dnsHandler.UpdateDomainRecord)           // 修改解析记录
+			recordMgmt.DELETE("/id/:record_id", dnsHandler.DeleteDomainRecord)        // 删除解析记录
+			recordMgmt.PUT("/id/:record_id/status", dnsHandler.SetDomainRecordStatus) // 设置解析记录状态
+
+			// 临时记录
+			recordMgmt.GET("/id/:record_id/lease", dnsHandler.GetRecordLease)       // 查询租约
+			recordMgmt.PUT("/id/:record_id/lease", dnsHandler.SetRecordLease)       // 设置租约
+			recordMgmt.DELETE("/id/:record_id/lease", dnsHandler.DeleteRecordLease) // 移除租约
+
+			// 批量操作
+			recordMgmt.POST("/batch", handlers.Batch.ExecuteBatch)       // 批量操作解析记录
+			recordMgmt.GET("/batch/:job_id", handlers.Batch.GetBatchJob) // 查询批量操作任务
+		}
+
+		// ACME DNS-01 验证
+		if handlers.Acme != nil {
+			api.POST("/acme/httpreq/present", handlers.Acme.Present) // lego httpreq
+			api.POST("/acme/httpreq/cleanup", handlers.Acme.Cleanup) // lego httpreq
+			api.POST("/acme-dns/update", handlers.Acme.Update)       // acme-dns
+		}
+
+		// external-dns webhook
+		if handlers.ExternalDNS != nil {
+			webhook := api.Group("/external-dns")
+			{
+				webhook.GET("", handlers.ExternalDNS.Negotiate)                        // 协商
+				webhook.GET("/records", handlers.ExternalDNS.Records)                  // 获取记录
+				webhook.POST("/records", handlers.ExternalDNS.ApplyChanges)            // 执行变更
+				webhook.POST("/adjustendpoints", handlers.ExternalDNS.AdjustEndpoints) // 规范化端点
+			}
+		}
+
+		// 健康检查故障转移
+		if handlers.Failover != nil {
+			api.GET("/failover", handlers.Failover.ListGroups)     // 获取故障转移组状态
+			api.GET("/failover/:name", handlers.Failover.GetGroup) // 获取单个故障转移组状态
 		}
 	}
 
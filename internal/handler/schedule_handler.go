@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/audit"
+	"dns-update/internal/schedule"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleHandler 处理定时变更相关的请求
+type ScheduleHandler struct {
+	manager *schedule.Manager
+	audit   *audit.Log
+}
+
+// NewScheduleHandler 创建定时变更处理器
+func NewScheduleHandler(manager *schedule.Manager, auditLog *audit.Log) *ScheduleHandler {
+	return &ScheduleHandler{
+		manager: manager,
+		audit:   auditLog,
+	}
+}
+
+// CreateSchedule godoc
+// @Summary      创建定时变更
+// @Description  在指定时间执行一组解析记录操作（与批量操作格式相同），限流或上游临时错误时按配置重试，
+// @Description  执行结果通过通知发送。任务保存在数据目录中，服务重启后继续等待执行。
+// @Description  任务以创建者的身份执行，创建时先做策略检查，不允许的操作返回403
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Param        domain   path      string                  true  "域名"
+// @Param        request  body      schedule.CreateRequest  true  "定时变更"
+// @Success      201      {object}  schedule.Job
+// @Failure      400      {object}  apperror.Response
+// @Failure      403      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /domains/{domain}/schedules [post]
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req schedule.CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	job, err := h.manager.Create(c.Param("domain"), &req, serviceActor(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/schedules/"+job.Id)
+	c.JSON(http.StatusCreated, job)
+}
+
+// ListSchedules godoc
+// @Summary      获取定时变更
+// @Description  按执行时间返回定时变更，可按状态和域名过滤
+// @Tags         schedule
+// @Produce      json
+// @Param        status  query     string  false  "状态(scheduled/running/succeeded/failed/canceled)"
+// @Param        domain  query     string  false  "域名"
+// @Success      200     {array}   schedule.Job
+// @Router       /schedules [get]
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.List(c.Query("status"), c.Query("domain")))
+}
+
+// GetSchedule godoc
+// @Summary      查询定时变更
+// @Tags         schedule
+// @Produce      json
+// @Param        job_id  path      string  true  "任务ID"
+// @Success      200     {object}  schedule.Job
+// @Failure      404     {object}  apperror.Response
+// @Router       /schedules/{job_id} [get]
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	job, err := h.manager.Get(c.Param("job_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelSchedule godoc
+// @Summary      取消定时变更
+// @Description  取消尚未执行或等待重试的定时变更
+// @Tags         schedule
+// @Produce      json
+// @Param        job_id  path      string  true  "任务ID"
+// @Success      200     {object}  schedule.Job
+// @Failure      401     {object}  apperror.Response
+// @Failure      404     {object}  apperror.Response
+// @Failure      409     {object}  apperror.Response
+// @Router       /schedules/{job_id} [delete]
+func (h *ScheduleHandler) CancelSchedule(c *gin.Context) {
+	job, err := h.manager.Cancel(c.Param("job_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	recordAudit(c, h.audit, "schedule.cancel", job.Domain, job.Id, nil)
+	c.JSON(http.StatusOK, job)
+}
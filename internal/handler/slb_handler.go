@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"net/http"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/audit"
+	"dns-update/internal/service"
+	"dns-update/internal/slb"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SLBHandler 处理权重负载均衡相关的请求
+type SLBHandler struct {
+	dnsService *service.DNSService
+	manager    *slb.Manager
+	audit      *audit.Log
+}
+
+// NewSLBHandler 创建负载均衡处理器
+func NewSLBHandler(dnsService *service.DNSService, manager *slb.Manager, auditLog *audit.Log) *SLBHandler {
+	return &SLBHandler{
+		dnsService: dnsService,
+		manager:    manager,
+		audit:      auditLog,
+	}
+}
+
+// SetSLBStatusRequest 开启或关闭负载均衡的请求
+type SetSLBStatusRequest struct {
+	Open bool   `json:"open"`
+	Line string `json:"line"` // 解析线路，为空表示默认线路
+}
+
+// SetSLBWeightsRequest 设置负载均衡权重的请求
+type SetSLBWeightsRequest struct {
+	Weights map[string]int32 `json:"weights"` // 按记录值指定权重(1-100)
+}
+
+// ListSubDomains godoc
+// @Summary      获取负载均衡子域名
+// @Description  获取域名下开启过权重负载均衡的子域名及其状态
+// @Tags         slb
+// @Produce      json
+// @Param        domain  path      string  true   "域名"
+// @Param        rr      query     string  false  "主机记录"
+// @Success      200     {array}   service.SLBSubDomain
+// @Failure      400     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /domains/{domain}/slb [get]
+func (h *SLBHandler) ListSubDomains(c *gin.Context) {
+	subDomains, err := h.dnsService.ListSLBSubDomains(c.Param("domain"), c.Query("rr"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if subDomains == nil {
+		subDomains = []service.SLBSubDomain{}
+	}
+
+	c.JSON(http.StatusOK, subDomains)
+}
+
+// GetMembers godoc
+// @Summary      获取负载均衡成员
+// @Description  获取子域名的负载均衡状态和各成员的权重
+// @Tags         slb
+// @Produce      json
+// @Param        domain  path      string  true  "域名"
+// @Param        rr      path      string  true  "主机记录"
+// @Param        type    path      string  true  "记录类型(A/AAAA/CNAME)"
+// @Success      200     {object}  service.SLBMembers
+// @Failure      400     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
+// @Router       /domains/{domain}/slb/{rr}/{type} [get]
+func (h *SLBHandler) GetMembers(c *gin.Context) {
+	members, err := h.dnsService.GetSLBMembers(c.Param("domain"), c.Param("rr"), c.Param("type"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// SetStatus godoc
+// @Summary      开启或关闭负载均衡
+// @Description  开启或关闭子域名的权重负载均衡，关闭后按轮询返回所有记录
+// @Tags         slb
+// @Accept       json
+// @Produce      json
+// @Param        domain   path      string               true  "域名"
+// @Param        rr       path      string               true  "主机记录"
+// @Param        type     path      string               true  "记录类型(A/AAAA/CNAME)"
+// @Param        request  body      SetSLBStatusRequest  true  "状态"
+// @Success      204
+// @Failure      400      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /domains/{domain}/slb/{rr}/{type}/status [put]
+func (h *SLBHandler) SetStatus(c *gin.Context) {
+	var req SetSLBStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	if err := withActor(c, h.dnsService).SetSLBStatus(c.Param("domain"), c.Param("rr"), c.Param("type"), req.Line, req.Open); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetWeights godoc
+// @Summary      设置负载均衡权重
+// @Description  按记录值设置成员的权重，子域名未开启负载均衡时自动开启
+// @Tags         slb
+// @Accept       json
+// @Produce      json
+// @Param        domain   path      string                true  "域名"
+// @Param        rr       path      string                true  "主机记录"
+// @Param        type     path      string                true  "记录类型(A/AAAA/CNAME)"
+// @Param        request  body      SetSLBWeightsRequest  true  "权重"
+// @Success      204
+// @Failure      400      {object}  apperror.Response
+// @Failure      404      {object}  apperror.Response
+// @Failure      500      {object}  apperror.Response
+// @Router       /domains/{domain}/slb/{rr}/{type}/weights [put]
+func (h *SLBHandler) SetWeights(c *gin.Context) {
+	var req SetSLBWeightsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	if err := h.manager.SetWeights(withActor(c, h.dnsService), c.Param("domain"), c.Param("rr"), c.Param("type"), req.Weights); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// StartShift godoc
+// @Summary      创建流量切换任务
+// @Description  在指定步数内按间隔逐步调整成员权重，目标权重为0的成员在最后一步被暂停。
+// @Description  同一子域名同时只能有一个进行中的任务，任务只保存在内存中，服务重启后不会继续。
+// @Description  每一步以创建者的身份执行，创建时先做策略检查，不允许时返回403
+// @Tags         slb
+// @Accept       json
+// @Produce      json
+// @Param        domain   path      string            true  "域名"
+// @Param        rr       path      string            true  "主机记录"
+// @Param        type     path      string            true  "记录类型(A/AAAA/CNAME)"
+// @Param        request  body      slb.ShiftRequest  true  "切换计划"
+// @Success      202      {object}  slb.Shift
+// @Failure      400      {object}  apperror.Response
+// @Failure      403      {object}  apperror.Response
+// @Failure      404      {object}  apperror.Response
+// @Failure      409      {object}  apperror.Response
+// @Router       /domains/{domain}/slb/{rr}/{type}/shifts [post]
+func (h *SLBHandler) StartShift(c *gin.Context) {
+	var req slb.ShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, apperror.BadRequest("请求体格式错误: "+err.Error()))
+		return
+	}
+
+	shift, err := h.manager.StartShift(withActor(c, h.dnsService), c.Param("domain"), c.Param("rr"), c.Param("type"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/slb/shifts/"+shift.Id)
+	c.JSON(http.StatusAccepted, shift)
+}
+
+// ListShifts godoc
+// @Summary      获取流量切换任务
+// @Tags         slb
+// @Produce      json
+// @Success      200  {array}  slb.Shift
+// @Router       /slb/shifts [get]
+func (h *SLBHandler) ListShifts(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.List())
+}
+
+// GetShift godoc
+// @Summary      查询流量切换任务
+// @Tags         slb
+// @Produce      json
+// @Param        shift_id  path      string  true  "任务ID"
+// @Success      200       {object}  slb.Shift
+// @Failure      404       {object}  apperror.Response
+// @Router       /slb/shifts/{shift_id} [get]
+func (h *SLBHandler) GetShift(c *gin.Context) {
+	shift, err := h.manager.Get(c.Param("shift_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+// AbortShift godoc
+// @Summary      中止流量切换任务
+// @Description  中止进行中的任务，已调整的权重保持不变
+// @Tags         slb
+// @Produce      json
+// @Param        shift_id  path      string  true  "任务ID"
+// @Success      200       {object}  slb.Shift
+// @Failure      401       {object}  apperror.Response
+// @Failure      404       {object}  apperror.Response
+// @Failure      409       {object}  apperror.Response
+// @Router       /slb/shifts/{shift_id} [delete]
+func (h *SLBHandler) AbortShift(c *gin.Context) {
+	shift, err := h.manager.Abort(c.Param("shift_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	recordAudit(c, h.audit, "slb.shift_abort", shift.Domain, shift.Id, map[string]string{"rr": shift.RR, "type": shift.Type})
+	c.JSON(http.StatusOK, shift)
+}
@@ -0,0 +1,357 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/notify"
+	"dns-update/internal/service"
+	"dns-update/internal/store"
+	"dns-update/internal/validation"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// StoreFile 临时记录租约的状态文件名
+const StoreFile = "leases.json"
+
+// RecordService 临时记录依赖的解析记录服务
+type RecordService interface {
+	GetDomainRecordById(recordId string) (*service.DomainRecord, error)
+	DeleteDomainRecord(recordId string) error
+	CheckWrite(action, domainName, recordId string, input *service.DomainRecordInput) error
+}
+
+// RecordServiceFor 返回以指定调用方执行写操作的服务，到期记录以设置租约的调用方身份删除
+type RecordServiceFor func(actor service.Actor) RecordService
+
+// Options 临时记录的选项
+type Options struct {
+	Interval          time.Duration // 检查到期记录的间隔
+	ReconcileInterval time.Duration // 核对记录是否已被手动删除的间隔
+	MaxDuration       time.Duration // 租约的最长时长
+}
+
+// DefaultOptions 默认的临时记录选项
+var DefaultOptions = Options{
+	Interval:          30 * time.Second,
+	ReconcileInterval: 10 * time.Minute,
+	MaxDuration:       90 * 24 * time.Hour,
+}
+
+// Expiry 租约的到期时间，ExpiresAt 和 TTLMinutes 只能指定一个
+type Expiry struct {
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`  // 到期时间，RFC3339 格式
+	TTLMinutes int64      `json:"ttl_minutes,omitempty"` // 从现在起多少分钟后到期
+}
+
+// IsZero 是否未指定到期时间
+func (e *Expiry) IsZero() bool {
+	return e.ExpiresAt == nil && e.TTLMinutes == 0
+}
+
+// Lease 临时记录的租约，到期后记录被删除
+type Lease struct {
+	RecordId  string        `json:"record_id"`
+	Domain    string        `json:"domain"`
+	RR        string        `json:"rr"`
+	Type      string        `json:"type"`
+	Value     string        `json:"value"`
+	Actor     service.Actor `json:"actor"` // 最近一次设置租约的调用方，到期时按其身份做策略检查
+	ExpiresAt time.Time     `json:"expires_at"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Manager 管理临时记录的租约并删除到期的记录，租约保存在状态文件中
+type Manager struct {
+	records  RecordServiceFor
+	notifier notify.Notifier
+	file     *store.File
+	opts     Options
+	log      *zap.Logger
+
+	mu     sync.Mutex
+	leases map[string]*Lease
+}
+
+// NewManager 创建临时记录管理器并加载已保存的租约
+func NewManager(records RecordServiceFor, notifier notify.Notifier, file *store.File, opts *Options) (*Manager, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	o := *opts
+	if o.Interval <= 0 {
+		o.Interval = DefaultOptions.Interval
+	}
+	if o.ReconcileInterval <= 0 {
+		o.ReconcileInterval = DefaultOptions.ReconcileInterval
+	}
+	if o.MaxDuration <= 0 {
+		o.MaxDuration = DefaultOptions.MaxDuration
+	}
+
+	m := &Manager{
+		records:  records,
+		notifier: notifier,
+		file:     file,
+		opts:     o,
+		log:      logger.GetLogger(),
+		leases:   make(map[string]*Lease),
+	}
+
+	var leases []*Lease
+	if err := file.Load(&leases); err != nil {
+		return nil, err
+	}
+	for _, l := range leases {
+		m.leases[l.RecordId] = l
+	}
+
+	m.log.Info("已加载临时记录租约",
+		zap.String("file", file.Path()),
+		zap.Int("count", len(leases)),
+	)
+	return m, nil
+}
+
+// Resolve 校验到期时间并换算为绝对时间
+func (m *Manager) Resolve(expiry *Expiry) (time.Time, error) {
+	var errs validation.Errors
+	now := time.Now()
+
+	var expiresAt time.Time
+	switch {
+	case expiry.ExpiresAt != nil && expiry.TTLMinutes != 0:
+		errs.Add("expires_at", errors.New("expires_at和ttl_minutes只能指定一个"))
+	case expiry.ExpiresAt != nil:
+		expiresAt = *expiry.ExpiresAt
+		if !expiresAt.After(now) {
+			errs.Add("expires_at", errors.New("到期时间必须晚于当前时间"))
+		}
+	case expiry.TTLMinutes < 0:
+		errs.Add("ttl_minutes", errors.New("ttl_minutes必须大于0"))
+	case expiry.TTLMinutes > 0:
+		expiresAt = now.Add(time.Duration(expiry.TTLMinutes) * time.Minute)
+	default:
+		errs.Add("expires_at", errors.New("需要指定expires_at或ttl_minutes"))
+	}
+	if !expiresAt.IsZero() && expiresAt.Sub(now) > m.opts.MaxDuration {
+		errs.Add("expires_at", fmt.Errorf("租约不能超过%s", m.opts.MaxDuration))
+	}
+
+	if err := errs.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return expiresAt, nil
+}
+
+// Track 为记录设置租约，已有租约时更新到期时间。
+// 设置时以 actor 的身份检查是否允许删除该记录，不允许时拒绝设置
+func (m *Manager) Track(domain string, record *service.DomainRecord, expiresAt time.Time, actor service.Actor) (*Lease, error) {
+	if err := m.records(actor).CheckWrite(service.WriteDelete, domain, record.RecordId, nil); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.leases[record.RecordId]
+	previous := Lease{}
+	if ok {
+		previous = *l
+	} else {
+		l = &Lease{RecordId: record.RecordId, CreatedAt: now}
+		m.leases[record.RecordId] = l
+	}
+	l.Domain = domain
+	l.RR = record.RR
+	l.Type = record.Type
+	l.Value = record.Value
+	l.Actor = actor
+	l.ExpiresAt = expiresAt
+	l.UpdatedAt = now
+
+	if err := m.saveLocked(); err != nil {
+		if ok {
+			*l = previous
+		} else {
+			delete(m.leases, record.RecordId)
+		}
+		return nil, apperror.Internal(err)
+	}
+
+	m.log.Info("已设置临时记录租约",
+		zap.String("record_id", record.RecordId),
+		zap.String("domain", domain),
+		zap.Time("expires_at", expiresAt),
+	)
+	c := *l
+	return &c, nil
+}
+
+// Get 查询记录的租约
+func (m *Manager) Get(recordId string) (*Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.leases[recordId]
+	if !ok {
+		return nil, apperror.NotFound("解析记录没有租约")
+	}
+	c := *l
+	return &c, nil
+}
+
+// List 按到期时间返回所有租约
+func (m *Manager) List() []Lease {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	leases := make([]Lease, 0, len(m.leases))
+	for _, l := range m.leases {
+		leases = append(leases, *l)
+	}
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].ExpiresAt.Before(leases[j].ExpiresAt)
+	})
+	return leases
+}
+
+// Release 移除记录的租约，记录不再自动删除；没有租约时返回 false
+func (m *Manager) Release(recordId string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.leases[recordId]; !ok {
+		return false
+	}
+	delete(m.leases, recordId)
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存临时记录租约失败", zap.Error(err))
+	}
+
+	m.log.Info("已移除临时记录租约", zap.String("record_id", recordId))
+	return true
+}
+
+// Run 定期删除到期的记录并核对租约，直到 ctx 取消
+func (m *Manager) Run(ctx context.Context) {
+	reap := time.NewTicker(m.opts.Interval)
+	defer reap.Stop()
+	reconcile := time.NewTicker(m.opts.ReconcileInterval)
+	defer reconcile.Stop()
+
+	// 启动时先核对一次，清理停机期间被手动删除的记录
+	m.Reconcile()
+	m.Reap()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reap.C:
+			m.Reap()
+		case <-reconcile.C:
+			m.Reconcile()
+		}
+	}
+}
+
+// Reap 删除所有到期的记录，失败的记录在下一次检查时重试
+func (m *Manager) Reap() {
+	now := time.Now()
+	for _, l := range m.List() {
+		if l.ExpiresAt.After(now) {
+			break
+		}
+
+		err := m.records(l.Actor).DeleteDomainRecord(l.RecordId)
+		if err != nil && !apperror.IsNotFound(err) {
+			m.log.Error("删除到期的临时记录失败",
+				zap.String("record_id", l.RecordId),
+				zap.String("domain", l.Domain),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !m.forget(&l) {
+			continue
+		}
+
+		m.log.Info("已删除到期的临时记录",
+			zap.String("record_id", l.RecordId),
+			zap.String("domain", l.Domain),
+			zap.String("rr", l.RR),
+			zap.String("type", l.Type),
+		)
+		m.notifier.Notify(&notify.Event{
+			Kind:    "lease.expired",
+			Title:   "临时记录已到期删除",
+			Message: fmt.Sprintf("%s.%s 的 %s 记录 %s 已到期删除", l.RR, l.Domain, l.Type, l.Value),
+			Fields: map[string]string{
+				"record_id":  l.RecordId,
+				"domain":     l.Domain,
+				"expires_at": l.ExpiresAt.Format(time.RFC3339),
+			},
+		})
+	}
+}
+
+// Reconcile 移除记录已被手动删除的租约
+func (m *Manager) Reconcile() {
+	for _, l := range m.List() {
+		_, err := m.records(l.Actor).GetDomainRecordById(l.RecordId)
+		if err == nil {
+			continue
+		}
+		if !apperror.IsNotFound(err) {
+			m.log.Warn("核对临时记录失败",
+				zap.String("record_id", l.RecordId),
+				zap.Error(err),
+			)
+			continue
+		}
+		if m.forget(&l) {
+			m.log.Info("临时记录已被手动删除，移除租约",
+				zap.String("record_id", l.RecordId),
+				zap.String("domain", l.Domain),
+			)
+		}
+	}
+}
+
+// forget 移除租约，租约在此期间被延长时保留并返回 false
+func (m *Manager) forget(l *Lease) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.leases[l.RecordId]
+	if !ok || !current.UpdatedAt.Equal(l.UpdatedAt) {
+		return false
+	}
+	delete(m.leases, l.RecordId)
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存临时记录租约失败", zap.Error(err))
+	}
+	return true
+}
+
+// saveLocked 保存所有租约，调用方需持有锁
+func (m *Manager) saveLocked() error {
+	leases := make([]*Lease, 0, len(m.leases))
+	for _, l := range m.leases {
+		leases = append(leases, l)
+	}
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].CreatedAt.Before(leases[j].CreatedAt)
+	})
+	return m.file.Save(leases)
+}
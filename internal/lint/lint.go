@@ -0,0 +1,440 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"dns-update/internal/service"
+	"dns-update/pkg/idn"
+)
+
+// Severity 问题级别
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// 规则名称
+const (
+	RuleCNAMEConflict   = "cname-conflict"
+	RuleCNAMEAtApex     = "cname-at-apex"
+	RuleDanglingCNAME   = "dangling-cname"
+	RuleDuplicateRecord = "duplicate-record"
+	RuleDisabledShadow  = "disabled-shadow"
+	RuleMissingSPF      = "missing-spf"
+	RuleMissingDMARC    = "missing-dmarc"
+	RuleMissingCAA      = "missing-caa"
+	RuleLowTTL          = "low-ttl"
+	RuleInconsistentTTL = "inconsistent-ttl"
+)
+
+// Issue 检查发现的问题
+type Issue struct {
+	Rule      string   `json:"rule"`
+	Severity  Severity `json:"severity"`
+	RR        string   `json:"rr,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	Line      string   `json:"line,omitempty"`
+	RecordIds []string `json:"record_ids,omitempty"`
+	Message   string   `json:"message"`
+}
+
+// Report 域名检查报告
+type Report struct {
+	Domain      string           `json:"domain"`
+	RecordCount int              `json:"record_count"`
+	Summary     map[Severity]int `json:"summary"`
+	Issues      []Issue          `json:"issues"`
+}
+
+// HasErrors 报告中是否包含错误级别的问题
+func (r *Report) HasErrors() bool {
+	return r.Summary[SeverityError] > 0
+}
+
+// Options 检查选项
+type Options struct {
+	MinTTL int64 // 低于该值的TTL视为过低
+}
+
+// DefaultOptions 默认检查选项
+var DefaultOptions = Options{
+	MinTTL: 60,
+}
+
+// linter 单次检查的上下文
+type linter struct {
+	domain  string
+	opts    Options
+	records []service.DomainRecord
+	issues  []Issue
+}
+
+// Analyze 分析域名的解析记录并返回检查报告
+func Analyze(domain string, records []service.DomainRecord, opts *Options) *Report {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+
+	// 记录值均为 ASCII 形式，域名也统一转换后再比较
+	asciiDomain, err := idn.ToASCII(domain)
+	if err != nil {
+		asciiDomain = domain
+	}
+
+	l := &linter{
+		domain:  strings.ToLower(strings.TrimSuffix(asciiDomain, ".")),
+		opts:    *opts,
+		records: records,
+	}
+
+	l.checkCNAME()
+	l.checkDuplicates()
+	l.checkDisabledShadow()
+	l.checkMailAndCAA()
+	l.checkTTL()
+
+	report := &Report{
+		Domain:      domain,
+		RecordCount: len(records),
+		Summary: map[Severity]int{
+			SeverityError:   0,
+			SeverityWarning: 0,
+			SeverityInfo:    0,
+		},
+		Issues: l.issues,
+	}
+	if report.Issues == nil {
+		report.Issues = []Issue{}
+	}
+	for _, issue := range report.Issues {
+		report.Summary[issue.Severity]++
+	}
+	return report
+}
+
+// add 记录一个问题
+func (l *linter) add(issue Issue) {
+	l.issues = append(l.issues, issue)
+}
+
+// isEnabled 记录是否处于启用状态
+func isEnabled(r service.DomainRecord) bool {
+	return !strings.EqualFold(r.Status, service.RecordStatusDisable)
+}
+
+// normalizeRR 统一主机记录大小写
+func normalizeRR(rr string) string {
+	return strings.ToLower(rr)
+}
+
+// recordIds 提取记录ID
+func recordIds(records []service.DomainRecord) []string {
+	ids := make([]string, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.RecordId)
+	}
+	return ids
+}
+
+// groupBy 按键对记录分组，enabledOnly 为 true 时只统计启用的记录，返回排序后的键以保证输出稳定
+func groupBy(records []service.DomainRecord, key func(service.DomainRecord) string, enabledOnly bool) ([]string, map[string][]service.DomainRecord) {
+	groups := make(map[string][]service.DomainRecord)
+	for _, r := range records {
+		if enabledOnly && !isEnabled(r) {
+			continue
+		}
+		k := key(r)
+		groups[k] = append(groups[k], r)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, groups
+}
+
+// nameLineKey 按主机记录和线路分组
+func nameLineKey(r service.DomainRecord) string {
+	return normalizeRR(r.RR) + "|" + r.Line
+}
+
+// rrsetKey 按主机记录、类型和线路分组
+func rrsetKey(r service.DomainRecord) string {
+	return normalizeRR(r.RR) + "|" + r.Type + "|" + r.Line
+}
+
+// checkCNAME 检查CNAME与其他记录共存、apex CNAME 以及悬空的CNAME
+func (l *linter) checkCNAME() {
+	keys, groups := groupBy(l.records, nameLineKey, true)
+	for _, k := range keys {
+		group := groups[k]
+		var cnames, others []service.DomainRecord
+		for _, r := range group {
+			if r.Type == "CNAME" {
+				cnames = append(cnames, r)
+			} else {
+				others = append(others, r)
+			}
+		}
+		if len(cnames) == 0 {
+			continue
+		}
+
+		rr := group[0].RR
+		if len(others) > 0 {
+			types := make([]string, 0, len(others))
+			for _, r := range others {
+				types = append(types, r.Type)
+			}
+			l.add(Issue{
+				Rule:      RuleCNAMEConflict,
+				Severity:  SeverityError,
+				RR:        rr,
+				Type:      "CNAME",
+				Line:      group[0].Line,
+				RecordIds: recordIds(group),
+				Message:   fmt.Sprintf("CNAME记录与其他类型记录(%s)共存", strings.Join(uniqueSorted(types), ",")),
+			})
+		}
+
+		if rr == "@" {
+			l.add(Issue{
+				Rule:      RuleCNAMEAtApex,
+				Severity:  SeverityWarning,
+				RR:        rr,
+				Type:      "CNAME",
+				Line:      group[0].Line,
+				RecordIds: recordIds(cnames),
+				Message:   "根域名使用CNAME记录会与NS、MX等记录冲突",
+			})
+		}
+	}
+
+	l.checkDanglingCNAME()
+}
+
+// checkDanglingCNAME 检查指向本域名内不存在的主机名的CNAME
+func (l *linter) checkDanglingCNAME() {
+	names := make(map[string]bool)
+	wildcards := make([]string, 0)
+	for _, r := range l.records {
+		if !isEnabled(r) {
+			continue
+		}
+		rr := normalizeRR(r.RR)
+		names[rr] = true
+		if strings.HasPrefix(rr, "*") {
+			wildcards = append(wildcards, strings.TrimPrefix(rr, "*"))
+		}
+	}
+
+	for _, r := range l.records {
+		if r.Type != "CNAME" || !isEnabled(r) {
+			continue
+		}
+		target := strings.ToLower(strings.TrimSuffix(r.Value, "."))
+		rr, ok := l.relativeName(target)
+		if !ok || names[rr] || matchesWildcard(rr, wildcards) {
+			continue
+		}
+		l.add(Issue{
+			Rule:      RuleDanglingCNAME,
+			Severity:  SeverityWarning,
+			RR:        r.RR,
+			Type:      "CNAME",
+			Line:      r.Line,
+			RecordIds: []string{r.RecordId},
+			Message:   fmt.Sprintf("CNAME指向的%s在本域名下不存在", r.Value),
+		})
+	}
+}
+
+// relativeName 将本域名下的完整域名转换为主机记录
+func (l *linter) relativeName(fqdn string) (string, bool) {
+	if fqdn == l.domain {
+		return "@", true
+	}
+	if strings.HasSuffix(fqdn, "."+l.domain) {
+		return strings.TrimSuffix(fqdn, "."+l.domain), true
+	}
+	return "", false
+}
+
+// matchesWildcard 判断主机记录是否被通配符记录覆盖，suffixes 为去掉 * 后的后缀
+func matchesWildcard(rr string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if suffix == "" && rr != "@" {
+			return true
+		}
+		if suffix != "" && strings.HasSuffix(rr, suffix) && len(rr) > len(suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDuplicates 检查完全重复的记录
+func (l *linter) checkDuplicates() {
+	keys, groups := groupBy(l.records, func(r service.DomainRecord) string {
+		return rrsetKey(r) + "|" + strings.ToLower(r.Value)
+	}, false)
+	for _, k := range keys {
+		group := groups[k]
+		if len(group) < 2 {
+			continue
+		}
+		l.add(Issue{
+			Rule:      RuleDuplicateRecord,
+			Severity:  SeverityError,
+			RR:        group[0].RR,
+			Type:      group[0].Type,
+			Line:      group[0].Line,
+			RecordIds: recordIds(group),
+			Message:   fmt.Sprintf("存在%d条重复的记录，记录值为%s", len(group), group[0].Value),
+		})
+	}
+}
+
+// checkDisabledShadow 检查与启用记录同名同类型的暂停记录
+func (l *linter) checkDisabledShadow() {
+	keys, groups := groupBy(l.records, rrsetKey, false)
+	for _, k := range keys {
+		var enabled, disabled []service.DomainRecord
+		for _, r := range groups[k] {
+			if isEnabled(r) {
+				enabled = append(enabled, r)
+			} else {
+				disabled = append(disabled, r)
+			}
+		}
+		if len(enabled) == 0 || len(disabled) == 0 {
+			continue
+		}
+		l.add(Issue{
+			Rule:      RuleDisabledShadow,
+			Severity:  SeverityInfo,
+			RR:        disabled[0].RR,
+			Type:      disabled[0].Type,
+			Line:      disabled[0].Line,
+			RecordIds: recordIds(disabled),
+			Message:   fmt.Sprintf("%d条暂停的记录与%d条启用的记录同名同类型，重新启用后会改变解析结果", len(disabled), len(enabled)),
+		})
+	}
+}
+
+// checkMailAndCAA 检查SPF、DMARC和CAA记录是否缺失
+func (l *linter) checkMailAndCAA() {
+	var hasSPF, hasDMARC, hasCAA, hasMX bool
+	for _, r := range l.records {
+		if !isEnabled(r) {
+			continue
+		}
+		rr := normalizeRR(r.RR)
+		value := strings.ToLower(strings.Trim(r.Value, `"`))
+		switch {
+		case r.Type == "MX" && rr == "@":
+			hasMX = true
+		case r.Type == "TXT" && rr == "@" && strings.HasPrefix(value, "v=spf1"):
+			hasSPF = true
+		case r.Type == "TXT" && rr == "_dmarc" && strings.HasPrefix(value, "v=dmarc1"):
+			hasDMARC = true
+		case r.Type == "CAA" && rr == "@":
+			hasCAA = true
+		}
+	}
+
+	// 没有MX记录的域名也建议发布 v=spf1 -all，防止被冒用
+	mailSeverity := SeverityInfo
+	if hasMX {
+		mailSeverity = SeverityWarning
+	}
+	if !hasSPF {
+		l.add(Issue{
+			Rule:     RuleMissingSPF,
+			Severity: mailSeverity,
+			RR:       "@",
+			Type:     "TXT",
+			Message:  "缺少SPF记录(v=spf1)",
+		})
+	}
+	if !hasDMARC {
+		l.add(Issue{
+			Rule:     RuleMissingDMARC,
+			Severity: mailSeverity,
+			RR:       "_dmarc",
+			Type:     "TXT",
+			Message:  "缺少DMARC记录(v=DMARC1)",
+		})
+	}
+	if !hasCAA {
+		l.add(Issue{
+			Rule:     RuleMissingCAA,
+			Severity: SeverityInfo,
+			RR:       "@",
+			Type:     "CAA",
+			Message:  "缺少CAA记录，任何CA都可以为该域名签发证书",
+		})
+	}
+}
+
+// checkTTL 检查过低的TTL以及同一记录集内不一致的TTL
+func (l *linter) checkTTL() {
+	keys, groups := groupBy(l.records, rrsetKey, true)
+	for _, k := range keys {
+		group := groups[k]
+
+		var low []service.DomainRecord
+		ttls := make(map[int64]bool)
+		for _, r := range group {
+			ttls[r.TTL] = true
+			if r.TTL > 0 && r.TTL < l.opts.MinTTL {
+				low = append(low, r)
+			}
+		}
+
+		if len(low) > 0 {
+			l.add(Issue{
+				Rule:      RuleLowTTL,
+				Severity:  SeverityWarning,
+				RR:        group[0].RR,
+				Type:      group[0].Type,
+				Line:      group[0].Line,
+				RecordIds: recordIds(low),
+				Message:   fmt.Sprintf("TTL低于%d秒，会显著增加解析请求量", l.opts.MinTTL),
+			})
+		}
+
+		if len(ttls) > 1 {
+			l.add(Issue{
+				Rule:      RuleInconsistentTTL,
+				Severity:  SeverityWarning,
+				RR:        group[0].RR,
+				Type:      group[0].Type,
+				Line:      group[0].Line,
+				RecordIds: recordIds(group),
+				Message:   "同一记录集内的TTL不一致",
+			})
+		}
+	}
+}
+
+// uniqueSorted 去重并排序
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+
+	"dns-update/internal/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityKey 调用方身份在上下文中的键
+const IdentityKey = "identity"
+
+// Token 接口访问令牌，Name 作为调用方身份记录在审计日志中
+type Token struct {
+	Name  string
+	Token string
+}
+
+// Identity 根据 Authorization: Bearer 令牌识别调用方身份的中间件。
+// 未携带令牌的请求按匿名处理，由具体接口决定是否需要身份；
+// 其他认证方式（如 ACME 接口的 Basic 认证）不受影响
+func Identity(tokens []Token) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(tokens) == 0 {
+			c.Next()
+			return
+		}
+
+		scheme, credential, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			c.Next()
+			return
+		}
+
+		credential = strings.TrimSpace(credential)
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(credential), []byte(t.Token)) == 1 {
+				c.Set(IdentityKey, t.Name)
+				c.Next()
+				return
+			}
+		}
+
+		e := apperror.Unauthorized("访问令牌无效")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, e.ToResponse(GetRequestId(c)))
+	}
+}
+
+// GetIdentity 获取调用方身份，匿名请求返回空字符串
+func GetIdentity(c *gin.Context) string {
+	return c.GetString(IdentityKey)
+}
+
+// RequireIdentity 要求调用方已认证的中间件，allowed 不为空时只允许其中的身份
+func RequireIdentity(allowed []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity := GetIdentity(c)
+		if identity == "" {
+			e := apperror.Unauthorized("需要通过 Authorization: Bearer 令牌认证")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, e.ToResponse(GetRequestId(c)))
+			return
+		}
+		if len(allowed) > 0 && !slices.Contains(allowed, identity) {
+			e := apperror.Forbidden(identity + " 没有权限访问该接口")
+			c.AbortWithStatusJSON(http.StatusForbidden, e.ToResponse(GetRequestId(c)))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIdHeader 请求ID响应头
+	RequestIdHeader = "X-Request-Id"
+	// RequestIdKey 请求ID在上下文中的键
+	RequestIdKey = "request_id"
+)
+
+// RequestID 为每个请求生成唯一ID的中间件
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 优先沿用调用方传入的请求ID
+		requestId := c.GetHeader(RequestIdHeader)
+		if requestId == "" {
+			requestId = newRequestId()
+		}
+
+		c.Set(RequestIdKey, requestId)
+		c.Header(RequestIdHeader, requestId)
+
+		c.Next()
+	}
+}
+
+// GetRequestId 获取当前请求的ID
+func GetRequestId(c *gin.Context) string {
+	return c.GetString(RequestIdKey)
+}
+
+// newRequestId 生成随机请求ID
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -24,7 +24,7 @@ func RequestTimer() gin.HandlerFunc {
 		c.Header("X-Response-Time", duration.String())
 
 		// 写入日志
-		logger.GetLogger().Info("请求处理完成",
+		logger.Named(logger.ComponentHandler).Info("请求处理完成",
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
 			zap.Int("status", c.Writer.Status()),
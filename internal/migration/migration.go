@@ -0,0 +1,723 @@
+package migration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/notify"
+	"dns-update/internal/service"
+	"dns-update/internal/store"
+	"dns-update/internal/validation"
+	"dns-update/pkg/idn"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// 迁移的状态
+const (
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusFailed    = "failed"
+	StatusAborted   = "aborted"
+	StatusCompleted = "completed"
+)
+
+// 迁移的阶段，按顺序执行
+const (
+	PhaseLowerTTL     = "lower_ttl"      // 降低TTL
+	PhaseWaitOldTTL   = "wait_old_ttl"   // 等待原TTL的缓存过期
+	PhaseSwitch       = "switch"         // 修改记录值
+	PhaseWaitNewValue = "wait_new_value" // 等待新值生效并观察
+	PhaseRaiseTTL     = "raise_ttl"      // 恢复TTL
+	PhaseDone         = "done"
+)
+
+// 迁移的限制
+const (
+	MaxRecords = 100
+	// MaxHold 修改记录值后观察时间的上限
+	MaxHold = 7 * 24 * time.Hour
+)
+
+// StoreFile 迁移的状态文件名
+const StoreFile = "migrations.json"
+
+// tick 检查迁移进度的间隔
+const tick = time.Second
+
+// RecordService 迁移依赖的解析记录服务
+type RecordService interface {
+	GetDomainRecordById(recordId string) (*service.DomainRecord, error)
+	UpdateDomainRecord(recordId string, input *service.DomainRecordInput) error
+	CheckWrite(action, domainName, recordId string, input *service.DomainRecordInput) error
+}
+
+// RecordServiceFor 返回以指定调用方执行写操作的服务，迁移以创建者的身份执行
+type RecordServiceFor func(actor service.Actor) RecordService
+
+// Options 迁移的选项
+type Options struct {
+	LowTTL        int64         // 未指定时降低到的TTL
+	Margin        time.Duration // 等待缓存过期时额外等待的时长
+	RetryInterval time.Duration // 步骤失败后的重试间隔
+	MaxAttempts   int           // 每个步骤最多执行的次数，超过后迁移失败，可以手动恢复
+	Retention     time.Duration // 已结束迁移的保留时长
+}
+
+// DefaultOptions 默认的迁移选项
+var DefaultOptions = Options{
+	LowTTL:        600,
+	Margin:        30 * time.Second,
+	RetryInterval: 30 * time.Second,
+	MaxAttempts:   5,
+	Retention:     30 * 24 * time.Hour,
+}
+
+// RecordRequest 迁移中的一条记录
+type RecordRequest struct {
+	RecordId string `json:"record_id"`
+	Value    string `json:"value"` // 新的记录值
+}
+
+// CreateRequest 创建迁移的请求
+type CreateRequest struct {
+	Records    []RecordRequest `json:"records"`
+	LowTTL     int64           `json:"low_ttl"`     // 迁移期间的TTL，0 表示使用默认值
+	RestoreTTL int64           `json:"restore_ttl"` // 迁移完成后的TTL，0 表示恢复原TTL
+	Hold       string          `json:"hold"`        // 修改记录值后、恢复TTL前额外观察的时长，如 30m
+}
+
+// Record 迁移中的一条记录及其原始状态
+type Record struct {
+	RecordId      string `json:"record_id"`
+	RR            string `json:"rr"`
+	Type          string `json:"type"`
+	Line          string `json:"line,omitempty"`
+	Priority      int64  `json:"priority,omitempty"`
+	Value         string `json:"value"`
+	OriginalValue string `json:"original_value"`
+	OriginalTTL   int64  `json:"original_ttl"`
+}
+
+// Event 迁移过程中的事件
+type Event struct {
+	Time    time.Time `json:"time"`
+	Phase   string    `json:"phase"`
+	Message string    `json:"message"`
+}
+
+// Migration TTL 降低、切换、恢复的迁移流程
+type Migration struct {
+	Id          string        `json:"id"`
+	Domain      string        `json:"domain"`
+	Actor       service.Actor `json:"actor"` // 创建迁移的调用方，每一步按其身份做策略检查
+	Records     []Record      `json:"records"`
+	LowTTL      int64         `json:"low_ttl"`
+	RestoreTTL  int64         `json:"restore_ttl,omitempty"`
+	HoldSeconds int64         `json:"hold_seconds"`
+	Status      string        `json:"status"`
+	Phase       string        `json:"phase"`
+	WaitUntil   *time.Time    `json:"wait_until,omitempty"` // 当前阶段最早在该时间之后继续
+	Attempts    int           `json:"attempts"`             // 当前步骤已失败的次数
+	Error       string        `json:"error,omitempty"`
+	Events      []Event       `json:"events"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	FinishedAt  *time.Time    `json:"finished_at,omitempty"`
+}
+
+// Manager 管理迁移流程，状态保存在状态文件中，服务重启后从当前阶段继续
+type Manager struct {
+	records  RecordServiceFor
+	notifier notify.Notifier
+	file     *store.File
+	opts     Options
+	log      *zap.Logger
+
+	mu         sync.Mutex
+	migrations map[string]*Migration
+	busy       map[string]bool // 正在执行步骤的迁移
+}
+
+// NewManager 创建迁移管理器并加载已保存的迁移
+func NewManager(records RecordServiceFor, notifier notify.Notifier, file *store.File, opts *Options) (*Manager, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	o := *opts
+	if o.LowTTL <= 0 {
+		o.LowTTL = DefaultOptions.LowTTL
+	}
+	if o.Margin <= 0 {
+		o.Margin = DefaultOptions.Margin
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultOptions.RetryInterval
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultOptions.MaxAttempts
+	}
+	if o.Retention <= 0 {
+		o.Retention = DefaultOptions.Retention
+	}
+
+	m := &Manager{
+		records:    records,
+		notifier:   notifier,
+		file:       file,
+		opts:       o,
+		log:        logger.GetLogger(),
+		migrations: make(map[string]*Migration),
+		busy:       make(map[string]bool),
+	}
+
+	var migrations []*Migration
+	if err := file.Load(&migrations); err != nil {
+		return nil, err
+	}
+	for _, mig := range migrations {
+		m.migrations[mig.Id] = mig
+	}
+
+	m.log.Info("已加载迁移",
+		zap.String("file", file.Path()),
+		zap.Int("count", len(migrations)),
+	)
+	return m, nil
+}
+
+// Create 创建迁移并立即开始降低TTL，记录的原始值和TTL在创建时保存。
+// 创建时以 actor 的身份对每条记录的修改做策略检查，不允许时拒绝创建
+func (m *Manager) Create(domain string, req *CreateRequest, actor service.Actor) (*Migration, error) {
+	if domain == "" {
+		return nil, apperror.BadRequest("域名不能为空")
+	}
+
+	var errs validation.Errors
+	if len(req.Records) == 0 {
+		errs.Add("records", errors.New("records不能为空"))
+	} else if len(req.Records) > MaxRecords {
+		errs.Add("records", fmt.Errorf("单次最多迁移%d条记录", MaxRecords))
+	}
+	lowTTL := req.LowTTL
+	if lowTTL == 0 {
+		lowTTL = m.opts.LowTTL
+	}
+	if err := validation.ValidateTTL(lowTTL); err != nil {
+		errs.Add("low_ttl", err)
+	}
+	if err := validation.ValidateTTL(req.RestoreTTL); err != nil {
+		errs.Add("restore_ttl", err)
+	}
+	var hold time.Duration
+	if req.Hold != "" {
+		var err error
+		if hold, err = time.ParseDuration(req.Hold); err != nil || hold < 0 {
+			errs.Add("hold", errors.New("观察时长格式错误，应为 30s、5m 等形式"))
+		} else if hold > MaxHold {
+			errs.Add("hold", fmt.Errorf("观察时长不能超过%s", MaxHold))
+		}
+	}
+	seen := make(map[string]bool)
+	for i, r := range req.Records {
+		field := fmt.Sprintf("records[%d]", i)
+		if r.RecordId == "" {
+			errs.Add(field+".record_id", errors.New("记录ID不能为空"))
+		} else if seen[r.RecordId] {
+			errs.Add(field+".record_id", errors.New("记录重复"))
+		}
+		seen[r.RecordId] = true
+		if r.Value == "" {
+			errs.Add(field+".value", errors.New("新的记录值不能为空"))
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	// 查询记录的当前状态，校验归属和新值
+	recordService := m.records(actor)
+	records := make([]Record, 0, len(req.Records))
+	for i, r := range req.Records {
+		field := fmt.Sprintf("records[%d]", i)
+		current, err := recordService.GetDomainRecordById(r.RecordId)
+		if err != nil {
+			return nil, err
+		}
+		if current.DomainName != "" && !idn.Equal(current.DomainName, domain) {
+			return nil, apperror.NotFound(fmt.Sprintf("解析记录%s不属于指定域名", r.RecordId))
+		}
+		if err := validation.ValidateValue(current.Type, r.Value); err != nil {
+			errs.Add(field+".value", err)
+			continue
+		}
+		records = append(records, Record{
+			RecordId:      r.RecordId,
+			RR:            current.RR,
+			Type:          current.Type,
+			Line:          current.Line,
+			Priority:      current.Priority,
+			Value:         r.Value,
+			OriginalValue: current.Value,
+			OriginalTTL:   current.TTL,
+		})
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		input := &service.DomainRecordInput{RR: r.RR, Type: r.Type, Value: r.Value, TTL: lowTTL, Line: r.Line}
+		if err := recordService.CheckWrite(service.WriteUpdate, domain, r.RecordId, input); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	mig := &Migration{
+		Id:          newMigrationId(),
+		Domain:      domain,
+		Actor:       actor,
+		Records:     records,
+		LowTTL:      lowTTL,
+		RestoreTTL:  req.RestoreTTL,
+		HoldSeconds: int64(hold / time.Second),
+		Status:      StatusRunning,
+		Phase:       PhaseLowerTTL,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	addEvent(mig, "迁移已创建")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeLocked()
+	m.migrations[mig.Id] = mig
+	if err := m.saveLocked(); err != nil {
+		delete(m.migrations, mig.Id)
+		return nil, apperror.Internal(err)
+	}
+
+	m.log.Info("已创建迁移",
+		zap.String("migration_id", mig.Id),
+		zap.String("domain", domain),
+		zap.Int("records", len(records)),
+		zap.Int64("low_ttl", lowTTL),
+	)
+	return copyMigration(mig), nil
+}
+
+// Get 查询迁移
+func (m *Manager) Get(id string) (*Migration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mig, ok := m.migrations[id]
+	if !ok {
+		return nil, apperror.NotFound("迁移不存在")
+	}
+	return copyMigration(mig), nil
+}
+
+// List 返回所有迁移，最新创建的在前
+func (m *Manager) List() []*Migration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	migrations := make([]*Migration, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		migrations = append(migrations, copyMigration(mig))
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].CreatedAt.After(migrations[j].CreatedAt)
+	})
+	return migrations
+}
+
+// Pause 暂停迁移，正在执行的步骤完成后不再进入下一阶段；等待中的时间照常计算
+func (m *Manager) Pause(id string) (*Migration, error) {
+	return m.transition(id, func(mig *Migration) error {
+		if mig.Status != StatusRunning {
+			return apperror.Conflict(fmt.Sprintf("迁移当前状态为%s，无法暂停", mig.Status))
+		}
+		mig.Status = StatusPaused
+		addEvent(mig, "迁移已暂停")
+		return nil
+	})
+}
+
+// Resume 恢复暂停或失败的迁移，失败的步骤会重新执行
+func (m *Manager) Resume(id string) (*Migration, error) {
+	return m.transition(id, func(mig *Migration) error {
+		switch mig.Status {
+		case StatusPaused:
+		case StatusFailed:
+			mig.Attempts = 0
+			mig.WaitUntil = nil
+			mig.FinishedAt = nil
+		default:
+			return apperror.Conflict(fmt.Sprintf("迁移当前状态为%s，无法恢复", mig.Status))
+		}
+		mig.Status = StatusRunning
+		mig.Error = ""
+		addEvent(mig, "迁移已恢复")
+		return nil
+	})
+}
+
+// Abort 中止迁移。记录值尚未修改时恢复原TTL；已修改记录值时保持当前状态，TTL 仍为迁移期间的值
+func (m *Manager) Abort(id string) (*Migration, error) {
+	var restore bool
+	mig, err := m.transition(id, func(mig *Migration) error {
+		switch mig.Status {
+		case StatusRunning, StatusPaused, StatusFailed:
+		default:
+			return apperror.Conflict(fmt.Sprintf("迁移当前状态为%s，无法中止", mig.Status))
+		}
+		now := time.Now()
+		mig.Status = StatusAborted
+		mig.WaitUntil = nil
+		mig.FinishedAt = &now
+		restore = mig.Phase == PhaseLowerTTL || mig.Phase == PhaseWaitOldTTL
+		if restore {
+			addEvent(mig, "迁移已中止，记录值未修改，正在恢复原TTL")
+		} else {
+			addEvent(mig, "迁移已中止，记录值已修改，保持当前状态")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if restore {
+		go m.restore(mig)
+	}
+	return mig, nil
+}
+
+// transition 在锁内修改迁移状态并保存
+func (m *Manager) transition(id string, fn func(mig *Migration) error) (*Migration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mig, ok := m.migrations[id]
+	if !ok {
+		return nil, apperror.NotFound("迁移不存在")
+	}
+	if err := fn(mig); err != nil {
+		return nil, err
+	}
+	mig.UpdatedAt = time.Now()
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存迁移失败", zap.Error(err))
+	}
+
+	m.log.Info("迁移状态已变更",
+		zap.String("migration_id", id),
+		zap.String("status", mig.Status),
+		zap.String("phase", mig.Phase),
+	)
+	return copyMigration(mig), nil
+}
+
+// Run 定期推进进行中的迁移，直到 ctx 取消
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		m.dispatch()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch 为可以继续的迁移启动下一步
+func (m *Manager) dispatch() {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, mig := range m.migrations {
+		if mig.Status != StatusRunning || m.busy[id] {
+			continue
+		}
+		if mig.WaitUntil != nil && mig.WaitUntil.After(now) {
+			continue
+		}
+		m.busy[id] = true
+		go m.step(copyMigration(mig))
+	}
+}
+
+// step 执行迁移当前阶段的操作，成功后进入下一阶段
+func (m *Manager) step(snapshot *Migration) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.busy, snapshot.Id)
+		m.mu.Unlock()
+	}()
+
+	next, wait, message, err := m.advance(snapshot)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mig, ok := m.migrations[snapshot.Id]
+	if !ok || mig.Phase != snapshot.Phase || mig.Status == StatusAborted {
+		return
+	}
+	now := time.Now()
+	mig.UpdatedAt = now
+
+	if err != nil {
+		mig.Attempts++
+		mig.Error = apperror.From(err).Message
+		if mig.Attempts >= m.opts.MaxAttempts {
+			if mig.Status == StatusRunning {
+				mig.Status = StatusFailed
+				mig.WaitUntil = nil
+				mig.FinishedAt = &now
+			}
+			addEvent(mig, fmt.Sprintf("步骤失败%d次，迁移已停止: %s", mig.Attempts, mig.Error))
+			m.log.Error("迁移步骤失败",
+				zap.String("migration_id", mig.Id),
+				zap.String("phase", mig.Phase),
+				zap.Error(err),
+			)
+			m.notifyLocked(mig, "migration.failed", "迁移失败", fmt.Sprintf("阶段 %s 失败: %s", mig.Phase, mig.Error))
+		} else {
+			retryAt := now.Add(m.opts.RetryInterval)
+			mig.WaitUntil = &retryAt
+			m.log.Warn("迁移步骤失败，稍后重试",
+				zap.String("migration_id", mig.Id),
+				zap.String("phase", mig.Phase),
+				zap.Int("attempts", mig.Attempts),
+				zap.Error(err),
+			)
+		}
+		if err := m.saveLocked(); err != nil {
+			m.log.Error("保存迁移失败", zap.Error(err))
+		}
+		return
+	}
+
+	mig.Phase = next
+	mig.Attempts = 0
+	mig.Error = ""
+	mig.WaitUntil = nil
+	if wait > 0 {
+		until := now.Add(wait)
+		mig.WaitUntil = &until
+	}
+	if message != "" {
+		addEvent(mig, message)
+	}
+	if next == PhaseDone && mig.Status == StatusRunning {
+		mig.Status = StatusCompleted
+		mig.FinishedAt = &now
+		m.notifyLocked(mig, "migration.completed", "迁移完成", fmt.Sprintf("域名 %s 的%d条记录已迁移", mig.Domain, len(mig.Records)))
+	}
+
+	m.log.Info("迁移进入下一阶段",
+		zap.String("migration_id", mig.Id),
+		zap.String("phase", next),
+		zap.Duration("wait", wait),
+	)
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存迁移失败", zap.Error(err))
+	}
+}
+
+// advance 执行当前阶段，返回下一阶段、进入下一阶段后需要等待的时长和事件说明
+func (m *Manager) advance(mig *Migration) (string, time.Duration, string, error) {
+	switch mig.Phase {
+	case PhaseLowerTTL:
+		var maxTTL int64
+		for _, r := range mig.Records {
+			if err := m.apply(mig, &r, "", lowTTL(mig, &r)); err != nil {
+				return "", 0, "", err
+			}
+			maxTTL = max(maxTTL, r.OriginalTTL)
+		}
+		wait := time.Duration(maxTTL)*time.Second + m.opts.Margin
+		return PhaseWaitOldTTL, wait, fmt.Sprintf("TTL已降低到%d，等待原TTL(最长%d秒)的缓存过期", mig.LowTTL, maxTTL), nil
+
+	case PhaseWaitOldTTL:
+		return PhaseSwitch, 0, "", nil
+
+	case PhaseSwitch:
+		for _, r := range mig.Records {
+			if err := m.apply(mig, &r, r.Value, lowTTL(mig, &r)); err != nil {
+				return "", 0, "", err
+			}
+		}
+		hold := time.Duration(mig.HoldSeconds) * time.Second
+		wait := time.Duration(mig.LowTTL)*time.Second + m.opts.Margin + hold
+		return PhaseWaitNewValue, wait, fmt.Sprintf("记录值已修改，等待%s后恢复TTL", wait), nil
+
+	case PhaseWaitNewValue:
+		return PhaseRaiseTTL, 0, "", nil
+
+	case PhaseRaiseTTL:
+		for _, r := range mig.Records {
+			ttl := mig.RestoreTTL
+			if ttl == 0 {
+				ttl = r.OriginalTTL
+			}
+			if err := m.apply(mig, &r, "", ttl); err != nil {
+				return "", 0, "", err
+			}
+		}
+		return PhaseDone, 0, "TTL已恢复，迁移完成", nil
+	}
+	return "", 0, "", fmt.Errorf("未知的迁移阶段: %s", mig.Phase)
+}
+
+// restore 中止迁移时恢复原TTL，失败只记录日志
+func (m *Manager) restore(mig *Migration) {
+	// 等待正在执行的步骤结束，避免恢复后又被降低
+	for {
+		m.mu.Lock()
+		busy := m.busy[mig.Id]
+		m.mu.Unlock()
+		if !busy {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	var failed []string
+	for _, r := range mig.Records {
+		if err := m.apply(mig, &r, "", r.OriginalTTL); err != nil {
+			m.log.Error("恢复原TTL失败",
+				zap.String("migration_id", mig.Id),
+				zap.String("record_id", r.RecordId),
+				zap.Error(err),
+			)
+			failed = append(failed, r.RecordId)
+		}
+	}
+
+	message := "已恢复原TTL"
+	if len(failed) > 0 {
+		message = "恢复原TTL失败的记录: " + strings.Join(failed, ", ")
+	}
+	if _, err := m.transition(mig.Id, func(mig *Migration) error {
+		addEvent(mig, message)
+		return nil
+	}); err != nil {
+		m.log.Error("保存迁移失败", zap.Error(err))
+	}
+}
+
+// apply 以迁移创建者的身份将记录设置为指定的值和TTL，value 为空时保持当前值；已经一致时不调用接口
+func (m *Manager) apply(mig *Migration, r *Record, value string, ttl int64) error {
+	records := m.records(mig.Actor)
+	current, err := records.GetDomainRecordById(r.RecordId)
+	if err != nil {
+		return err
+	}
+	if current.DomainName != "" && !idn.Equal(current.DomainName, mig.Domain) {
+		return apperror.NotFound(fmt.Sprintf("解析记录%s不属于指定域名", r.RecordId))
+	}
+	if value == "" {
+		value = current.Value
+	}
+	if strings.EqualFold(current.Value, value) && current.TTL == ttl {
+		return nil
+	}
+
+	input := &service.DomainRecordInput{
+		RR:    current.RR,
+		Type:  current.Type,
+		Value: value,
+		TTL:   ttl,
+		Line:  current.Line,
+	}
+	if current.Type == validation.TypeMX {
+		input.Priority = current.Priority
+	}
+	return records.UpdateDomainRecord(r.RecordId, input)
+}
+
+// notifyLocked 发送迁移事件通知，调用方需持有锁
+func (m *Manager) notifyLocked(mig *Migration, kind, title, message string) {
+	m.notifier.Notify(&notify.Event{
+		Kind:    kind,
+		Title:   title,
+		Message: message,
+		Fields: map[string]string{
+			"migration_id": mig.Id,
+			"domain":       mig.Domain,
+			"phase":        mig.Phase,
+			"records":      strconv.Itoa(len(mig.Records)),
+		},
+	})
+}
+
+// saveLocked 保存所有迁移，调用方需持有锁
+func (m *Manager) saveLocked() error {
+	migrations := make([]*Migration, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].CreatedAt.Before(migrations[j].CreatedAt)
+	})
+	return m.file.Save(migrations)
+}
+
+// purgeLocked 清理超过保留时长的已结束迁移，调用方需持有锁
+func (m *Manager) purgeLocked() {
+	deadline := time.Now().Add(-m.opts.Retention)
+	for id, mig := range m.migrations {
+		if mig.FinishedAt != nil && mig.FinishedAt.Before(deadline) {
+			delete(m.migrations, id)
+		}
+	}
+}
+
+// lowTTL 迁移期间记录使用的TTL，原TTL更低时保持原TTL
+func lowTTL(mig *Migration, r *Record) int64 {
+	if r.OriginalTTL > 0 && r.OriginalTTL < mig.LowTTL {
+		return r.OriginalTTL
+	}
+	return mig.LowTTL
+}
+
+// addEvent 追加迁移事件
+func addEvent(mig *Migration, message string) {
+	mig.Events = append(mig.Events, Event{Time: time.Now(), Phase: mig.Phase, Message: message})
+}
+
+// copyMigration 复制迁移，避免调用方与执行中的迁移产生竞争
+func copyMigration(mig *Migration) *Migration {
+	c := *mig
+	c.Records = append([]Record(nil), mig.Records...)
+	c.Events = append([]Event(nil), mig.Events...)
+	return &c
+}
+
+// newMigrationId 生成随机迁移ID
+func newMigrationId() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// 通知的消息格式
+const (
+	FormatJSON     = "json"     // 原样发送 Event
+	FormatDingTalk = "dingtalk" // 钉钉群机器人文本消息
+	FormatWeCom    = "wecom"    // 企业微信群机器人文本消息
+	FormatSlack    = "slack"    // Slack incoming webhook
+)
+
+// sendTimeout 单次发送通知的超时时间
+const sendTimeout = 10 * time.Second
+
+// Event 通知事件
+type Event struct {
+	Kind    string            `json:"kind"`    // 事件类型，如 failover.switched
+	Title   string            `json:"title"`   // 简短的标题
+	Message string            `json:"message"` // 详细说明
+	Fields  map[string]string `json:"fields,omitempty"`
+	Time    time.Time         `json:"time"`
+}
+
+// Notifier 发送通知
+type Notifier interface {
+	// Notify 异步发送通知，发送失败只记录日志
+	Notify(event *Event)
+}
+
+// Target 通知的接收地址
+type Target struct {
+	Name   string
+	URL    string
+	Format string // 消息格式，为空时使用 json
+}
+
+// Webhook 通过 HTTP POST 将通知发送到一组地址
+type Webhook struct {
+	targets []Target
+	client  *http.Client
+	log     *zap.Logger
+}
+
+// NewWebhook 创建 webhook 通知，没有地址时通知被丢弃
+func NewWebhook(targets []Target) *Webhook {
+	return &Webhook{
+		targets: targets,
+		client:  &http.Client{Timeout: sendTimeout},
+		log:     logger.GetLogger(),
+	}
+}
+
+// Notify 异步发送通知到所有地址
+func (w *Webhook) Notify(event *Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, target := range w.targets {
+		go func(target Target) {
+			if err := w.send(target, event); err != nil {
+				w.log.Error("发送通知失败",
+					zap.String("target", target.Name),
+					zap.String("kind", event.Kind),
+					zap.Error(err),
+				)
+			}
+		}(target)
+	}
+}
+
+// send 按目标的格式发送通知
+func (w *Webhook) send(target Target, event *Event) error {
+	body, err := encode(target.Format, event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("通知地址返回 %s", resp.Status)
+	}
+	return nil
+}
+
+// encode 将事件编码为目标格式的请求体
+func encode(format string, event *Event) ([]byte, error) {
+	switch format {
+	case "", FormatJSON:
+		return json.Marshal(event)
+	case FormatDingTalk, FormatWeCom:
+		return json.Marshal(map[string]any{
+			"msgtype": "text",
+			"text":    map[string]string{"content": Text(event)},
+		})
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": Text(event)})
+	}
+	return nil, fmt.Errorf("不支持的通知格式: %s", format)
+}
+
+// Text 将事件格式化为纯文本
+func Text(event *Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[dns-update] %s\n%s", event.Title, event.Message)
+
+	keys := make([]string, 0, len(event.Fields))
+	for k := range event.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n%s: %s", k, event.Fields[k])
+	}
+	fmt.Fprintf(&b, "\n%s", event.Time.Format(time.RFC3339))
+	return b.String()
+}
@@ -0,0 +1,492 @@
+package policy
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/audit"
+	"dns-update/internal/notify"
+	"dns-update/internal/service"
+	"dns-update/pkg/idn"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// SystemActor 内部组件（故障转移、审批执行、控制器等）发起写操作时的身份
+const SystemActor = "system"
+
+// 时间格式
+const (
+	dateTimeLayout = "2006-01-02 15:04"
+	clockLayout    = "15:04"
+)
+
+// Window 规则生效的时间窗口：Start/End 为一次性窗口，From/To 为每天（或 Days 指定的星期）重复的窗口
+type Window struct {
+	Start string   // 开始时间，格式 2006-01-02 15:04
+	End   string   // 结束时间，格式 2006-01-02 15:04
+	Days  []string // 星期，如 mon、sat，为空表示每天
+	From  string   // 每天的开始时间，格式 15:04
+	To    string   // 每天的结束时间，早于 From 时跨越零点
+}
+
+// Rule 写操作策略规则，匹配的写操作按 Deny、ForbidDelete、MaxTTLDecrease 检查
+type Rule struct {
+	Name           string
+	Timezone       string   // 时间窗口的时区，为空时使用本地时区
+	Windows        []Window // 只在这些时间窗口内生效，为空表示始终生效
+	Domains        []string // 域名，* 表示所有域名，为空表示所有域名
+	RR             []string // 主机记录通配符，为空表示所有主机记录
+	Types          []string // 记录类型，为空表示所有类型
+	Actions        []string // 写操作类型，为空表示所有写操作
+	AllowedActors  []string // 不受该规则限制的身份，system 表示内部组件
+	Deny           bool     // 禁止匹配的写操作，用于变更冻结
+	ForbidDelete   bool     // 禁止删除匹配的记录
+	MaxTTLDecrease int64    // 一次最多降低的TTL（秒），0 表示不限制
+	Message        string   // 拒绝时附加的说明
+}
+
+// Options 策略的选项
+type Options struct {
+	Rules            []Rule
+	BreakGlassActors []string // 允许紧急变更的身份，为空表示任意已认证身份
+}
+
+// window 解析后的时间窗口
+type window struct {
+	start, end time.Time
+	days       map[time.Weekday]bool
+	from, to   time.Duration
+	desc       string
+}
+
+// rule 解析后的规则
+type rule struct {
+	Rule
+	loc     *time.Location
+	windows []window
+	types   map[string]bool
+	actions map[string]bool
+}
+
+// Engine 在写操作执行前按规则检查，实现 service.WriteGuard
+type Engine struct {
+	rules      []*rule
+	breakGlass []string
+	audit      *audit.Log
+	notifier   notify.Notifier
+	log        *zap.Logger
+	now        func() time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+var writeActions = map[string]bool{
+	service.WriteCreate:     true,
+	service.WriteUpdate:     true,
+	service.WriteDelete:     true,
+	service.WriteEnable:     true,
+	service.WriteDisable:    true,
+	service.WriteRemark:     true,
+	service.WriteSLB:        true,
+	service.WriteCustomLine: true,
+	service.WriteDomain:     true,
+	service.WriteGroup:      true,
+}
+
+// NewEngine 解析并校验策略规则
+func NewEngine(auditLog *audit.Log, notifier notify.Notifier, opts *Options) (*Engine, error) {
+	e := &Engine{
+		breakGlass: opts.BreakGlassActors,
+		audit:      auditLog,
+		notifier:   notifier,
+		log:        logger.GetLogger(),
+		now:        time.Now,
+	}
+
+	for i, r := range opts.Rules {
+		parsed, err := parseRule(r)
+		if err != nil {
+			name := r.Name
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return nil, fmt.Errorf("策略规则[%s]: %w", name, err)
+		}
+		e.rules = append(e.rules, parsed)
+	}
+
+	e.log.Info("已加载写操作策略", zap.Int("rules", len(e.rules)))
+	return e, nil
+}
+
+// parseRule 解析规则
+func parseRule(r Rule) (*rule, error) {
+	if r.Name == "" {
+		return nil, fmt.Errorf("名称不能为空")
+	}
+	if !r.Deny && !r.ForbidDelete && r.MaxTTLDecrease <= 0 {
+		return nil, fmt.Errorf("需要指定deny、forbid_delete或max_ttl_decrease")
+	}
+
+	loc := time.Local
+	if r.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(r.Timezone); err != nil {
+			return nil, fmt.Errorf("时区无效: %s", r.Timezone)
+		}
+	}
+
+	parsed := &rule{Rule: r, loc: loc}
+	for i, w := range r.Windows {
+		pw, err := parseWindow(w, loc)
+		if err != nil {
+			return nil, fmt.Errorf("时间窗口[%d]%w", i, err)
+		}
+		parsed.windows = append(parsed.windows, pw)
+	}
+	if len(r.Types) > 0 {
+		parsed.types = make(map[string]bool)
+		for _, t := range r.Types {
+			parsed.types[strings.ToUpper(t)] = true
+		}
+	}
+	if len(r.Actions) > 0 {
+		parsed.actions = make(map[string]bool)
+		for _, a := range r.Actions {
+			if !writeActions[a] {
+				return nil, fmt.Errorf("写操作类型不支持: %s", a)
+			}
+			parsed.actions[a] = true
+		}
+	}
+	for _, pattern := range r.RR {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("主机记录通配符无效: %s", pattern)
+		}
+	}
+	return parsed, nil
+}
+
+// parseWindow 解析时间窗口
+func parseWindow(w Window, loc *time.Location) (window, error) {
+	var pw window
+	switch {
+	case w.Start != "" || w.End != "":
+		var err error
+		if pw.start, err = time.ParseInLocation(dateTimeLayout, w.Start, loc); err != nil {
+			return pw, fmt.Errorf("的开始时间格式应为 %s", dateTimeLayout)
+		}
+		if pw.end, err = time.ParseInLocation(dateTimeLayout, w.End, loc); err != nil {
+			return pw, fmt.Errorf("的结束时间格式应为 %s", dateTimeLayout)
+		}
+		if !pw.end.After(pw.start) {
+			return pw, fmt.Errorf("的结束时间必须晚于开始时间")
+		}
+		pw.desc = fmt.Sprintf("%s ~ %s (%s)", w.Start, w.End, loc)
+	case w.From != "" || w.To != "":
+		from, err := time.Parse(clockLayout, w.From)
+		if err != nil {
+			return pw, fmt.Errorf("的from格式应为 %s", clockLayout)
+		}
+		to, err := time.Parse(clockLayout, w.To)
+		if err != nil {
+			return pw, fmt.Errorf("的to格式应为 %s", clockLayout)
+		}
+		pw.from = time.Duration(from.Hour())*time.Hour + time.Duration(from.Minute())*time.Minute
+		pw.to = time.Duration(to.Hour())*time.Hour + time.Duration(to.Minute())*time.Minute
+		if pw.from == pw.to {
+			return pw, fmt.Errorf("的from和to不能相同")
+		}
+		if len(w.Days) > 0 {
+			pw.days = make(map[time.Weekday]bool)
+			for _, d := range w.Days {
+				day, ok := weekdays[strings.ToLower(d)]
+				if !ok {
+					return pw, fmt.Errorf("的星期无效: %s", d)
+				}
+				pw.days[day] = true
+			}
+		}
+		days := "每天"
+		if len(w.Days) > 0 {
+			days = strings.Join(w.Days, ",")
+		}
+		pw.desc = fmt.Sprintf("%s %s-%s (%s)", days, w.From, w.To, loc)
+	default:
+		return pw, fmt.Errorf("需要指定start/end或from/to")
+	}
+	return pw, nil
+}
+
+// contains 判断时间是否在窗口内
+func (w *window) contains(t time.Time) bool {
+	if !w.start.IsZero() {
+		return !t.Before(w.start) && t.Before(w.end)
+	}
+
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	clock := t.Sub(midnight)
+	if w.from < w.to {
+		return w.onDay(t.Weekday()) && clock >= w.from && clock < w.to
+	}
+	// 跨越零点：当天 from 之后，或前一天开始的窗口在当天 to 之前
+	if clock >= w.from && w.onDay(t.Weekday()) {
+		return true
+	}
+	return clock < w.to && w.onDay((t.Weekday()+6)%7)
+}
+
+// onDay 窗口是否在该星期开始
+func (w *window) onDay(day time.Weekday) bool {
+	return w.days == nil || w.days[day]
+}
+
+// active 返回当前生效的时间窗口说明，规则没有时间窗口时返回空字符串
+func (r *rule) active(now time.Time) (string, bool) {
+	if len(r.windows) == 0 {
+		return "", true
+	}
+	t := now.In(r.loc)
+	for _, w := range r.windows {
+		if w.contains(t) {
+			return w.desc, true
+		}
+	}
+	return "", false
+}
+
+// matches 判断规则是否适用于写操作
+func (r *rule) matches(op *service.WriteOp) bool {
+	if r.actions != nil && !r.actions[op.Action] {
+		return false
+	}
+	if len(r.Domains) > 0 && !slices.ContainsFunc(r.Domains, func(d string) bool {
+		return d == "*" || idn.Equal(d, op.Domain)
+	}) {
+		return false
+	}
+	if r.types != nil && !r.types[strings.ToUpper(op.Type)] && (op.Before == nil || !r.types[op.Before.Type]) {
+		return false
+	}
+	if len(r.RR) > 0 && !r.matchRR(op.RR) && (op.Before == nil || !r.matchRR(op.Before.RR)) {
+		return false
+	}
+	return true
+}
+
+// matchRR 判断主机记录是否匹配通配符
+func (r *rule) matchRR(rr string) bool {
+	if rr == "" {
+		return false
+	}
+	name := strings.ToLower(rr)
+	if ascii, err := idn.ToASCII(rr); err == nil {
+		name = strings.ToLower(ascii)
+	}
+	for _, pattern := range r.RR {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// violation 返回写操作违反规则的原因，不违反时返回空字符串
+func (r *rule) violation(op *service.WriteOp, window string) string {
+	var reason string
+	switch {
+	case r.Deny:
+		reason = "禁止" + describe(op)
+		if window != "" {
+			reason += "，当前处于变更冻结窗口 " + window
+		}
+	case r.ForbidDelete && op.Action == service.WriteDelete:
+		reason = "禁止删除" + target(op)
+	case r.MaxTTLDecrease > 0 && op.Action == service.WriteUpdate && op.Before != nil && op.TTL > 0 &&
+		op.Before.TTL-op.TTL > r.MaxTTLDecrease:
+		reason = fmt.Sprintf("%s的TTL从%d降低到%d，超过一次最多降低%d秒的限制",
+			strings.TrimPrefix(target(op), " "), op.Before.TTL, op.TTL, r.MaxTTLDecrease)
+	default:
+		return ""
+	}
+	if r.Message != "" {
+		reason += "（" + r.Message + "）"
+	}
+	return reason
+}
+
+// Violation 违反的策略规则
+type Violation struct {
+	Rule   string
+	Reason string
+}
+
+// Evaluate 返回写操作违反的所有规则，不考虑紧急变更
+func (e *Engine) Evaluate(op *service.WriteOp) []Violation {
+	now := e.now()
+	actor := actorName(op.Actor.Name)
+
+	var violations []Violation
+	for _, r := range e.rules {
+		if !r.matches(op) || slices.Contains(r.AllowedActors, actor) {
+			continue
+		}
+		window, ok := r.active(now)
+		if !ok {
+			continue
+		}
+		if reason := r.violation(op, window); reason != "" {
+			violations = append(violations, Violation{Rule: r.Name, Reason: reason})
+		}
+	}
+	return violations
+}
+
+// CheckWrite 检查写操作，违反规则时拒绝；携带紧急变更原因时放行并写入审计日志
+func (e *Engine) CheckWrite(op *service.WriteOp) error {
+	violations := e.Evaluate(op)
+	if op.Actor.BreakGlass == "" {
+		if len(violations) == 0 {
+			return nil
+		}
+		v := violations[0]
+		e.log.Warn("写操作被策略拒绝",
+			zap.String("rule", v.Rule),
+			zap.String("actor", actorName(op.Actor.Name)),
+			zap.String("action", op.Action),
+			zap.String("domain", op.Domain),
+			zap.String("rr", op.RR),
+			zap.String("reason", v.Reason),
+		)
+		return apperror.New(http.StatusForbidden, apperror.CodePolicyViolation,
+			fmt.Sprintf("策略 %s 不允许该操作: %s；如确需变更，请使用紧急变更", v.Rule, v.Reason))
+	}
+
+	if op.Actor.Name == "" {
+		return apperror.Unauthorized("紧急变更需要携带访问令牌")
+	}
+	if len(e.breakGlass) > 0 && !slices.Contains(e.breakGlass, op.Actor.Name) {
+		e.record(op, "policy.break_glass_denied", violations)
+		return apperror.Forbidden(fmt.Sprintf("%s 没有紧急变更权限", op.Actor.Name))
+	}
+
+	e.record(op, "policy.break_glass", violations)
+	if len(violations) > 0 {
+		e.notifier.Notify(&notify.Event{
+			Kind:    "policy.break_glass",
+			Title:   "紧急变更越过策略限制",
+			Message: fmt.Sprintf("%s 使用紧急变更%s，原因: %s", op.Actor.Name, describe(op), op.Actor.BreakGlass),
+			Fields: map[string]string{
+				"actor":  op.Actor.Name,
+				"domain": op.Domain,
+				"rules":  ruleNames(violations),
+			},
+		})
+	}
+	return nil
+}
+
+// record 写入紧急变更的审计日志
+func (e *Engine) record(op *service.WriteOp, action string, violations []Violation) {
+	detail := map[string]string{
+		"operation": op.Action,
+		"reason":    op.Actor.BreakGlass,
+	}
+	if op.RR != "" {
+		detail["rr"] = op.RR
+	}
+	if op.Type != "" {
+		detail["type"] = op.Type
+	}
+	if len(violations) > 0 {
+		detail["overridden"] = ruleNames(violations)
+		reasons := make([]string, 0, len(violations))
+		for _, v := range violations {
+			reasons = append(reasons, v.Reason)
+		}
+		detail["violations"] = strings.Join(reasons, "; ")
+	}
+
+	e.audit.Record(&audit.Entry{
+		Actor:     op.Actor.Name,
+		Action:    action,
+		Domain:    op.Domain,
+		Target:    op.RecordId,
+		RequestId: op.Actor.RequestId,
+		Detail:    detail,
+	})
+}
+
+// actorName 内部组件没有身份，按 system 处理
+func actorName(name string) string {
+	if name == "" {
+		return SystemActor
+	}
+	return name
+}
+
+// ruleNames 汇总违反的规则名称
+func ruleNames(violations []Violation) string {
+	names := make([]string, 0, len(violations))
+	for _, v := range violations {
+		names = append(names, v.Rule)
+	}
+	return strings.Join(names, ",")
+}
+
+// describe 描述写操作
+func describe(op *service.WriteOp) string {
+	verbs := map[string]string{
+		service.WriteCreate:     "添加",
+		service.WriteUpdate:     "修改",
+		service.WriteDelete:     "删除",
+		service.WriteEnable:     "启用",
+		service.WriteDisable:    "暂停",
+		service.WriteRemark:     "修改备注",
+		service.WriteSLB:        "修改负载均衡",
+		service.WriteCustomLine: "修改自定义线路",
+		service.WriteDomain:     "添加域名",
+		service.WriteGroup:      "修改域名组",
+	}
+	switch op.Action {
+	case service.WriteCustomLine:
+		return fmt.Sprintf("%s（域名 %s）", verbs[op.Action], op.Domain)
+	case service.WriteDomain:
+		return verbs[op.Action] + " " + op.Domain
+	case service.WriteGroup:
+		return verbs[op.Action] + " " + op.RecordId
+	}
+	return verbs[op.Action] + target(op)
+}
+
+// target 描述写操作的对象
+func target(op *service.WriteOp) string {
+	rr, recordType := op.RR, op.Type
+	if op.Before != nil {
+		rr, recordType = op.Before.RR, op.Before.Type
+	}
+	name := op.Domain
+	if rr != "" && rr != "@" {
+		name = rr + "." + op.Domain
+	}
+	if recordType == "" {
+		return " " + name
+	}
+	return fmt.Sprintf(" %s 的 %s 记录", name, recordType)
+}
@@ -0,0 +1,517 @@
+package schedule
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/batch"
+	"dns-update/internal/notify"
+	"dns-update/internal/service"
+	"dns-update/internal/store"
+	"dns-update/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// 定时任务状态
+const (
+	StatusScheduled = "scheduled"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// 重试次数的上限
+const MaxAttemptsLimit = 10
+
+// StoreFile 定时任务的状态文件名
+const StoreFile = "schedules.json"
+
+// Executor 执行定时任务中的解析记录操作
+type Executor interface {
+	Check(domain string, req *batch.Request) error
+	Execute(ctx context.Context, domain string, req *batch.Request) *batch.Result
+}
+
+// ExecutorFor 返回以指定调用方执行操作的执行器，定时任务以创建者的身份执行
+type ExecutorFor func(actor service.Actor) Executor
+
+// Options 定时任务的选项
+type Options struct {
+	MaxAttempts   int           // 默认的最多执行次数
+	RetryInterval time.Duration // 第 n 次重试前等待 n 倍的间隔
+	MaxDelay      time.Duration // 超过执行时间该时长仍未执行（如服务停机）的任务不再执行
+	Retention     time.Duration // 已结束任务的保留时长
+}
+
+// DefaultOptions 默认的定时任务选项
+var DefaultOptions = Options{
+	MaxAttempts:   3,
+	RetryInterval: time.Minute,
+	MaxDelay:      time.Hour,
+	Retention:     30 * 24 * time.Hour,
+}
+
+// tick 检查到期任务的间隔
+const tick = time.Second
+
+// CreateRequest 创建定时任务的请求
+type CreateRequest struct {
+	RunAt       time.Time         `json:"run_at"`       // 执行时间，RFC3339 格式
+	Operations  []batch.Operation `json:"operations"`   // 与批量操作相同的操作列表
+	Atomic      bool              `json:"atomic"`       // 任一操作失败时回滚已成功的操作
+	MaxAttempts int               `json:"max_attempts"` // 最多执行次数，0 表示使用默认值
+	Note        string            `json:"note"`
+}
+
+// Job 定时任务
+type Job struct {
+	Id          string                  `json:"id"`
+	Domain      string                  `json:"domain"`
+	RunAt       time.Time               `json:"run_at"`
+	Note        string                  `json:"note,omitempty"`
+	Actor       service.Actor           `json:"actor"` // 创建任务的调用方，执行时按其身份做策略检查
+	Operations  []batch.Operation       `json:"operations"`
+	Atomic      bool                    `json:"atomic"`
+	Status      string                  `json:"status"`
+	Attempts    int                     `json:"attempts"`
+	MaxAttempts int                     `json:"max_attempts"`
+	NextRunAt   *time.Time              `json:"next_run_at,omitempty"` // 下一次重试的时间
+	Results     []batch.OperationResult `json:"results,omitempty"`     // 各操作最近一次的执行结果
+	Error       string                  `json:"error,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	FinishedAt  *time.Time              `json:"finished_at,omitempty"`
+}
+
+// Manager 管理定时任务，任务保存在状态文件中，服务重启后继续执行
+type Manager struct {
+	executor ExecutorFor
+	notifier notify.Notifier
+	file     *store.File
+	opts     Options
+	log      *zap.Logger
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewManager 创建定时任务管理器并加载已保存的任务。
+// 服务停止时正在执行的任务结果未知，标记为失败，不会自动重新执行
+func NewManager(executor ExecutorFor, notifier notify.Notifier, file *store.File, opts *Options) (*Manager, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	o := *opts
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultOptions.MaxAttempts
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultOptions.RetryInterval
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = DefaultOptions.MaxDelay
+	}
+	if o.Retention <= 0 {
+		o.Retention = DefaultOptions.Retention
+	}
+
+	m := &Manager{
+		executor: executor,
+		notifier: notifier,
+		file:     file,
+		opts:     o,
+		log:      logger.Named(logger.ComponentScheduler),
+		jobs:     make(map[string]*Job),
+	}
+
+	var jobs []*Job
+	if err := file.Load(&jobs); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, job := range jobs {
+		if job.Status == StatusRunning {
+			job.Status = StatusFailed
+			job.Error = "服务停止时任务正在执行，执行结果未知，请核对解析记录"
+			job.UpdatedAt = now
+			job.FinishedAt = &now
+		}
+		m.jobs[job.Id] = job
+	}
+
+	m.mu.Lock()
+	err := m.saveLocked()
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.log.Info("已加载定时任务",
+		zap.String("file", file.Path()),
+		zap.Int("count", len(jobs)),
+	)
+	return m, nil
+}
+
+// Create 创建定时任务，创建时以 actor 的身份做策略检查，不允许的操作直接拒绝
+func (m *Manager) Create(domain string, req *CreateRequest, actor service.Actor) (*Job, error) {
+	if domain == "" {
+		return nil, apperror.BadRequest("域名不能为空")
+	}
+	if req.RunAt.IsZero() {
+		return nil, apperror.Invalid(apperror.FieldError{Field: "run_at", Message: "执行时间不能为空"})
+	}
+	if !req.RunAt.After(time.Now()) {
+		return nil, apperror.Invalid(apperror.FieldError{Field: "run_at", Message: "执行时间必须晚于当前时间"})
+	}
+	if req.MaxAttempts < 0 || req.MaxAttempts > MaxAttemptsLimit {
+		return nil, apperror.Invalid(apperror.FieldError{
+			Field:   "max_attempts",
+			Message: fmt.Sprintf("max_attempts必须在0-%d之间", MaxAttemptsLimit),
+		})
+	}
+	batchReq := &batch.Request{Operations: req.Operations, Atomic: req.Atomic}
+	if err := batchReq.Validate(); err != nil {
+		return nil, err
+	}
+	if err := m.executor(actor).Check(domain, batchReq); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &Job{
+		Id:          newJobId(),
+		Domain:      domain,
+		RunAt:       req.RunAt,
+		Note:        req.Note,
+		Actor:       actor,
+		Operations:  req.Operations,
+		Atomic:      req.Atomic,
+		Status:      StatusScheduled,
+		MaxAttempts: req.MaxAttempts,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = m.opts.MaxAttempts
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeLocked()
+	m.jobs[job.Id] = job
+	if err := m.saveLocked(); err != nil {
+		delete(m.jobs, job.Id)
+		return nil, apperror.Internal(err)
+	}
+
+	m.log.Info("已创建定时任务",
+		zap.String("job_id", job.Id),
+		zap.String("domain", domain),
+		zap.String("actor", actor.Name),
+		zap.Time("run_at", job.RunAt),
+		zap.Int("operations", len(job.Operations)),
+	)
+	return copyJob(job), nil
+}
+
+// Get 查询定时任务
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, apperror.NotFound("定时任务不存在")
+	}
+	return copyJob(job), nil
+}
+
+// List 按执行时间返回定时任务，status 和 domain 为空时不过滤
+func (m *Manager) List(status, domain string) []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if status != "" && job.Status != status {
+			continue
+		}
+		if domain != "" && !strings.EqualFold(job.Domain, domain) {
+			continue
+		}
+		jobs = append(jobs, copyJob(job))
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].RunAt.Before(jobs[j].RunAt)
+	})
+	return jobs
+}
+
+// Cancel 取消尚未执行的定时任务，等待重试的任务也可以取消
+func (m *Manager) Cancel(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, apperror.NotFound("定时任务不存在")
+	}
+	if job.Status != StatusScheduled {
+		return nil, apperror.Conflict(fmt.Sprintf("定时任务当前状态为%s，无法取消", job.Status))
+	}
+
+	now := time.Now()
+	job.Status = StatusCanceled
+	job.NextRunAt = nil
+	job.UpdatedAt = now
+	job.FinishedAt = &now
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存定时任务失败", zap.Error(err))
+	}
+
+	m.log.Info("已取消定时任务", zap.String("job_id", id))
+	return copyJob(job), nil
+}
+
+// Run 定期执行到期的任务，直到 ctx 取消
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		m.dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch 将到期的任务标记为执行中并逐个启动
+func (m *Manager) dispatch(ctx context.Context) {
+	now := time.Now()
+
+	m.mu.Lock()
+	var due []*Job
+	changed := false
+	for _, job := range m.jobs {
+		if job.Status != StatusScheduled {
+			continue
+		}
+		at := job.RunAt
+		if job.NextRunAt != nil {
+			at = *job.NextRunAt
+		}
+		if at.After(now) {
+			continue
+		}
+
+		if job.Attempts == 0 && now.Sub(job.RunAt) > m.opts.MaxDelay {
+			// 服务停机等原因错过执行时间太久，继续执行可能已不符合预期
+			m.finishLocked(job, StatusFailed, fmt.Sprintf("超过执行时间%s仍未执行，已放弃", m.opts.MaxDelay))
+			changed = true
+			continue
+		}
+
+		job.Status = StatusRunning
+		job.Attempts++
+		job.NextRunAt = nil
+		job.UpdatedAt = now
+		due = append(due, job)
+		changed = true
+	}
+	if changed {
+		if err := m.saveLocked(); err != nil {
+			m.log.Error("保存定时任务失败", zap.Error(err))
+		}
+	}
+	m.mu.Unlock()
+
+	for _, job := range due {
+		go m.execute(ctx, job)
+	}
+}
+
+// execute 执行任务中尚未成功的操作，失败时按错误类型决定是否重试
+func (m *Manager) execute(ctx context.Context, job *Job) {
+	m.mu.Lock()
+	var indexes []int
+	var ops []batch.Operation
+	for i, op := range job.Operations {
+		if i < len(job.Results) && job.Results[i].Status == batch.StatusSucceeded {
+			continue
+		}
+		if op.Record != nil {
+			// 执行时可能补全记录参数，复制一份避免修改保存的任务
+			record := *op.Record
+			op.Record = &record
+		}
+		indexes = append(indexes, i)
+		ops = append(ops, op)
+	}
+	domain, atomic, attempt, actor := job.Domain, job.Atomic, job.Attempts, job.Actor
+	m.mu.Unlock()
+
+	m.log.Info("开始执行定时任务",
+		zap.String("job_id", job.Id),
+		zap.String("domain", domain),
+		zap.Int("attempt", attempt),
+		zap.Int("operations", len(ops)),
+	)
+
+	result := m.executor(actor).Execute(ctx, domain, &batch.Request{Operations: ops, Atomic: atomic})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(job.Results) != len(job.Operations) {
+		job.Results = make([]batch.OperationResult, len(job.Operations))
+		for i, op := range job.Operations {
+			job.Results[i] = batch.OperationResult{Index: i, Action: op.Action, RecordId: op.RecordId, Status: batch.StatusPending}
+		}
+	}
+	for k, r := range result.Results {
+		r.Index = indexes[k]
+		job.Results[indexes[k]] = r
+	}
+
+	if result.Failed == 0 && !result.RolledBack {
+		m.finishLocked(job, StatusSucceeded, "")
+	} else if reason, retry := m.retryable(job, result); retry {
+		next := time.Now().Add(time.Duration(job.Attempts) * m.opts.RetryInterval)
+		job.Status = StatusScheduled
+		job.NextRunAt = &next
+		job.Error = reason
+		job.UpdatedAt = time.Now()
+		m.log.Warn("定时任务执行失败，稍后重试",
+			zap.String("job_id", job.Id),
+			zap.Int("attempt", job.Attempts),
+			zap.Time("next_run_at", next),
+			zap.String("error", reason),
+		)
+	} else {
+		m.finishLocked(job, StatusFailed, reason)
+	}
+
+	if err := m.saveLocked(); err != nil {
+		m.log.Error("保存定时任务失败", zap.Error(err))
+	}
+}
+
+// retryable 汇总失败原因，只有全部失败都是限流或上游临时错误且未超过次数时才重试
+func (m *Manager) retryable(job *Job, result *batch.Result) (string, bool) {
+	var reasons []string
+	retry := job.Attempts < job.MaxAttempts
+	for _, r := range result.Results {
+		switch r.Status {
+		case batch.StatusFailed:
+			reasons = append(reasons, fmt.Sprintf("操作%d: %s", r.Index, r.Error))
+			if !transient(r.Code) {
+				retry = false
+			}
+		case batch.StatusRollbackFailed:
+			reasons = append(reasons, fmt.Sprintf("操作%d回滚失败: %s", r.Index, r.Error))
+			retry = false
+		}
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "操作已回滚")
+	}
+	return strings.Join(reasons, "; "), retry
+}
+
+// finishLocked 结束任务并发送通知，调用方需持有锁
+func (m *Manager) finishLocked(job *Job, status, reason string) {
+	now := time.Now()
+	job.Status = status
+	job.Error = reason
+	job.NextRunAt = nil
+	job.UpdatedAt = now
+	job.FinishedAt = &now
+
+	event := &notify.Event{
+		Kind: "schedule." + status,
+		Fields: map[string]string{
+			"job_id":   job.Id,
+			"domain":   job.Domain,
+			"run_at":   job.RunAt.Format(time.RFC3339),
+			"attempts": strconv.Itoa(job.Attempts),
+		},
+	}
+	if job.Note != "" {
+		event.Fields["note"] = job.Note
+	}
+
+	if status == StatusSucceeded {
+		m.log.Info("定时任务执行成功", zap.String("job_id", job.Id), zap.String("domain", job.Domain))
+		event.Title = "定时变更执行成功"
+		event.Message = fmt.Sprintf("域名 %s 的定时变更已执行，共%d个操作", job.Domain, len(job.Operations))
+	} else {
+		m.log.Error("定时任务执行失败", zap.String("job_id", job.Id), zap.String("error", reason))
+		event.Title = "定时变更执行失败"
+		event.Message = fmt.Sprintf("域名 %s 的定时变更执行失败: %s", job.Domain, reason)
+	}
+	m.notifier.Notify(event)
+}
+
+// saveLocked 保存所有任务，调用方需持有锁
+func (m *Manager) saveLocked() error {
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return m.file.Save(jobs)
+}
+
+// purgeLocked 清理超过保留时长的已结束任务，调用方需持有锁
+func (m *Manager) purgeLocked() {
+	deadline := time.Now().Add(-m.opts.Retention)
+	for id, job := range m.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(deadline) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// transient 判断错误码是否为可重试的临时错误
+func transient(code string) bool {
+	switch {
+	case strings.HasPrefix(code, apperror.CodeThrottling):
+		return true
+	case code == apperror.CodeUpstream, code == apperror.CodeInternal, strings.HasPrefix(code, "ServiceUnavailable"):
+		return true
+	}
+	return false
+}
+
+// copyJob 复制任务，避免调用方与执行中的任务产生竞争
+func copyJob(job *Job) *Job {
+	c := *job
+	c.Operations = append([]batch.Operation(nil), job.Operations...)
+	c.Results = append([]batch.OperationResult(nil), job.Results...)
+	return &c
+}
+
+// newJobId 生成随机任务ID
+func newJobId() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
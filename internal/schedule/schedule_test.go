@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/batch"
+	"dns-update/internal/notify"
+	"dns-update/internal/service"
+	"dns-update/internal/store"
+)
+
+// fakeExecutor 记录执行时的调用方，denied 中的调用方检查不通过
+type fakeExecutor struct {
+	actor  service.Actor
+	denied map[string]bool
+	ran    chan service.Actor
+}
+
+func (e *fakeExecutor) Check(string, *batch.Request) error {
+	if e.denied[e.actor.Name] {
+		return apperror.Forbidden("变更冻结期间不允许修改")
+	}
+	return nil
+}
+
+func (e *fakeExecutor) Execute(_ context.Context, _ string, req *batch.Request) *batch.Result {
+	e.ran <- e.actor
+	results := make([]batch.OperationResult, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = batch.OperationResult{Index: i, Action: op.Action, RecordId: op.RecordId, Status: batch.StatusSucceeded}
+	}
+	return &batch.Result{Total: len(results), Succeeded: len(results), Results: results}
+}
+
+// nopNotifier 丢弃所有通知
+type nopNotifier struct{}
+
+func (*nopNotifier) Notify(*notify.Event) {}
+
+func newTestManager(t *testing.T, denied map[string]bool) (*Manager, chan service.Actor) {
+	t.Helper()
+	file, err := store.NewFile(t.TempDir(), StoreFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := make(chan service.Actor, 1)
+	executorFor := func(actor service.Actor) Executor {
+		return &fakeExecutor{actor: actor, denied: denied, ran: ran}
+	}
+	m, err := NewManager(executorFor, &nopNotifier{}, file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, ran
+}
+
+func TestJobRunsAsCreator(t *testing.T) {
+	m, ran := newTestManager(t, map[string]bool{"ci": true})
+	req := &CreateRequest{
+		RunAt:      time.Now().Add(time.Hour),
+		Operations: []batch.Operation{{Action: batch.ActionDisable, RecordId: "1"}},
+	}
+
+	if _, err := m.Create("example.com", req, service.Actor{Name: "ci"}); !apperror.HasCode(err, apperror.CodeForbidden) {
+		t.Fatalf("创建时应按调用方做策略检查，得到 %v", err)
+	}
+	if len(m.List("", "")) != 0 {
+		t.Fatal("策略检查未通过的任务不应保存")
+	}
+
+	creator := service.Actor{Name: "alice", RequestId: "req-1"}
+	job, err := m.Create("example.com", req, creator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 提前到期后执行
+	m.mu.Lock()
+	m.jobs[job.Id].RunAt = time.Now()
+	m.mu.Unlock()
+	m.dispatch(context.Background())
+
+	select {
+	case actor := <-ran:
+		if actor != creator {
+			t.Errorf("执行时的调用方 = %+v，期望 %+v", actor, creator)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("到期任务未执行")
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"sync"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/validation"
+
+	"go.uber.org/zap"
+)
+
+// 跨域名搜索的并发度限制
+const (
+	DefaultCrossSearchConcurrency = 5
+	MaxCrossSearchConcurrency     = 20
+)
+
+// CrossDomainQuery 跨域名搜索条件
+type CrossDomainQuery struct {
+	Value       string // 记录值关键字
+	ValueExact  bool   // 记录值是否精确匹配
+	Type        string // 记录类型
+	RRPattern   string // 主机记录，支持 * 和 ? 通配符
+	Status      string // 状态(Enable/Disable)
+	Concurrency int    // 并发查询的域名数
+}
+
+// DomainSearchResult 单个域名的搜索结果
+type DomainSearchResult struct {
+	DomainName string         `json:"domain_name"`
+	Records    []DomainRecord `json:"records"`
+	Code       string         `json:"code,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// CrossDomainResult 跨域名搜索结果
+type CrossDomainResult struct {
+	DomainsSearched int                  `json:"domains_searched"`
+	DomainsFailed   int                  `json:"domains_failed"`
+	MatchedRecords  int                  `json:"matched_records"`
+	Results         []DomainSearchResult `json:"results"`
+}
+
+// Validate 校验跨域名搜索条件
+func (q *CrossDomainQuery) Validate() error {
+	var errs validation.Errors
+
+	if q.Value == "" && q.Type == "" && q.RRPattern == "" && q.Status == "" {
+		errs.Add("value", errors.New("至少需要指定一个搜索条件"))
+	}
+	if q.Type != "" {
+		if err := validation.ValidateQueryType(q.Type); err != nil {
+			errs.Add("type", err)
+		}
+	}
+	if q.RRPattern != "" {
+		if _, err := path.Match(q.RRPattern, ""); err != nil {
+			errs.Add("rr", errors.New("主机记录通配符格式错误"))
+		}
+	}
+	if q.Status != "" && !strings.EqualFold(q.Status, RecordStatusEnable) && !strings.EqualFold(q.Status, RecordStatusDisable) {
+		errs.Add("status", errors.New("status必须是Enable或Disable"))
+	}
+	if q.Concurrency < 0 || q.Concurrency > MaxCrossSearchConcurrency {
+		errs.Add("concurrency", errors.New("concurrency必须在1-20之间"))
+	}
+
+	return errs.Err()
+}
+
+// recordQuery 生成单个域名的查询条件，尽量让接口完成过滤
+func (q *CrossDomainQuery) recordQuery(domainName string) *RecordQuery {
+	query := &RecordQuery{
+		DomainName:   domainName,
+		Type:         q.Type,
+		Status:       q.Status,
+		ValueKeyWord: q.Value,
+		PageSize:     MaxRecordQueryPageSize,
+	}
+	// 不含通配符的主机记录直接精确匹配
+	if q.RRPattern != "" && !strings.ContainsAny(q.RRPattern, "*?[") {
+		query.RR = q.RRPattern
+	}
+	return query
+}
+
+// match 本地过滤接口无法表达的条件
+func (q *CrossDomainQuery) match(r *DomainRecord) bool {
+	if q.ValueExact && !strings.EqualFold(strings.TrimSuffix(r.Value, "."), strings.TrimSuffix(q.Value, ".")) {
+		return false
+	}
+	if q.RRPattern != "" && strings.ContainsAny(q.RRPattern, "*?[") {
+		matched, _ := path.Match(strings.ToLower(q.RRPattern), strings.ToLower(r.RR))
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchAllDomains 在账户下所有域名中搜索解析记录，单个域名失败不影响其他域名
+func (s *DNSService) SearchAllDomains(query *CrossDomainQuery) (*CrossDomainResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	concurrency := query.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultCrossSearchConcurrency
+	}
+
+	domains, err := s.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("正在跨域名搜索解析记录",
+		zap.Int("domains", len(domains)),
+		zap.String("value", query.Value),
+		zap.String("type", query.Type),
+		zap.String("rr", query.RRPattern),
+		zap.String("status", query.Status),
+		zap.Int("concurrency", concurrency),
+	)
+
+	results := make([]DomainSearchResult, len(domains))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, d := range domains {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, domainName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := DomainSearchResult{DomainName: domainName, Records: []DomainRecord{}}
+			records, err := s.QueryDomainRecords(query.recordQuery(domainName))
+			if err != nil {
+				appErr := apperror.From(err)
+				result.Code = appErr.Code
+				result.Error = appErr.Message
+			}
+			for _, r := range records {
+				if query.match(&r) {
+					result.Records = append(result.Records, r)
+				}
+			}
+			results[i] = result
+		}(i, d.DomainName)
+	}
+	wg.Wait()
+
+	// 只返回有匹配记录或查询失败的域名，保持域名列表的顺序
+	result := &CrossDomainResult{
+		DomainsSearched: len(domains),
+		Results:         make([]DomainSearchResult, 0),
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			result.DomainsFailed++
+		}
+		if r.Error == "" && len(r.Records) == 0 {
+			continue
+		}
+		result.MatchedRecords += len(r.Records)
+		result.Results = append(result.Results, r)
+	}
+
+	s.log.Info("跨域名搜索解析记录完成",
+		zap.Int("domains_searched", result.DomainsSearched),
+		zap.Int("domains_failed", result.DomainsFailed),
+		zap.Int("matched_records", result.MatchedRecords),
+	)
+	return result, nil
+}
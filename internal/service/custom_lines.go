@@ -0,0 +1,419 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/validation"
+
+	dns "github.com/alibabacloud-go/alidns-20150109/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"go.uber.org/zap"
+)
+
+// customLinesPageSize 获取自定义线路时每页的数量
+const customLinesPageSize int64 = 100
+
+// IPSegment 自定义线路的地址段，起止地址均包含在内
+type IPSegment struct {
+	StartIp string `json:"start_ip"`
+	EndIp   string `json:"end_ip"`
+}
+
+// CustomLine 按地址段定义的自定义解析线路
+type CustomLine struct {
+	Id         int64       `json:"id"`
+	Code       string      `json:"code"` // 创建和修改解析记录时使用的线路代码
+	Name       string      `json:"name"`
+	DomainName string      `json:"domain_name,omitempty"`
+	Segments   []IPSegment `json:"segments,omitempty"`
+}
+
+// CustomLineInput 添加或修改自定义线路的参数
+type CustomLineInput struct {
+	Name     string   `json:"name"`
+	Segments []string `json:"segments"` // CIDR(10.0.0.0/8)、单个地址或起止地址(10.0.0.1-10.0.0.9)
+}
+
+// ipRange 解析后的地址段
+type ipRange struct {
+	start, end netip.Addr
+	text       string
+}
+
+// overlaps 判断两个地址段是否有重叠，不同地址族不会重叠
+func (r ipRange) overlaps(o ipRange) bool {
+	if r.start.Is4() != o.start.Is4() {
+		return false
+	}
+	return r.start.Compare(o.end) <= 0 && o.start.Compare(r.end) <= 0
+}
+
+// ListCustomLines 获取域名下的自定义线路及其地址段
+func (s *DNSService) ListCustomLines(domainName string) ([]CustomLine, error) {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("正在获取自定义线路", zap.String("domain", domainName))
+
+	var lines []CustomLine
+	for page := int64(1); ; page++ {
+		req := &dns.DescribeCustomLinesRequest{
+			DomainName: tea.String(domainName),
+			PageNumber: tea.Int64(page),
+			PageSize:   tea.Int64(customLinesPageSize),
+		}
+
+		s.throttle()
+		resp, err := s.client.DescribeCustomLines(req)
+		if err != nil {
+			s.log.Error("获取自定义线路失败",
+				zap.String("domain", domainName),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+
+		for _, item := range resp.Body.CustomLines {
+			// 列表接口不返回地址段，需要逐条查询
+			line, err := s.GetCustomLine(tea.Int64Value(item.Id))
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, *line)
+		}
+
+		if len(resp.Body.CustomLines) == 0 || page >= int64(tea.Int32Value(resp.Body.TotalPages)) {
+			break
+		}
+	}
+
+	s.log.Info("获取自定义线路成功",
+		zap.String("domain", domainName),
+		zap.Int("count", len(lines)),
+	)
+	return lines, nil
+}
+
+// GetCustomLine 获取自定义线路的详细信息
+func (s *DNSService) GetCustomLine(lineId int64) (*CustomLine, error) {
+	if lineId <= 0 {
+		return nil, apperror.BadRequest("线路ID无效")
+	}
+
+	req := &dns.DescribeCustomLineRequest{
+		LineId: tea.Int64(lineId),
+	}
+
+	s.throttle()
+	resp, err := s.client.DescribeCustomLine(req)
+	if err != nil {
+		s.log.Error("获取自定义线路详情失败",
+			zap.Int64("line_id", lineId),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	line := &CustomLine{
+		Id:         tea.Int64Value(resp.Body.Id),
+		Code:       tea.StringValue(resp.Body.Code),
+		Name:       tea.StringValue(resp.Body.Name),
+		DomainName: tea.StringValue(resp.Body.DomainName),
+	}
+	for _, seg := range resp.Body.IpSegmentList {
+		line.Segments = append(line.Segments, IPSegment{
+			StartIp: tea.StringValue(seg.StartIp),
+			EndIp:   tea.StringValue(seg.EndIp),
+		})
+	}
+	return line, nil
+}
+
+// AddCustomLine 添加自定义线路，地址段不能与域名下其他自定义线路重叠
+func (s *DNSService) AddCustomLine(domainName string, input *CustomLineInput) (*CustomLine, error) {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return nil, err
+	}
+	ranges, err := input.validate()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCustomLineOverlap(domainName, 0, ranges); err != nil {
+		return nil, err
+	}
+	if err := s.checkWrite(&WriteOp{Action: WriteCustomLine, Domain: domainName}); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("正在添加自定义线路",
+		zap.String("domain", domainName),
+		zap.String("name", input.Name),
+		zap.Int("segments", len(ranges)),
+	)
+
+	req := &dns.AddCustomLineRequest{
+		DomainName: tea.String(domainName),
+		LineName:   tea.String(input.Name),
+	}
+	for _, r := range ranges {
+		req.IpSegment = append(req.IpSegment, &dns.AddCustomLineRequestIpSegment{
+			StartIp: tea.String(r.start.String()),
+			EndIp:   tea.String(r.end.String()),
+		})
+	}
+
+	s.throttle()
+	resp, err := s.client.AddCustomLine(req)
+	if err != nil {
+		s.log.Error("添加自定义线路失败",
+			zap.String("domain", domainName),
+			zap.String("name", input.Name),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	s.invalidateLines(domainName)
+
+	line := &CustomLine{
+		Id:         tea.Int64Value(resp.Body.LineId),
+		Code:       tea.StringValue(resp.Body.LineCode),
+		Name:       input.Name,
+		DomainName: domainName,
+		Segments:   segments(ranges),
+	}
+	s.log.Info("添加自定义线路成功",
+		zap.String("domain", domainName),
+		zap.Int64("line_id", line.Id),
+		zap.String("code", line.Code),
+	)
+	return line, nil
+}
+
+// UpdateCustomLine 修改自定义线路的名称和地址段，地址段整体替换
+func (s *DNSService) UpdateCustomLine(domainName string, lineId int64, input *CustomLineInput) error {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return err
+	}
+	if lineId <= 0 {
+		return apperror.BadRequest("线路ID无效")
+	}
+	ranges, err := input.validate()
+	if err != nil {
+		return err
+	}
+	if err := s.checkCustomLineOverlap(domainName, lineId, ranges); err != nil {
+		return err
+	}
+	if err := s.checkWrite(&WriteOp{Action: WriteCustomLine, Domain: domainName}); err != nil {
+		return err
+	}
+
+	s.log.Info("正在修改自定义线路",
+		zap.String("domain", domainName),
+		zap.Int64("line_id", lineId),
+		zap.Int("segments", len(ranges)),
+	)
+
+	req := &dns.UpdateCustomLineRequest{
+		LineId:   tea.Int64(lineId),
+		LineName: tea.String(input.Name),
+	}
+	for _, r := range ranges {
+		req.IpSegment = append(req.IpSegment, &dns.UpdateCustomLineRequestIpSegment{
+			StartIp: tea.String(r.start.String()),
+			EndIp:   tea.String(r.end.String()),
+		})
+	}
+
+	s.throttle()
+	if _, err := s.client.UpdateCustomLine(req); err != nil {
+		s.log.Error("修改自定义线路失败",
+			zap.Int64("line_id", lineId),
+			zap.Error(err),
+		)
+		return err
+	}
+	s.invalidateLines(domainName)
+
+	s.log.Info("修改自定义线路成功", zap.Int64("line_id", lineId))
+	return nil
+}
+
+// DeleteCustomLine 删除自定义线路
+func (s *DNSService) DeleteCustomLine(domainName string, lineId int64) error {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return err
+	}
+	if lineId <= 0 {
+		return apperror.BadRequest("线路ID无效")
+	}
+	if err := s.checkWrite(&WriteOp{Action: WriteCustomLine, Domain: domainName}); err != nil {
+		return err
+	}
+
+	s.log.Info("正在删除自定义线路", zap.Int64("line_id", lineId))
+
+	req := &dns.DeleteCustomLinesRequest{
+		LineIds: tea.String(strconv.FormatInt(lineId, 10)),
+	}
+
+	s.throttle()
+	if _, err := s.client.DeleteCustomLines(req); err != nil {
+		s.log.Error("删除自定义线路失败",
+			zap.Int64("line_id", lineId),
+			zap.Error(err),
+		)
+		return err
+	}
+	s.invalidateLines(domainName)
+
+	s.log.Info("删除自定义线路成功", zap.Int64("line_id", lineId))
+	return nil
+}
+
+// checkCustomLineOverlap 检查地址段是否与域名下其他自定义线路重叠，excludeId 为正在修改的线路
+func (s *DNSService) checkCustomLineOverlap(domainName string, excludeId int64, ranges []ipRange) error {
+	lines, err := s.ListCustomLines(domainName)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		if line.Id == excludeId {
+			continue
+		}
+		for _, seg := range line.Segments {
+			other, err := parseIPSegment(seg.StartIp + "-" + seg.EndIp)
+			if err != nil {
+				// 上游返回的地址段无法解析时跳过，不影响其他线路的检查
+				continue
+			}
+			for _, r := range ranges {
+				if r.overlaps(other) {
+					return apperror.Conflict(fmt.Sprintf("地址段 %s 与自定义线路 %s(%s) 的 %s-%s 重叠",
+						r.text, line.Name, line.Code, seg.StartIp, seg.EndIp))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validate 校验线路名称和地址段，返回按起始地址排序的地址段
+func (in *CustomLineInput) validate() ([]ipRange, error) {
+	var errs validation.Errors
+
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		errs.Add("name", errors.New("线路名称不能为空"))
+	}
+	if len(in.Segments) == 0 {
+		errs.Add("segments", errors.New("地址段不能为空"))
+	}
+
+	var ranges []ipRange
+	for i, text := range in.Segments {
+		r, err := parseIPSegment(text)
+		if err != nil {
+			errs.Add(fmt.Sprintf("segments[%d]", i), err)
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	// 同一线路内的地址段也不能重叠
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].start.Is4() != ranges[j].start.Is4() {
+			return ranges[i].start.Is4()
+		}
+		return ranges[i].start.Less(ranges[j].start)
+	})
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i-1].overlaps(ranges[i]) {
+			return nil, apperror.Invalid(apperror.FieldError{
+				Field:   "segments",
+				Message: fmt.Sprintf("地址段 %s 与 %s 重叠", ranges[i-1].text, ranges[i].text),
+			})
+		}
+	}
+	return ranges, nil
+}
+
+// parseIPSegment 解析 CIDR、单个地址或起止地址
+func parseIPSegment(text string) (ipRange, error) {
+	text = strings.TrimSpace(text)
+	r := ipRange{text: text}
+
+	switch {
+	case strings.Contains(text, "/"):
+		prefix, err := netip.ParsePrefix(text)
+		if err != nil {
+			return r, fmt.Errorf("无效的CIDR: %s", text)
+		}
+		prefix = prefix.Masked()
+		r.start, r.end = prefix.Addr(), lastAddr(prefix)
+
+	case strings.Contains(text, "-"):
+		start, end, _ := strings.Cut(text, "-")
+		var err error
+		if r.start, err = netip.ParseAddr(strings.TrimSpace(start)); err != nil {
+			return r, fmt.Errorf("无效的起始地址: %s", start)
+		}
+		if r.end, err = netip.ParseAddr(strings.TrimSpace(end)); err != nil {
+			return r, fmt.Errorf("无效的结束地址: %s", end)
+		}
+
+	default:
+		addr, err := netip.ParseAddr(text)
+		if err != nil {
+			return r, fmt.Errorf("无效的地址段: %s", text)
+		}
+		r.start, r.end = addr, addr
+	}
+
+	r.start, r.end = r.start.Unmap(), r.end.Unmap()
+	if r.start.Zone() != "" || r.end.Zone() != "" {
+		return r, fmt.Errorf("地址段不能包含区域: %s", text)
+	}
+	if r.start.Is4() != r.end.Is4() {
+		return r, fmt.Errorf("起止地址必须属于同一地址族: %s", text)
+	}
+	if r.end.Less(r.start) {
+		return r, fmt.Errorf("结束地址不能小于起始地址: %s", text)
+	}
+	return r, nil
+}
+
+// lastAddr 返回网段中的最后一个地址
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Addr()
+	bytes := addr.AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 0x80 >> (bit % 8)
+	}
+	last, _ := netip.AddrFromSlice(bytes)
+	return last
+}
+
+// segments 将地址段转换为接口返回的形式
+func segments(ranges []ipRange) []IPSegment {
+	result := make([]IPSegment, 0, len(ranges))
+	for _, r := range ranges {
+		result = append(result, IPSegment{StartIp: r.start.String(), EndIp: r.end.String()})
+	}
+	return result
+}
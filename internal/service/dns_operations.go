@@ -1,6 +1,8 @@
 package service
 
 import (
+	"dns-update/pkg/redact"
+
 	dns "github.com/alibabacloud-go/alidns-20150109/v2/client"
 	console "github.com/alibabacloud-go/tea-console/client"
 	util "github.com/alibabacloud-go/tea-utils/service"
@@ -12,9 +14,10 @@ func (s *DNSService) DescribeDomains() error {
 	req := &dns.DescribeDomainsRequest{}
 	console.Log(tea.String("查询域名列表(json)↓"))
 
+	s.throttle()
 	resp, err := s.client.DescribeDomains(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
@@ -27,13 +30,18 @@ func (s *DNSService) AddDomain(domainName *string) error {
 	req := &dns.AddDomainRequest{
 		DomainName: domainName,
 	}
+	if err := s.checkWrite(&WriteOp{Action: WriteDomain, Domain: tea.StringValue(domainName)}); err != nil {
+		return err
+	}
 	console.Log(tea.String("云解析添加域名(" + tea.StringValue(domainName) + ")的结果(json)↓"))
 
+	s.throttle()
 	resp, err := s.client.AddDomain(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
+	s.invalidateZones()
 
 	console.Log(util.ToJSONString(tea.ToMap(resp)))
 	return nil
@@ -46,9 +54,10 @@ func (s *DNSService) DescribeDomainRecords(domainName *string) error {
 	}
 	console.Log(tea.String("查询域名(" + tea.StringValue(domainName) + ")的解析记录(json)↓"))
 
+	s.throttle()
 	resp, err := s.client.DescribeDomainRecords(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
@@ -63,9 +72,10 @@ func (s *DNSService) DescribeRecordLogs(domainName *string) error {
 	}
 	console.Log(tea.String("查询域名(" + tea.StringValue(domainName) + ")的解析记录日志(json)↓"))
 
+	s.throttle()
 	resp, err := s.client.DescribeRecordLogs(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
@@ -80,9 +90,10 @@ func (s *DNSService) DescribeDomainRecordByRecordId(recordId *string) error {
 	}
 	console.Log(tea.String("查询RecordId:" + tea.StringValue(recordId) + "的域名解析记录信息(json)↓"))
 
+	s.throttle()
 	resp, err := s.client.DescribeDomainRecordInfo(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
@@ -97,87 +108,13 @@ func (s *DNSService) DescribeDomainInfo(domainName *string) error {
 	}
 	console.Log(tea.String("查询域名:" + tea.StringValue(domainName) + "的信息(json)↓"))
 
+	s.throttle()
 	resp, err := s.client.DescribeDomainInfo(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
-		return err
-	}
-
-	console.Log(util.ToJSONString(tea.ToMap(resp)))
-	return nil
-}
-
-// AddDomainRecord 添加域名解析记录
-func (s *DNSService) AddDomainRecord(domainName, RR, recordType, value *string) error {
-	req := &dns.AddDomainRecordRequest{
-		DomainName: domainName,
-		RR:         RR,
-		Type:       recordType,
-		Value:      value,
-	}
-
-	resp, err := s.client.AddDomainRecord(req)
-	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
-	console.Log(tea.String("添加域名解析记录的结果(json)↓"))
-	console.Log(util.ToJSONString(tea.ToMap(resp)))
-	return nil
-}
-
-// UpdateDomainRecord 更新域名解析记录
-func (s *DNSService) UpdateDomainRecord(recordId, RR, recordType, value *string) error {
-	req := &dns.UpdateDomainRecordRequest{
-		RecordId: recordId,
-		RR:       RR,
-		Type:     recordType,
-		Value:    value,
-	}
-
-	resp, err := s.client.UpdateDomainRecord(req)
-	if err != nil {
-		console.Log(tea.String(err.Error()))
-		return err
-	}
-
-	console.Log(tea.String("更新域名解析记录的结果(json)↓"))
-	console.Log(util.ToJSONString(tea.ToMap(resp)))
-	return nil
-}
-
-// SetDomainRecordStatus 设置域名解析状态
-func (s *DNSService) SetDomainRecordStatus(recordId, status *string) error {
-	req := &dns.SetDomainRecordStatusRequest{
-		RecordId: recordId,
-		Status:   status,
-	}
-
-	resp, err := s.client.SetDomainRecordStatus(req)
-	if err != nil {
-		console.Log(tea.String(err.Error()))
-		return err
-	}
-
-	console.Log(tea.String("设置域名解析状态的结果(json)↓"))
-	console.Log(util.ToJSONString(tea.ToMap(resp)))
-	return nil
-}
-
-// DeleteDomainRecord 删除域名解析记录
-func (s *DNSService) DeleteDomainRecord(recordId *string) error {
-	req := &dns.DeleteDomainRecordRequest{
-		RecordId: recordId,
-	}
-
-	resp, err := s.client.DeleteDomainRecord(req)
-	if err != nil {
-		console.Log(tea.String(err.Error()))
-		return err
-	}
-
-	console.Log(tea.String("删除域名解析记录的结果(json)↓"))
 	console.Log(util.ToJSONString(tea.ToMap(resp)))
 	return nil
 }
@@ -186,9 +123,10 @@ func (s *DNSService) DeleteDomainRecord(recordId *string) error {
 func (s *DNSService) DescribeDomainGroups() error {
 	req := &dns.DescribeDomainGroupsRequest{}
 
+	s.throttle()
 	resp, err := s.client.DescribeDomainGroups(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
@@ -202,10 +140,14 @@ func (s *DNSService) AddDomainGroup(groupName *string) error {
 	req := &dns.AddDomainGroupRequest{
 		GroupName: groupName,
 	}
+	if err := s.checkWrite(&WriteOp{Action: WriteGroup, RecordId: tea.StringValue(groupName)}); err != nil {
+		return err
+	}
 
+	s.throttle()
 	resp, err := s.client.AddDomainGroup(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
@@ -220,10 +162,14 @@ func (s *DNSService) UpdateDomainGroup(groupId, groupName *string) error {
 		GroupId:   groupId,
 		GroupName: groupName,
 	}
+	if err := s.checkWrite(&WriteOp{Action: WriteGroup, RecordId: tea.StringValue(groupId)}); err != nil {
+		return err
+	}
 
+	s.throttle()
 	resp, err := s.client.UpdateDomainGroup(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
@@ -237,10 +183,14 @@ func (s *DNSService) DeleteDomainGroup(groupId *string) error {
 	req := &dns.DeleteDomainGroupRequest{
 		GroupId: groupId,
 	}
+	if err := s.checkWrite(&WriteOp{Action: WriteGroup, RecordId: tea.StringValue(groupId)}); err != nil {
+		return err
+	}
 
+	s.throttle()
 	resp, err := s.client.DeleteDomainGroup(req)
 	if err != nil {
-		console.Log(tea.String(err.Error()))
+		console.Log(tea.String(redact.String(err.Error())))
 		return err
 	}
 
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"dns-update/internal/apperror"
+	"dns-update/internal/validation"
+
+	dns "github.com/alibabacloud-go/alidns-20150109/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"go.uber.org/zap"
+)
+
+// 解析记录状态
+const (
+	RecordStatusEnable  = "Enable"
+	RecordStatusDisable = "Disable"
+)
+
+// DomainRecordInput 添加或修改解析记录的参数
+type DomainRecordInput struct {
+	RR       string `json:"rr"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	TTL      int64  `json:"ttl"`      // 0 表示使用默认值
+	Priority int64  `json:"priority"` // 仅MX记录使用
+	Line     string `json:"line"`     // 解析线路，为空表示默认线路，修改时为空会重置为默认线路
+}
+
+// Validate 校验解析记录参数，国际化名称按转换后的 ASCII 形式校验
+func (in *DomainRecordInput) Validate() error {
+	normalized := *in
+	if err := normalized.normalize(); err != nil {
+		return err
+	}
+	return validation.ValidateRecord(validation.Record{
+		RR:       normalized.RR,
+		Type:     normalized.Type,
+		Value:    normalized.Value,
+		TTL:      normalized.TTL,
+		Priority: normalized.Priority,
+	})
+}
+
+// AddDomainRecord 添加域名解析记录，返回新记录的ID
+func (s *DNSService) AddDomainRecord(domainName string, input *DomainRecordInput) (string, error) {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return "", err
+	}
+	if err := input.normalize(); err != nil {
+		return "", err
+	}
+	if err := input.Validate(); err != nil {
+		return "", err
+	}
+	if err := s.ValidateLine(domainName, input.Line); err != nil {
+		return "", err
+	}
+	if err := s.checkWrite(&WriteOp{
+		Action: WriteCreate,
+		Domain: domainName,
+		RR:     input.RR,
+		Type:   input.Type,
+		TTL:    input.TTL,
+	}); err != nil {
+		return "", err
+	}
+
+	s.log.Info("正在添加解析记录",
+		zap.String("domain", domainName),
+		zap.String("rr", input.RR),
+		zap.String("type", input.Type),
+		zap.String("line", input.Line),
+	)
+
+	req := &dns.AddDomainRecordRequest{
+		DomainName: tea.String(domainName),
+		RR:         tea.String(input.RR),
+		Type:       tea.String(input.Type),
+		Value:      tea.String(input.Value),
+	}
+	if input.TTL != 0 {
+		req.TTL = tea.Int64(input.TTL)
+	}
+	if input.Priority != 0 {
+		req.Priority = tea.Int64(input.Priority)
+	}
+	if input.Line != "" {
+		req.Line = tea.String(input.Line)
+	}
+
+	s.throttle()
+	resp, err := s.client.AddDomainRecord(req)
+	if err != nil {
+		s.log.Error("添加解析记录失败",
+			zap.String("domain", domainName),
+			zap.String("rr", input.RR),
+			zap.Error(err),
+		)
+		return "", err
+	}
+
+	recordId := tea.StringValue(resp.Body.RecordId)
+	s.log.Info("添加解析记录成功",
+		zap.String("domain", domainName),
+		zap.String("record_id", recordId),
+	)
+	return recordId, nil
+}
+
+// UpdateDomainRecord 修改解析记录
+func (s *DNSService) UpdateDomainRecord(recordId string, input *DomainRecordInput) error {
+	if recordId == "" {
+		return apperror.BadRequest("记录ID不能为空")
+	}
+	return s.updateDomainRecord(recordId, nil, input)
+}
+
+// UpdateDomainRecordFrom 修改调用方已查询的解析记录，校验线路和策略检查时不再重复查询
+func (s *DNSService) UpdateDomainRecordFrom(before *DomainRecord, input *DomainRecordInput) error {
+	if before == nil || before.RecordId == "" {
+		return apperror.BadRequest("记录ID不能为空")
+	}
+	return s.updateDomainRecord(before.RecordId, before, input)
+}
+
+// updateDomainRecord 修改解析记录，before 为空时在需要时查询一次修改前的记录
+func (s *DNSService) updateDomainRecord(recordId string, before *DomainRecord, input *DomainRecordInput) error {
+	if err := input.normalize(); err != nil {
+		return err
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	// 线路按记录所属的域名校验，策略检查也需要修改前的记录，只查询一次
+	if before == nil && (s.guard != nil || (input.Line != "" && input.Line != DefaultLine)) {
+		var err error
+		if before, err = s.GetDomainRecordById(recordId); err != nil {
+			return err
+		}
+	}
+	if input.Line != "" && input.Line != DefaultLine {
+		if err := s.ValidateLine(before.DomainName, input.Line); err != nil {
+			return err
+		}
+	}
+	if err := s.checkWriteOn(WriteUpdate, before, input); err != nil {
+		return err
+	}
+
+	s.log.Info("正在修改解析记录",
+		zap.String("record_id", recordId),
+		zap.String("rr", input.RR),
+		zap.String("type", input.Type),
+		zap.String("line", input.Line),
+	)
+
+	req := &dns.UpdateDomainRecordRequest{
+		RecordId: tea.String(recordId),
+		RR:       tea.String(input.RR),
+		Type:     tea.String(input.Type),
+		Value:    tea.String(input.Value),
+	}
+	if input.TTL != 0 {
+		req.TTL = tea.Int64(input.TTL)
+	}
+	if input.Priority != 0 {
+		req.Priority = tea.Int64(input.Priority)
+	}
+	if input.Line != "" {
+		req.Line = tea.String(input.Line)
+	}
+
+	s.throttle()
+	if _, err := s.client.UpdateDomainRecord(req); err != nil {
+		s.log.Error("修改解析记录失败",
+			zap.String("record_id", recordId),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.log.Info("修改解析记录成功", zap.String("record_id", recordId))
+	return nil
+}
+
+// SetDomainRecordStatus 设置解析记录状态
+func (s *DNSService) SetDomainRecordStatus(recordId, status string) error {
+	if recordId == "" {
+		return apperror.BadRequest("记录ID不能为空")
+	}
+	if status != RecordStatusEnable && status != RecordStatusDisable {
+		return apperror.BadRequest("状态必须是Enable或Disable")
+	}
+	action := WriteEnable
+	if status == RecordStatusDisable {
+		action = WriteDisable
+	}
+	if err := s.checkRecordWrite(action, recordId, nil); err != nil {
+		return err
+	}
+
+	s.log.Info("正在设置解析记录状态",
+		zap.String("record_id", recordId),
+		zap.String("status", status),
+	)
+
+	req := &dns.SetDomainRecordStatusRequest{
+		RecordId: tea.String(recordId),
+		Status:   tea.String(status),
+	}
+
+	s.throttle()
+	if _, err := s.client.SetDomainRecordStatus(req); err != nil {
+		s.log.Error("设置解析记录状态失败",
+			zap.String("record_id", recordId),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.log.Info("设置解析记录状态成功",
+		zap.String("record_id", recordId),
+		zap.String("status", status),
+	)
+	return nil
+}
+
+// SetDomainRecordRemark 设置解析记录的备注
+func (s *DNSService) SetDomainRecordRemark(recordId, remark string) error {
+	if recordId == "" {
+		return apperror.BadRequest("记录ID不能为空")
+	}
+	if err := s.checkRecordWrite(WriteRemark, recordId, nil); err != nil {
+		return err
+	}
+
+	req := &dns.UpdateDomainRecordRemarkRequest{
+		RecordId: tea.String(recordId),
+		Remark:   tea.String(remark),
+	}
+
+	s.throttle()
+	if _, err := s.client.UpdateDomainRecordRemark(req); err != nil {
+		s.log.Error("设置解析记录备注失败",
+			zap.String("record_id", recordId),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.log.Info("设置解析记录备注成功", zap.String("record_id", recordId))
+	return nil
+}
+
+// DeleteDomainRecord 删除解析记录
+func (s *DNSService) DeleteDomainRecord(recordId string) error {
+	if recordId == "" {
+		return apperror.BadRequest("记录ID不能为空")
+	}
+	if err := s.checkRecordWrite(WriteDelete, recordId, nil); err != nil {
+		return err
+	}
+
+	s.log.Info("正在删除解析记录", zap.String("record_id", recordId))
+
+	req := &dns.DeleteDomainRecordRequest{
+		RecordId: tea.String(recordId),
+	}
+
+	s.throttle()
+	if _, err := s.client.DeleteDomainRecord(req); err != nil {
+		s.log.Error("删除解析记录失败",
+			zap.String("record_id", recordId),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.log.Info("删除解析记录成功", zap.String("record_id", recordId))
+	return nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"strings"
+
+	"dns-update/internal/apperror"
+	"dns-update/pkg/idn"
+)
+
+// hostnameValueTypes 记录值为主机名的记录类型
+var hostnameValueTypes = map[string]bool{
+	"CNAME": true,
+	"NS":    true,
+	"MX":    true,
+}
+
+// normalizeDomainName 将域名规范化为 ASCII 形式，支持传入 Unicode 或 Punycode
+func normalizeDomainName(domainName string) (string, error) {
+	ascii, err := idn.ToASCII(domainName)
+	if err != nil {
+		return "", apperror.BadRequest(err.Error())
+	}
+	return strings.TrimSuffix(ascii, "."), nil
+}
+
+// normalizeRR 将主机记录规范化为 ASCII 形式
+func normalizeRR(rr string) (string, error) {
+	ascii, err := idn.ToASCII(rr)
+	if err != nil {
+		return "", apperror.BadRequest(err.Error())
+	}
+	return ascii, nil
+}
+
+// normalize 将记录中的主机记录以及主机名类型的记录值规范化为 ASCII 形式
+func (in *DomainRecordInput) normalize() error {
+	rr, err := normalizeRR(in.RR)
+	if err != nil {
+		return err
+	}
+	in.RR = rr
+
+	if hostnameValueTypes[in.Type] {
+		value, err := idn.ToASCII(in.Value)
+		if err != nil {
+			return apperror.BadRequest(err.Error())
+		}
+		in.Value = value
+	}
+	return nil
+}
+
+// annotateDomain 补充域名的 ASCII 和 Unicode 形式
+func annotateDomain(d *Domain) {
+	if d.PunyCode == "" {
+		if ascii, err := idn.ToASCII(d.DomainName); err == nil {
+			d.PunyCode = ascii
+		}
+	}
+	d.UnicodeName = idn.ToUnicode(d.PunyCode)
+	if d.UnicodeName == "" {
+		d.UnicodeName = d.DomainName
+	}
+}
+
+// annotateRecord 为国际化的主机记录和记录值补充 Unicode 形式
+func annotateRecord(r *DomainRecord) {
+	if idn.IsIDN(r.RR) {
+		r.RRUnicode = idn.ToUnicode(r.RR)
+	}
+	if hostnameValueTypes[r.Type] && idn.IsIDN(r.Value) {
+		r.ValueUnicode = idn.ToUnicode(r.Value)
+	}
+}
+
+// annotateRecords 为记录列表补充 Unicode 形式
+func annotateRecords(records []DomainRecord) []DomainRecord {
+	for i := range records {
+		annotateRecord(&records[i])
+	}
+	return records
+}
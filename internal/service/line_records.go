@@ -0,0 +1,297 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/validation"
+
+	"go.uber.org/zap"
+)
+
+// LineRecordSet 按解析线路展开的一条逻辑记录，每个线路对应一组记录值
+type LineRecordSet struct {
+	RR       string              `json:"rr"`
+	Type     string              `json:"type"`
+	TTL      int64               `json:"ttl"`
+	Priority int64               `json:"priority,omitempty"` // 仅MX记录使用
+	Lines    map[string][]string `json:"lines"`              // 线路代码 -> 记录值
+}
+
+// LineRecordSetInput 设置逻辑记录的参数，必须包含默认线路
+type LineRecordSetInput struct {
+	TTL      int64               `json:"ttl"`      // 0 表示使用默认值
+	Priority int64               `json:"priority"` // 仅MX记录使用
+	Lines    map[string][]string `json:"lines"`    // 线路代码 -> 记录值
+}
+
+// LineRecordSetResult 设置逻辑记录的结果
+type LineRecordSetResult struct {
+	Added   int            `json:"added"`
+	Updated int            `json:"updated"`
+	Deleted int            `json:"deleted"`
+	Set     *LineRecordSet `json:"set"`
+}
+
+// GetLineRecordSet 获取主机记录和类型下各解析线路的记录值
+func (s *DNSService) GetLineRecordSet(domainName, rr, recordType string) (*LineRecordSet, error) {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return nil, err
+	}
+	if rr, err = normalizeRR(rr); err != nil {
+		return nil, err
+	}
+	recordType = strings.ToUpper(recordType)
+	if err := validation.ValidateQueryType(recordType); err != nil {
+		return nil, apperror.BadRequest(err.Error())
+	}
+
+	records, err := s.QueryDomainRecords(&RecordQuery{
+		DomainName: domainName,
+		RR:         rr,
+		Type:       recordType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lineRecordSet(rr, recordType, records), nil
+}
+
+// ApplyLineRecordSet 将逻辑记录展开为各线路的解析记录，并与现有记录比对后增删改。
+// 同一线路下多余的记录优先改为缺少的记录值，其次新增缺少的记录（默认线路最先），最后删除多余的记录。
+// 中途失败时直接返回错误，已完成的修改不会回滚，重新提交即可继续
+func (s *DNSService) ApplyLineRecordSet(domainName, rr, recordType string, input *LineRecordSetInput) (*LineRecordSetResult, error) {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return nil, err
+	}
+	if rr, err = normalizeRR(rr); err != nil {
+		return nil, err
+	}
+	recordType = strings.ToUpper(recordType)
+
+	desired, err := s.expandLineRecordSet(domainName, rr, recordType, input)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.QueryDomainRecords(&RecordQuery{
+		DomainName: domainName,
+		RR:         rr,
+		Type:       recordType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("正在设置分线路解析记录",
+		zap.String("domain", domainName),
+		zap.String("rr", rr),
+		zap.String("type", recordType),
+		zap.Int("lines", len(input.Lines)),
+	)
+
+	// 按线路拆分出需要新增的记录值和多余的记录，保留的记录检查 TTL 是否需要修改
+	result := &LineRecordSetResult{}
+	missing := make(map[string][]*DomainRecordInput)
+	unwanted := make(map[string][]DomainRecord)
+	var stale []DomainRecord
+	for _, record := range existing {
+		line := recordLine(record.Line)
+		want, ok := desired[lineValueKey(line, record.Value)]
+		if !ok {
+			unwanted[line] = append(unwanted[line], record)
+			continue
+		}
+		delete(desired, lineValueKey(line, record.Value))
+		if (want.TTL != 0 && record.TTL != want.TTL) || (recordType == validation.TypeMX && record.Priority != want.Priority) {
+			stale = append(stale, record)
+		}
+	}
+	for _, in := range desired {
+		missing[in.Line] = append(missing[in.Line], in)
+	}
+
+	for _, line := range orderedLines(missing) {
+		for _, in := range missing[line] {
+			if records := unwanted[line]; len(records) > 0 {
+				// 复用同一线路下多余的记录，也避免 CNAME 在同一线路上冲突
+				if err := s.UpdateDomainRecord(records[0].RecordId, in); err != nil {
+					return nil, err
+				}
+				unwanted[line] = records[1:]
+				result.Updated++
+				continue
+			}
+			if _, err := s.AddDomainRecord(domainName, in); err != nil {
+				return nil, err
+			}
+			result.Added++
+		}
+	}
+
+	for _, record := range stale {
+		in := &DomainRecordInput{
+			RR:       rr,
+			Type:     recordType,
+			Value:    record.Value,
+			TTL:      input.TTL,
+			Priority: input.Priority,
+			Line:     recordLine(record.Line),
+		}
+		if err := s.UpdateDomainRecord(record.RecordId, in); err != nil {
+			return nil, err
+		}
+		result.Updated++
+	}
+
+	for _, line := range orderedLines(unwanted) {
+		for _, record := range unwanted[line] {
+			if err := s.DeleteDomainRecord(record.RecordId); err != nil {
+				return nil, err
+			}
+			result.Deleted++
+		}
+	}
+
+	s.log.Info("设置分线路解析记录成功",
+		zap.String("domain", domainName),
+		zap.String("rr", rr),
+		zap.Int("added", result.Added),
+		zap.Int("updated", result.Updated),
+		zap.Int("deleted", result.Deleted),
+	)
+
+	if result.Set, err = s.GetLineRecordSet(domainName, rr, recordType); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// expandLineRecordSet 校验逻辑记录并展开为按线路和记录值索引的写入参数
+func (s *DNSService) expandLineRecordSet(domainName, rr, recordType string, input *LineRecordSetInput) (map[string]*DomainRecordInput, error) {
+	var errs validation.Errors
+	if len(input.Lines[DefaultLine]) == 0 {
+		errs.Add("lines", errors.New("必须包含默认线路(default)的记录值"))
+	}
+
+	expanded := make(map[string]*DomainRecordInput)
+	for _, line := range orderedLines(input.Lines) {
+		field := "lines." + line
+		values := input.Lines[line]
+		if len(values) == 0 {
+			errs.Add(field, errors.New("记录值不能为空"))
+			continue
+		}
+		if recordType == validation.TypeCNAME && len(values) > 1 {
+			errs.Add(field, errors.New("同一线路只能有一条CNAME记录"))
+			continue
+		}
+		if err := s.ValidateLine(domainName, line); err != nil {
+			if appErr := apperror.From(err); appErr.Status != http.StatusBadRequest {
+				return nil, err
+			}
+			errs = append(errs, fieldErrors(field, err)...)
+			continue
+		}
+
+		for i, value := range values {
+			valueField := fmt.Sprintf("%s[%d]", field, i)
+			in := &DomainRecordInput{
+				RR:       rr,
+				Type:     recordType,
+				Value:    value,
+				TTL:      input.TTL,
+				Priority: input.Priority,
+				Line:     line,
+			}
+			if err := in.normalize(); err != nil {
+				errs = append(errs, fieldErrors(valueField, err)...)
+				continue
+			}
+			if err := in.Validate(); err != nil {
+				errs = append(errs, fieldErrors(valueField, err)...)
+				continue
+			}
+			key := lineValueKey(line, in.Value)
+			if _, ok := expanded[key]; ok {
+				errs.Add(valueField, fmt.Errorf("记录值重复: %s", value))
+				continue
+			}
+			expanded[key] = in
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// fieldErrors 将错误转换为带前缀的字段错误
+func fieldErrors(prefix string, err error) []apperror.FieldError {
+	appErr := apperror.From(err)
+	if len(appErr.Fields) == 0 {
+		return []apperror.FieldError{{Field: prefix, Message: appErr.Message}}
+	}
+	fields := make([]apperror.FieldError, 0, len(appErr.Fields))
+	for _, f := range appErr.Fields {
+		fields = append(fields, apperror.FieldError{Field: prefix + "." + f.Field, Message: f.Message})
+	}
+	return fields
+}
+
+// lineRecordSet 将同一主机记录和类型的解析记录按线路归并
+func lineRecordSet(rr, recordType string, records []DomainRecord) *LineRecordSet {
+	set := &LineRecordSet{
+		RR:    rr,
+		Type:  recordType,
+		Lines: make(map[string][]string),
+	}
+	for _, record := range records {
+		line := recordLine(record.Line)
+		set.Lines[line] = append(set.Lines[line], record.Value)
+		// TTL 和优先级以默认线路的记录为准
+		if set.TTL == 0 || line == DefaultLine {
+			set.TTL = record.TTL
+			set.Priority = record.Priority
+		}
+	}
+	for line := range set.Lines {
+		sort.Strings(set.Lines[line])
+	}
+	return set
+}
+
+// recordLine 接口返回的线路为空时视为默认线路
+func recordLine(line string) string {
+	if line == "" {
+		return DefaultLine
+	}
+	return line
+}
+
+// lineValueKey 线路和记录值组成的索引，记录值不区分大小写
+func lineValueKey(line, value string) string {
+	return line + "\x00" + strings.ToLower(value)
+}
+
+// orderedLines 返回排序后的线路，默认线路排在最前
+func orderedLines[T any](lines map[string]T) []string {
+	keys := make([]string, 0, len(lines))
+	for line := range lines {
+		keys = append(keys, line)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == DefaultLine || keys[j] == DefaultLine {
+			return keys[i] == DefaultLine && keys[j] != DefaultLine
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+
+	dns "github.com/alibabacloud-go/alidns-20150109/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"go.uber.org/zap"
+)
+
+// DefaultLine 默认解析线路
+const DefaultLine = "default"
+
+// linesCacheTTL 解析线路列表的缓存时长，线路只在域名版本或自定义线路变化时改变
+const linesCacheTTL = 10 * time.Minute
+
+// RecordLine 解析线路
+type RecordLine struct {
+	Code        string        `json:"code"`
+	Name        string        `json:"name"`
+	DisplayName string        `json:"display_name"`
+	FatherCode  string        `json:"father_code,omitempty"`
+	Children    []*RecordLine `json:"children,omitempty"`
+}
+
+// linesCache 单个域名的解析线路缓存
+type linesCache struct {
+	lines     []RecordLine
+	fetchedAt time.Time
+}
+
+// lineCaches 按域名缓存的解析线路
+type lineCaches struct {
+	mu      sync.Mutex
+	domains map[string]*linesCache
+}
+
+// ListRecordLines 获取域名版本支持的解析线路（包括自定义线路），结果缓存一段时间
+func (s *DNSService) ListRecordLines(domainName string) ([]RecordLine, error) {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lines.mu.Lock()
+	cached, ok := s.lines.domains[domainName]
+	s.lines.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < linesCacheTTL {
+		return cached.lines, nil
+	}
+
+	s.log.Info("正在获取解析线路", zap.String("domain", domainName))
+
+	req := &dns.DescribeDomainInfoRequest{
+		DomainName:           tea.String(domainName),
+		NeedDetailAttributes: tea.Bool(true),
+	}
+
+	s.throttle()
+	resp, err := s.client.DescribeDomainInfo(req)
+	if err != nil {
+		s.log.Error("获取解析线路失败",
+			zap.String("domain", domainName),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	var lines []RecordLine
+	if resp.Body.RecordLines != nil {
+		for _, l := range resp.Body.RecordLines.RecordLine {
+			lines = append(lines, RecordLine{
+				Code:        tea.StringValue(l.LineCode),
+				Name:        tea.StringValue(l.LineName),
+				DisplayName: tea.StringValue(l.LineDisplayName),
+				FatherCode:  tea.StringValue(l.FatherCode),
+			})
+		}
+	}
+
+	s.lines.mu.Lock()
+	s.lines.domains[domainName] = &linesCache{lines: lines, fetchedAt: time.Now()}
+	s.lines.mu.Unlock()
+
+	s.log.Info("获取解析线路成功",
+		zap.String("domain", domainName),
+		zap.Int("count", len(lines)),
+	)
+	return lines, nil
+}
+
+// RecordLineTree 以树的形式返回域名支持的解析线路
+func (s *DNSService) RecordLineTree(domainName string) ([]*RecordLine, error) {
+	lines, err := s.ListRecordLines(domainName)
+	if err != nil {
+		return nil, err
+	}
+	return buildLineTree(lines), nil
+}
+
+// ValidateLine 校验域名是否支持解析线路，空值和默认线路总是有效
+func (s *DNSService) ValidateLine(domainName, line string) error {
+	if line == "" || line == DefaultLine {
+		return nil
+	}
+
+	lines, err := s.ListRecordLines(domainName)
+	if err != nil {
+		return err
+	}
+	for _, l := range lines {
+		if l.Code == line {
+			return nil
+		}
+	}
+	return apperror.BadRequest(fmt.Sprintf("域名不支持解析线路: %s", line))
+}
+
+// invalidateLines 清除域名的解析线路缓存，自定义线路变化后调用
+func (s *DNSService) invalidateLines(domainName string) {
+	s.lines.mu.Lock()
+	delete(s.lines.domains, domainName)
+	s.lines.mu.Unlock()
+}
+
+// buildLineTree 按 FatherCode 组织线路，父线路不在列表中的作为根节点
+func buildLineTree(lines []RecordLine) []*RecordLine {
+	nodes := make(map[string]*RecordLine, len(lines))
+	for i := range lines {
+		line := lines[i]
+		nodes[line.Code] = &line
+	}
+
+	var roots []*RecordLine
+	for i := range lines {
+		node := nodes[lines[i].Code]
+		if parent, ok := nodes[node.FatherCode]; ok && node.FatherCode != node.Code {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
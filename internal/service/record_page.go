@@ -0,0 +1,158 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"dns-update/internal/validation"
+
+	"go.uber.org/zap"
+)
+
+// 支持排序的字段
+const (
+	SortFieldRR    = "rr"
+	SortFieldType  = "type"
+	SortFieldTTL   = "ttl"
+	SortFieldValue = "value"
+)
+
+// RecordSortKey 排序条件
+type RecordSortKey struct {
+	Field string // 排序字段(rr/type/ttl/value)
+	Desc  bool   // 是否降序
+}
+
+// ParseRecordSort 解析排序参数，格式为逗号分隔的字段列表，字段前加 - 表示降序，如 "rr,-ttl"
+func ParseRecordSort(sort string) ([]RecordSortKey, error) {
+	if sort == "" {
+		return nil, nil
+	}
+
+	var keys []RecordSortKey
+	var errs validation.Errors
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		key := RecordSortKey{Field: strings.ToLower(strings.TrimPrefix(field, "-")), Desc: strings.HasPrefix(field, "-")}
+		switch key.Field {
+		case SortFieldRR, SortFieldType, SortFieldTTL, SortFieldValue:
+			keys = append(keys, key)
+		default:
+			errs.Add("sort", fmt.Errorf("不支持的排序字段: %s，可选 rr/type/ttl/value", field))
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SortRecords 按排序条件对记录进行稳定排序
+func SortRecords(records []DomainRecord, keys []RecordSortKey) {
+	if len(keys) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(records, func(a, b DomainRecord) int {
+		for _, key := range keys {
+			var c int
+			switch key.Field {
+			case SortFieldRR:
+				c = cmp.Compare(a.RR, b.RR)
+			case SortFieldType:
+				c = cmp.Compare(a.Type, b.Type)
+			case SortFieldTTL:
+				c = cmp.Compare(a.TTL, b.TTL)
+			case SortFieldValue:
+				c = cmp.Compare(a.Value, b.Value)
+			}
+			if key.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// RecordPage 单页解析记录
+type RecordPage struct {
+	Records []DomainRecord
+	Total   int64 // 匹配的记录总数
+}
+
+// QueryDomainRecordsPage 按查询条件获取指定页的解析记录
+//
+// 没有排序和本地过滤条件时直接按 page/perPage 请求对应的上游分页，
+// 否则获取全部匹配记录后在本地排序并截取
+func (s *DNSService) QueryDomainRecordsPage(query *RecordQuery, page, perPage int64, sort []RecordSortKey) (*RecordPage, error) {
+	var errs validation.Errors
+	if page < 1 || page > MaxRecordQueryPage {
+		errs.Add("page", fmt.Errorf("page必须在1-%d之间", MaxRecordQueryPage))
+	}
+	if perPage < 1 || perPage > MaxRecordQueryPageSize {
+		errs.Add("per_page", fmt.Errorf("per_page必须在1-%d之间", MaxRecordQueryPageSize))
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(sort) == 0 && query.RR == "" && query.RecordId == "" {
+		q, err := s.prepareQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		records, total, err := s.fetchRecordPage(q, perPage, page)
+		if err != nil {
+			return nil, err
+		}
+		return &RecordPage{Records: annotateRecords(records), Total: total}, nil
+	}
+
+	records, err := s.QueryDomainRecords(query)
+	if err != nil {
+		return nil, err
+	}
+	SortRecords(records, sort)
+
+	total := int64(len(records))
+	from := max(min((page-1)*perPage, total), 0)
+	to := min(from+perPage, total)
+	return &RecordPage{Records: records[from:to], Total: total}, nil
+}
+
+// StreamDomainRecords 按查询条件逐条回调匹配的解析记录
+//
+// 分页按顺序到达后立即回调，调用方无需等待最后一页即可开始处理。
+// 回调返回错误时停止获取后续分页并返回该错误
+func (s *DNSService) StreamDomainRecords(query *RecordQuery, emit func(*DomainRecord) error) error {
+	q, err := s.prepareQuery(query)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = s.forEachRecordPage(q, func(page []DomainRecord) error {
+		for i := range page {
+			annotateRecord(&page[i])
+			if err := emit(&page[i]); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("流式查询域名解析记录完成",
+		zap.String("domain", q.DomainName),
+		zap.Int("count", count),
+	)
+	return nil
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"dns-update/internal/validation"
+
+	dns "github.com/alibabacloud-go/alidns-20150109/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"go.uber.org/zap"
+)
+
+// 搜索模式
+const (
+	SearchModeLike     = "LIKE"
+	SearchModeExact    = "EXACT"
+	SearchModeAdvanced = "ADVANCED"
+)
+
+// 排序方向
+const (
+	DirectionAsc  = "ASC"
+	DirectionDesc = "DESC"
+)
+
+// RecordQuery 解析记录查询条件，覆盖 DescribeDomainRecords 的全部过滤参数
+//
+// KeyWord 在 LIKE/EXACT 模式下同时匹配主机记录和记录值；
+// RRKeyWord、TypeKeyWord、ValueKeyWord 只在 ADVANCED 模式下生效，
+// 指定了这些条件而未指定 SearchMode 时自动使用 ADVANCED 模式
+type RecordQuery struct {
+	DomainName   string // 域名
+	KeyWord      string // 关键字
+	RRKeyWord    string // 主机记录关键字
+	TypeKeyWord  string // 记录类型关键字
+	ValueKeyWord string // 记录值关键字
+	Type         string // 记录类型
+	Line         string // 解析线路
+	Status       string // 状态(Enable/Disable)
+	SearchMode   string // 搜索模式(LIKE/EXACT/ADVANCED)
+	OrderBy      string // 排序字段
+	Direction    string // 排序方向(ASC/DESC)
+	PageSize     int64  // 每页记录数
+
+	// 以下条件在本地过滤
+	RR       string // 精确匹配的主机记录
+	RecordId string // 解析记录ID
+}
+
+// DefaultRecordQueryPageSize 默认每页记录数，取接口允许的最大值以减少请求次数
+const DefaultRecordQueryPageSize int64 = 500
+
+// MaxRecordQueryPageSize 接口允许的最大每页记录数
+const MaxRecordQueryPageSize int64 = 500
+
+// MaxRecordQueryPage 允许的最大页码，保证 (page-1)*perPage 不溢出
+const MaxRecordQueryPage = math.MaxInt64 / MaxRecordQueryPageSize
+
+// Validate 校验查询条件
+func (q *RecordQuery) Validate() error {
+	var errs validation.Errors
+
+	if q.DomainName == "" {
+		errs.Add("domain", errors.New("域名不能为空"))
+	}
+	if q.Type != "" {
+		if err := validation.ValidateQueryType(q.Type); err != nil {
+			errs.Add("type", err)
+		}
+	}
+	if q.Status != "" && !strings.EqualFold(q.Status, RecordStatusEnable) && !strings.EqualFold(q.Status, RecordStatusDisable) {
+		errs.Add("status", errors.New("status必须是Enable或Disable"))
+	}
+	switch strings.ToUpper(q.SearchMode) {
+	case "", SearchModeLike, SearchModeExact, SearchModeAdvanced:
+	default:
+		errs.Add("search_mode", errors.New("search_mode必须是LIKE、EXACT或ADVANCED"))
+	}
+	switch strings.ToUpper(q.Direction) {
+	case "", DirectionAsc, DirectionDesc:
+	default:
+		errs.Add("direction", errors.New("direction必须是ASC或DESC"))
+	}
+	if q.PageSize < 0 || q.PageSize > MaxRecordQueryPageSize {
+		errs.Add("page_size", errors.New("page_size必须在1-500之间"))
+	}
+
+	return errs.Err()
+}
+
+// searchMode 计算实际使用的搜索模式
+func (q *RecordQuery) searchMode() string {
+	if q.SearchMode != "" {
+		return strings.ToUpper(q.SearchMode)
+	}
+	if q.RRKeyWord != "" || q.TypeKeyWord != "" || q.ValueKeyWord != "" || q.RR != "" {
+		return SearchModeAdvanced
+	}
+	return ""
+}
+
+// request 将查询条件转换为 DescribeDomainRecords 请求
+func (q *RecordQuery) request(pageSize int64) *dns.DescribeDomainRecordsRequest {
+	req := &dns.DescribeDomainRecordsRequest{
+		DomainName: tea.String(q.DomainName),
+		PageSize:   tea.Int64(pageSize),
+	}
+
+	optional := func(value string) *string {
+		if value == "" {
+			return nil
+		}
+		return tea.String(value)
+	}
+
+	rrKeyWord := q.RRKeyWord
+	if rrKeyWord == "" {
+		// 精确主机记录先按关键字缩小范围，再在本地精确过滤
+		rrKeyWord = q.RR
+	}
+
+	req.KeyWord = optional(q.KeyWord)
+	req.RRKeyWord = optional(rrKeyWord)
+	req.TypeKeyWord = optional(q.TypeKeyWord)
+	req.ValueKeyWord = optional(q.ValueKeyWord)
+	req.Type = optional(q.Type)
+	req.Line = optional(q.Line)
+	req.Status = optional(canonicalStatus(q.Status))
+	req.SearchMode = optional(q.searchMode())
+	req.OrderBy = optional(q.OrderBy)
+	req.Direction = optional(strings.ToUpper(q.Direction))
+	return req
+}
+
+// match 本地过滤接口不支持的精确条件
+func (q *RecordQuery) match(r *DomainRecord) bool {
+	if q.RR != "" && !strings.EqualFold(r.RR, q.RR) {
+		return false
+	}
+	if q.RecordId != "" && r.RecordId != q.RecordId {
+		return false
+	}
+	return true
+}
+
+// QueryDomainRecords 按查询条件获取全部匹配的解析记录
+func (s *DNSService) QueryDomainRecords(query *RecordQuery) ([]DomainRecord, error) {
+	q, err := s.prepareQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.paginateRecords(q)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("查询域名解析记录成功",
+		zap.String("domain", q.DomainName),
+		zap.Int("count", len(records)),
+	)
+	return annotateRecords(records), nil
+}
+
+// prepareQuery 规范化并校验查询条件，返回副本
+func (s *DNSService) prepareQuery(query *RecordQuery) (*RecordQuery, error) {
+	q := *query
+	if q.PageSize == 0 {
+		q.PageSize = DefaultRecordQueryPageSize
+	}
+
+	domainName, err := normalizeDomainName(q.DomainName)
+	if err != nil {
+		return nil, err
+	}
+	q.DomainName = domainName
+	if q.RR, err = normalizeRR(q.RR); err != nil {
+		return nil, err
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("正在查询域名解析记录",
+		zap.String("domain", q.DomainName),
+		zap.String("keyword", q.KeyWord),
+		zap.String("rr", q.RR),
+		zap.String("rr_keyword", q.RRKeyWord),
+		zap.String("type", q.Type),
+		zap.String("type_keyword", q.TypeKeyWord),
+		zap.String("value_keyword", q.ValueKeyWord),
+		zap.String("line", q.Line),
+		zap.String("status", q.Status),
+		zap.String("search_mode", q.searchMode()),
+		zap.Int64("page_size", q.PageSize),
+	)
+	return &q, nil
+}
+
+// paginateRecords 获取所有分页并合并为一个列表
+func (s *DNSService) paginateRecords(q *RecordQuery) ([]DomainRecord, error) {
+	records := make([]DomainRecord, 0)
+	err := s.forEachRecordPage(q, func(page []DomainRecord) error {
+		records = append(records, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// forEachRecordPage 获取所有分页，按页码顺序逐页回调
+//
+// 先请求第一页得到 TotalCount，再在限流范围内并发获取剩余分页。
+// 调用方指定的每页记录数不足以一次取完时，改用接口允许的最大值重新分页。
+// 已获取但尚未回调的分页数不超过并发数，回调返回错误时停止获取后续分页。
+// 回调收到的记录已在本地过滤，并按记录ID去重以应对翻页期间的记录变动
+func (s *DNSService) forEachRecordPage(q *RecordQuery, emit func([]DomainRecord) error) error {
+	seen := make(map[string]struct{})
+	emitPage := func(page []DomainRecord) error {
+		records := make([]DomainRecord, 0, len(page))
+		for _, record := range page {
+			if _, ok := seen[record.RecordId]; ok {
+				continue
+			}
+			seen[record.RecordId] = struct{}{}
+			if q.match(&record) {
+				records = append(records, record)
+			}
+		}
+		return emit(records)
+	}
+
+	pageSize := q.PageSize
+	first, totalCount, err := s.fetchRecordPage(q, pageSize, 1)
+	if err != nil {
+		return err
+	}
+
+	totalPages := pageCount(totalCount, pageSize)
+	if totalPages <= 1 {
+		return emitPage(first)
+	}
+
+	startPage := int64(2)
+	if pageSize < MaxRecordQueryPageSize {
+		// 第一页按原大小获取，与新的分页边界不对齐，需要从第一页重新获取
+		pageSize = MaxRecordQueryPageSize
+		totalPages = pageCount(totalCount, pageSize)
+		startPage = 1
+	} else if err := emitPage(first); err != nil {
+		return err
+	}
+
+	s.log.Debug("并发获取域名解析记录分页",
+		zap.String("domain", q.DomainName),
+		zap.Int64("total_records", totalCount),
+		zap.Int64("total_pages", totalPages),
+		zap.Int64("page_size", pageSize),
+		zap.Int("concurrency", s.pageConcurrency),
+	)
+
+	type pageResult struct {
+		records []DomainRecord
+		err     error
+	}
+	results := make([]chan pageResult, totalPages-startPage+1)
+	for i := range results {
+		results[i] = make(chan pageResult, 1)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// 按页码顺序派发，分页回调完成后才释放名额
+	sem := make(chan struct{}, s.pageConcurrency)
+	go func() {
+		for i := range results {
+			select {
+			case <-done:
+				return
+			case sem <- struct{}{}:
+			}
+			go func(i int) {
+				records, _, err := s.fetchRecordPage(q, pageSize, startPage+int64(i))
+				results[i] <- pageResult{records: records, err: err}
+			}(i)
+		}
+	}()
+
+	for i := range results {
+		result := <-results[i]
+		if result.err != nil {
+			return result.err
+		}
+		if err := emitPage(result.records); err != nil {
+			return err
+		}
+		<-sem
+	}
+	return nil
+}
+
+// fetchRecordPage 获取单个分页，返回该页记录和记录总数
+func (s *DNSService) fetchRecordPage(q *RecordQuery, pageSize, pageNumber int64) ([]DomainRecord, int64, error) {
+	req := q.request(pageSize)
+	req.PageNumber = tea.Int64(pageNumber)
+
+	s.throttle()
+	resp, err := s.client.DescribeDomainRecords(req)
+	if err != nil {
+		s.log.Error("获取域名解析记录失败",
+			zap.String("domain", q.DomainName),
+			zap.Int64("page", pageNumber),
+			zap.Error(err),
+		)
+		return nil, 0, err
+	}
+
+	records := make([]DomainRecord, 0, len(resp.Body.DomainRecords.Record))
+	for _, r := range resp.Body.DomainRecords.Record {
+		records = append(records, recordFromSDK(r))
+	}
+	totalCount := tea.Int64Value(resp.Body.TotalCount)
+
+	s.log.Debug("获取域名解析记录分页信息",
+		zap.String("domain", q.DomainName),
+		zap.Int64("current_page", pageNumber),
+		zap.Int("current_records", len(records)),
+		zap.Int64("total_records", totalCount),
+	)
+	return records, totalCount, nil
+}
+
+// pageCount 计算分页数量
+func pageCount(totalCount, pageSize int64) int64 {
+	return (totalCount + pageSize - 1) / pageSize
+}
+
+// canonicalStatus 将状态统一为接口要求的 Enable/Disable 形式
+func canonicalStatus(status string) string {
+	switch {
+	case strings.EqualFold(status, RecordStatusEnable):
+		return RecordStatusEnable
+	case strings.EqualFold(status, RecordStatusDisable):
+		return RecordStatusDisable
+	}
+	return status
+}
+
+// recordFromSDK 将接口返回的记录转换为 DomainRecord
+func recordFromSDK(r *dns.DescribeDomainRecordsResponseBodyDomainRecordsRecord) DomainRecord {
+	return DomainRecord{
+		RecordId:   tea.StringValue(r.RecordId),
+		DomainName: tea.StringValue(r.DomainName),
+		RR:         tea.StringValue(r.RR),
+		Type:       tea.StringValue(r.Type),
+		Value:      tea.StringValue(r.Value),
+		Status:     tea.StringValue(r.Status),
+		Locked:     tea.BoolValue(r.Locked),
+		Line:       tea.StringValue(r.Line),
+		Priority:   tea.Int64Value(r.Priority),
+		TTL:        tea.Int64Value(r.TTL),
+		Remark:     tea.StringValue(r.Remark),
+		Weight:     tea.Int32Value(r.Weight),
+	}
+}
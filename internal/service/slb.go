@@ -0,0 +1,264 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/validation"
+
+	dns "github.com/alibabacloud-go/alidns-20150109/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"go.uber.org/zap"
+)
+
+// 负载均衡权重的范围
+const (
+	MinSLBWeight = 1
+	MaxSLBWeight = 100
+)
+
+// slbTypes 支持权重负载均衡的记录类型
+var slbTypes = map[string]bool{
+	validation.TypeA:     true,
+	validation.TypeAAAA:  true,
+	validation.TypeCNAME: true,
+}
+
+// SLBSubDomain 开启过负载均衡的子域名
+type SLBSubDomain struct {
+	SubDomain   string    `json:"sub_domain"`
+	Type        string    `json:"type"`
+	RecordCount int64     `json:"record_count"`
+	Open        bool      `json:"open"`
+	Lines       []SLBLine `json:"lines,omitempty"` // 各解析线路的负载均衡状态
+}
+
+// SLBLine 解析线路的负载均衡状态
+type SLBLine struct {
+	Line string `json:"line"`
+	Open bool   `json:"open"`
+}
+
+// SLBMembers 子域名的负载均衡成员
+type SLBMembers struct {
+	SubDomain string         `json:"sub_domain"`
+	Type      string         `json:"type"`
+	Open      bool           `json:"open"`
+	Records   []DomainRecord `json:"records"`
+}
+
+// ValidateSLBWeight 校验负载均衡权重
+func ValidateSLBWeight(weight int32) error {
+	if weight < MinSLBWeight || weight > MaxSLBWeight {
+		return apperror.BadRequest(fmt.Sprintf("权重必须在%d到%d之间", MinSLBWeight, MaxSLBWeight))
+	}
+	return nil
+}
+
+// ListSLBSubDomains 获取域名下开启过负载均衡的子域名，rr 不为空时只返回该主机记录
+func (s *DNSService) ListSLBSubDomains(domainName, rr string) ([]SLBSubDomain, error) {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return nil, err
+	}
+	if rr, err = normalizeRR(rr); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("正在获取负载均衡子域名",
+		zap.String("domain", domainName),
+		zap.String("rr", rr),
+	)
+
+	var subDomains []SLBSubDomain
+	for page := int64(1); ; page++ {
+		req := &dns.DescribeDNSSLBSubDomainsRequest{
+			DomainName: tea.String(domainName),
+			PageNumber: tea.Int64(page),
+			PageSize:   tea.Int64(MaxRecordQueryPageSize),
+		}
+		if rr != "" {
+			req.Rr = tea.String(rr)
+		}
+
+		s.throttle()
+		resp, err := s.client.DescribeDNSSLBSubDomains(req)
+		if err != nil {
+			s.log.Error("获取负载均衡子域名失败",
+				zap.String("domain", domainName),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+
+		var items []*dns.DescribeDNSSLBSubDomainsResponseBodySlbSubDomainsSlbSubDomain
+		if resp.Body.SlbSubDomains != nil {
+			items = resp.Body.SlbSubDomains.SlbSubDomain
+		}
+		for _, item := range items {
+			sub := SLBSubDomain{
+				SubDomain:   tea.StringValue(item.SubDomain),
+				Type:        tea.StringValue(item.Type),
+				RecordCount: tea.Int64Value(item.RecordCount),
+				Open:        tea.BoolValue(item.Open),
+			}
+			if item.LineAlgorithms != nil {
+				for _, line := range item.LineAlgorithms.LineAlgorithm {
+					sub.Lines = append(sub.Lines, SLBLine{
+						Line: tea.StringValue(line.Line),
+						Open: tea.BoolValue(line.Open),
+					})
+				}
+			}
+			subDomains = append(subDomains, sub)
+		}
+
+		if len(items) == 0 || int64(len(subDomains)) >= tea.Int64Value(resp.Body.TotalCount) {
+			break
+		}
+	}
+
+	s.log.Info("获取负载均衡子域名成功",
+		zap.String("domain", domainName),
+		zap.Int("count", len(subDomains)),
+	)
+	return subDomains, nil
+}
+
+// GetSLBMembers 获取子域名的负载均衡成员及其权重
+func (s *DNSService) GetSLBMembers(domainName, rr, recordType string) (*SLBMembers, error) {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return nil, err
+	}
+	if rr, err = normalizeRR(rr); err != nil {
+		return nil, err
+	}
+	recordType = strings.ToUpper(recordType)
+	if !slbTypes[recordType] {
+		return nil, apperror.BadRequest("负载均衡只支持A、AAAA和CNAME记录")
+	}
+
+	records, err := s.QueryDomainRecords(&RecordQuery{
+		DomainName: domainName,
+		RR:         rr,
+		Type:       recordType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	members := &SLBMembers{
+		SubDomain: slbSubDomain(rr, domainName),
+		Type:      recordType,
+		Records:   records,
+	}
+
+	subDomains, err := s.ListSLBSubDomains(domainName, rr)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subDomains {
+		if strings.EqualFold(sub.SubDomain, members.SubDomain) && sub.Type == recordType {
+			members.Open = sub.Open
+		}
+	}
+	return members, nil
+}
+
+// SetSLBStatus 开启或关闭子域名的权重负载均衡，line 为空时设置默认线路
+func (s *DNSService) SetSLBStatus(domainName, rr, recordType, line string, open bool) error {
+	domainName, err := normalizeDomainName(domainName)
+	if err != nil {
+		return err
+	}
+	if rr, err = normalizeRR(rr); err != nil {
+		return err
+	}
+	if rr == "" {
+		return apperror.BadRequest("主机记录不能为空")
+	}
+	recordType = strings.ToUpper(recordType)
+	if recordType != "" && !slbTypes[recordType] {
+		return apperror.BadRequest("负载均衡只支持A、AAAA和CNAME记录")
+	}
+	if err := s.checkWrite(&WriteOp{Action: WriteSLB, Domain: domainName, RR: rr, Type: recordType}); err != nil {
+		return err
+	}
+
+	subDomain := slbSubDomain(rr, domainName)
+	s.log.Info("正在设置负载均衡状态",
+		zap.String("sub_domain", subDomain),
+		zap.String("type", recordType),
+		zap.Bool("open", open),
+	)
+
+	req := &dns.SetDNSSLBStatusRequest{
+		DomainName: tea.String(domainName),
+		SubDomain:  tea.String(subDomain),
+		Open:       tea.Bool(open),
+	}
+	if recordType != "" {
+		req.Type = tea.String(recordType)
+	}
+	if line != "" {
+		req.Line = tea.String(line)
+	}
+
+	s.throttle()
+	if _, err := s.client.SetDNSSLBStatus(req); err != nil {
+		s.log.Error("设置负载均衡状态失败",
+			zap.String("sub_domain", subDomain),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.log.Info("设置负载均衡状态成功",
+		zap.String("sub_domain", subDomain),
+		zap.Bool("open", open),
+	)
+	return nil
+}
+
+// UpdateSLBWeight 修改负载均衡成员的权重
+func (s *DNSService) UpdateSLBWeight(recordId string, weight int32) error {
+	if recordId == "" {
+		return apperror.BadRequest("记录ID不能为空")
+	}
+	if err := ValidateSLBWeight(weight); err != nil {
+		return err
+	}
+	if err := s.checkRecordWrite(WriteSLB, recordId, nil); err != nil {
+		return err
+	}
+
+	req := &dns.UpdateDNSSLBWeightRequest{
+		RecordId: tea.String(recordId),
+		Weight:   tea.Int32(weight),
+	}
+
+	s.throttle()
+	if _, err := s.client.UpdateDNSSLBWeight(req); err != nil {
+		s.log.Error("修改负载均衡权重失败",
+			zap.String("record_id", recordId),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.log.Info("修改负载均衡权重成功",
+		zap.String("record_id", recordId),
+		zap.Int32("weight", weight),
+	)
+	return nil
+}
+
+// slbSubDomain 负载均衡接口使用的子域名，主域名为 @.example.com 的形式
+func slbSubDomain(rr, domainName string) string {
+	if rr == "" {
+		rr = "@"
+	}
+	return rr + "." + domainName
+}
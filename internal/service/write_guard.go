@@ -0,0 +1,110 @@
+package service
+
+// 写操作类型，用于策略检查
+const (
+	WriteCreate     = "create"
+	WriteUpdate     = "update"
+	WriteDelete     = "delete"
+	WriteEnable     = "enable"
+	WriteDisable    = "disable"
+	WriteRemark     = "remark"
+	WriteSLB        = "slb"         // 开关负载均衡或修改权重
+	WriteCustomLine = "custom_line" // 添加、修改或删除自定义线路
+	WriteDomain     = "domain"      // 添加域名
+	WriteGroup      = "group"       // 添加、修改或删除域名组
+)
+
+// Actor 发起写操作的调用方
+type Actor struct {
+	Name       string `json:"name,omitempty"` // 调用方身份，内部组件（故障转移、控制器等）为空
+	RequestId  string `json:"request_id,omitempty"`
+	BreakGlass string `json:"break_glass,omitempty"` // 紧急变更的原因，非空时可以越过策略限制，并写入审计日志
+}
+
+// WriteOp 待执行的写操作
+type WriteOp struct {
+	Action   string
+	Domain   string
+	RecordId string // 记录ID，域名组操作为域名组ID（添加时为名称）
+	RR       string
+	Type     string
+	TTL      int64         // 写入后的TTL，0 表示使用默认值或不修改
+	Before   *DomainRecord // 修改前的记录，新增记录和自定义线路操作为空
+	Actor    Actor
+}
+
+// WriteGuard 在写操作执行前检查是否允许，返回错误时不执行
+type WriteGuard interface {
+	CheckWrite(op *WriteOp) error
+}
+
+// SetWriteGuard 设置写操作的策略检查，需要在处理请求前调用
+func (s *DNSService) SetWriteGuard(guard WriteGuard) {
+	s.guard = guard
+}
+
+// WithActor 返回以指定调用方执行写操作的服务，与原服务共享客户端、限流和缓存
+func (s *DNSService) WithActor(actor Actor) *DNSService {
+	c := *s
+	c.actor = actor
+	return &c
+}
+
+// CheckWrite 以当前调用方对稍后执行的写操作做策略检查，不执行写操作。
+// 定时变更、迁移等任务在创建时调用，不允许时拒绝创建；recordId 为空时按新增记录检查
+func (s *DNSService) CheckWrite(action, domainName, recordId string, input *DomainRecordInput) error {
+	if recordId != "" {
+		return s.checkRecordWrite(action, recordId, input)
+	}
+	op := &WriteOp{Action: action, Domain: domainName}
+	if input != nil {
+		op.RR = input.RR
+		op.Type = input.Type
+		op.TTL = input.TTL
+	}
+	return s.checkWrite(op)
+}
+
+// checkWrite 执行写操作前的策略检查
+func (s *DNSService) checkWrite(op *WriteOp) error {
+	if s.guard == nil {
+		return nil
+	}
+	op.Actor = s.actor
+	return s.guard.CheckWrite(op)
+}
+
+// checkRecordWrite 查询修改前的记录后执行策略检查，input 为 create/update 提交的记录
+func (s *DNSService) checkRecordWrite(action, recordId string, input *DomainRecordInput) error {
+	if s.guard == nil {
+		return nil
+	}
+
+	before, err := s.GetDomainRecordById(recordId)
+	if err != nil {
+		return err
+	}
+	return s.checkWriteOn(action, before, input)
+}
+
+// checkWriteOn 使用已查询的修改前记录执行策略检查
+func (s *DNSService) checkWriteOn(action string, before *DomainRecord, input *DomainRecordInput) error {
+	if s.guard == nil {
+		return nil
+	}
+
+	op := &WriteOp{
+		Action:   action,
+		Domain:   before.DomainName,
+		RecordId: before.RecordId,
+		RR:       before.RR,
+		Type:     before.Type,
+		Before:   before,
+	}
+	if input != nil {
+		op.RR = input.RR
+		op.Type = input.Type
+		op.TTL = input.TTL
+	}
+	return s.checkWrite(op)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+)
+
+// zonesCacheTTL 域名列表的缓存时长
+const zonesCacheTTL = 5 * time.Minute
+
+// zoneCache 账户下的域名列表（ASCII 形式）缓存
+type zoneCache struct {
+	mu        sync.Mutex
+	names     []string
+	fetchedAt time.Time
+}
+
+// ListZones 获取账户下的域名列表（ASCII 小写形式），结果缓存一段时间，refresh 为 true 时重新获取
+func (s *DNSService) ListZones(refresh bool) ([]string, error) {
+	s.zones.mu.Lock()
+	defer s.zones.mu.Unlock()
+
+	if !refresh && s.zones.names != nil && time.Since(s.zones.fetchedAt) < zonesCacheTTL {
+		return s.zones.names, nil
+	}
+
+	domains, err := s.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(domains))
+	for _, d := range domains {
+		name := d.PunyCode
+		if name == "" {
+			name = d.DomainName
+		}
+		names = append(names, strings.ToLower(name))
+	}
+	s.zones.names = names
+	s.zones.fetchedAt = time.Now()
+	return names, nil
+}
+
+// ResolveZone 找到托管完整名称的域名（取最长匹配，子域名单独托管时优先使用子域名），返回域名和主机记录；
+// 缓存中找不到时重新获取一次域名列表
+func (s *DNSService) ResolveZone(fqdn string) (string, string, error) {
+	name, err := normalizeDomainName(fqdn)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, refresh := range []bool{false, true} {
+		zones, err := s.ListZones(refresh)
+		if err != nil {
+			return "", "", err
+		}
+
+		best := ""
+		for _, zone := range zones {
+			if (name == zone || strings.HasSuffix(name, "."+zone)) && len(zone) > len(best) {
+				best = zone
+			}
+		}
+		if best == name {
+			return best, "@", nil
+		}
+		if best != "" {
+			return best, strings.TrimSuffix(name, "."+best), nil
+		}
+	}
+	return "", "", apperror.NotFound(fmt.Sprintf("账户下没有托管 %s 的域名", fqdn))
+}
+
+// invalidateZones 域名变化后清除域名列表缓存
+func (s *DNSService) invalidateZones() {
+	s.zones.mu.Lock()
+	s.zones.names = nil
+	s.zones.mu.Unlock()
+}
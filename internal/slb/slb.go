@@ -0,0 +1,507 @@
+package slb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"dns-update/internal/apperror"
+	"dns-update/internal/service"
+	"dns-update/internal/validation"
+	"dns-update/pkg/logger"
+	"dns-update/pkg/redact"
+
+	"go.uber.org/zap"
+)
+
+// 流量切换任务的状态
+const (
+	ShiftStatusRunning  = "running"
+	ShiftStatusFinished = "finished"
+	ShiftStatusFailed   = "failed"
+	ShiftStatusAborted  = "aborted"
+)
+
+// 流量切换的默认值和限制
+const (
+	DefaultShiftSteps = 5
+	MaxShiftSteps     = 100
+	MinShiftInterval  = 10 * time.Second
+	// ShiftRetention 已结束任务的保留时长
+	ShiftRetention = 24 * time.Hour
+)
+
+// defaultLine 默认解析线路，只调整默认线路的记录
+const defaultLine = "default"
+
+// RecordService 负载均衡管理依赖的解析记录服务
+type RecordService interface {
+	GetSLBMembers(domainName, rr, recordType string) (*service.SLBMembers, error)
+	SetSLBStatus(domainName, rr, recordType, line string, open bool) error
+	UpdateSLBWeight(recordId string, weight int32) error
+	SetDomainRecordStatus(recordId, status string) error
+	CheckWrite(action, domainName, recordId string, input *service.DomainRecordInput) error
+}
+
+// ShiftRequest 流量切换请求：在 Steps 步内把各成员的权重逐步调整为目标权重
+type ShiftRequest struct {
+	Weights  map[string]int32 `json:"weights"`  // 按记录值指定目标权重，0 表示最终暂停该记录，未指定的成员保持不变
+	Steps    int              `json:"steps"`    // 分几步完成，默认5
+	Interval string           `json:"interval"` // 每步的间隔，如 5m
+}
+
+// ShiftTarget 流量切换中的一个成员
+type ShiftTarget struct {
+	Value    string `json:"value"`
+	RecordId string `json:"record_id"`
+	From     int32  `json:"from"`
+	To       int32  `json:"to"`
+	Current  int32  `json:"current"`
+
+	disabled bool // 任务开始时记录已暂停，第一步先启用
+}
+
+// Shift 流量切换任务
+type Shift struct {
+	Id         string        `json:"id"`
+	Domain     string        `json:"domain"`
+	RR         string        `json:"rr"`
+	Type       string        `json:"type"`
+	Status     string        `json:"status"`
+	Step       int           `json:"step"`
+	Steps      int           `json:"steps"`
+	Interval   string        `json:"interval"`
+	Targets    []ShiftTarget `json:"targets"`
+	Error      string        `json:"error,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	NextStepAt *time.Time    `json:"next_step_at,omitempty"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+
+	interval time.Duration
+	cancel   context.CancelFunc
+	records  RecordService // 创建任务的调用方对应的服务
+}
+
+// Validate 校验流量切换请求
+func (r *ShiftRequest) Validate() (time.Duration, error) {
+	var errs validation.Errors
+
+	if len(r.Weights) == 0 {
+		errs.Add("weights", errors.New("至少需要指定一个成员的目标权重"))
+	}
+	for value, weight := range r.Weights {
+		if weight != 0 {
+			if err := service.ValidateSLBWeight(weight); err != nil {
+				errs.Add("weights."+value, err)
+			}
+		}
+	}
+	if r.Steps < 0 || r.Steps > MaxShiftSteps {
+		errs.Add("steps", fmt.Errorf("步数必须在1到%d之间", MaxShiftSteps))
+	}
+
+	interval, err := time.ParseDuration(r.Interval)
+	if err != nil {
+		errs.Add("interval", errors.New("间隔格式错误，应为 30s、5m 等形式"))
+	} else if interval < MinShiftInterval {
+		errs.Add("interval", fmt.Errorf("间隔不能小于%s", MinShiftInterval))
+	}
+
+	if err := errs.Err(); err != nil {
+		return 0, err
+	}
+	return interval, nil
+}
+
+// Manager 管理负载均衡权重和流量切换任务，任务只保存在内存中
+type Manager struct {
+	records RecordService
+	log     *zap.Logger
+
+	mu     sync.Mutex
+	shifts map[string]*Shift
+}
+
+// NewManager 创建负载均衡管理器
+func NewManager(records RecordService) *Manager {
+	return &Manager{
+		records: records,
+		log:     logger.GetLogger(),
+		shifts:  make(map[string]*Shift),
+	}
+}
+
+// SetWeights 按记录值设置成员的权重，子域名未开启负载均衡时先开启。
+// records 为以调用方身份执行写操作的服务，为 nil 时使用管理器的服务
+func (m *Manager) SetWeights(records RecordService, domain, rr, recordType string, weights map[string]int32) error {
+	if records == nil {
+		records = m.records
+	}
+	if len(weights) == 0 {
+		return apperror.BadRequest("至少需要指定一个成员的权重")
+	}
+	for _, weight := range weights {
+		if err := service.ValidateSLBWeight(weight); err != nil {
+			return err
+		}
+	}
+
+	members, err := m.members(domain, rr, recordType)
+	if err != nil {
+		return err
+	}
+	recordIds, err := resolveValues(members, weights)
+	if err != nil {
+		return err
+	}
+	if err := ensureOpen(records, domain, rr, members); err != nil {
+		return err
+	}
+
+	for value, weight := range weights {
+		if err := records.UpdateSLBWeight(recordIds[value], weight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartShift 创建流量切换任务，同一子域名同时只能有一个进行中的任务。
+// 任务的每一步都通过 records 执行，为 nil 时使用管理器的服务
+func (m *Manager) StartShift(records RecordService, domain, rr, recordType string, req *ShiftRequest) (*Shift, error) {
+	if records == nil {
+		records = m.records
+	}
+	interval, err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	steps := req.Steps
+	if steps == 0 {
+		steps = DefaultShiftSteps
+	}
+
+	members, err := m.members(domain, rr, recordType)
+	if err != nil {
+		return nil, err
+	}
+	recordIds, err := resolveValues(members, req.Weights)
+	if err != nil {
+		return nil, err
+	}
+
+	shift := &Shift{
+		Id:        newShiftId(),
+		Domain:    domain,
+		RR:        rr,
+		Type:      members.Type,
+		Status:    ShiftStatusRunning,
+		Steps:     steps,
+		Interval:  interval.String(),
+		CreatedAt: time.Now(),
+		interval:  interval,
+		records:   records,
+	}
+	for _, r := range members.Records {
+		to, ok := req.Weights[r.Value]
+		if !ok {
+			continue
+		}
+		disabled := !strings.EqualFold(r.Status, service.RecordStatusEnable)
+		if disabled && to == 0 {
+			continue
+		}
+		target := ShiftTarget{
+			Value:    r.Value,
+			RecordId: recordIds[r.Value],
+			From:     r.Weight,
+			To:       to,
+			Current:  r.Weight,
+			disabled: disabled,
+		}
+		if !members.Open || disabled || target.From == 0 {
+			// 未开启负载均衡或已暂停的成员从最小权重开始
+			target.From = service.MinSLBWeight
+		}
+		if disabled {
+			target.Current = 0
+		}
+		shift.Targets = append(shift.Targets, target)
+	}
+	if err := checkShift(records, domain, shift.Targets); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	shift.cancel = cancel
+
+	m.mu.Lock()
+	m.purgeLocked()
+	for _, s := range m.shifts {
+		if s.Status == ShiftStatusRunning && strings.EqualFold(s.Domain, domain) && strings.EqualFold(s.RR, rr) && s.Type == shift.Type {
+			m.mu.Unlock()
+			cancel()
+			return nil, apperror.Conflict(fmt.Sprintf("该子域名已有进行中的流量切换任务: %s", s.Id))
+		}
+	}
+	m.shifts[shift.Id] = shift
+	m.mu.Unlock()
+
+	if err := ensureOpen(records, domain, rr, members); err != nil {
+		m.finish(shift, err)
+		return m.Get(shift.Id)
+	}
+
+	m.log.Info("流量切换任务开始",
+		zap.String("shift_id", shift.Id),
+		zap.String("domain", domain),
+		zap.String("rr", rr),
+		zap.Int("steps", steps),
+		zap.Duration("interval", interval),
+	)
+	go m.run(ctx, shift)
+	return m.Get(shift.Id)
+}
+
+// checkShift 在创建任务时以调用方的身份检查每个成员会执行的写操作，避免任务执行到一半被策略拒绝
+func checkShift(records RecordService, domain string, targets []ShiftTarget) error {
+	for _, t := range targets {
+		actions := []string{service.WriteSLB}
+		if t.disabled {
+			actions = append(actions, service.WriteEnable)
+		}
+		if t.To == 0 {
+			actions = append(actions, service.WriteDisable)
+		}
+		for _, action := range actions {
+			if err := records.CheckWrite(action, domain, t.RecordId, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Get 查询流量切换任务
+func (m *Manager) Get(id string) (*Shift, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shift, ok := m.shifts[id]
+	if !ok {
+		return nil, apperror.NotFound("流量切换任务不存在")
+	}
+	return copyShift(shift), nil
+}
+
+// List 返回所有流量切换任务，最近创建的在前
+func (m *Manager) List() []*Shift {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeLocked()
+	shifts := make([]*Shift, 0, len(m.shifts))
+	for _, s := range m.shifts {
+		shifts = append(shifts, copyShift(s))
+	}
+	slices.SortFunc(shifts, func(a, b *Shift) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return shifts
+}
+
+// Abort 中止进行中的流量切换任务，已调整的权重保持不变
+func (m *Manager) Abort(id string) (*Shift, error) {
+	m.mu.Lock()
+	shift, ok := m.shifts[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, apperror.NotFound("流量切换任务不存在")
+	}
+	if shift.Status != ShiftStatusRunning {
+		m.mu.Unlock()
+		return nil, apperror.Conflict("流量切换任务已结束")
+	}
+	now := time.Now()
+	shift.Status = ShiftStatusAborted
+	shift.FinishedAt = &now
+	shift.NextStepAt = nil
+	shift.cancel()
+	m.mu.Unlock()
+
+	m.log.Info("流量切换任务已中止", zap.String("shift_id", id))
+	return m.Get(id)
+}
+
+// run 逐步调整权重，每步之间等待 interval
+func (m *Manager) run(ctx context.Context, shift *Shift) {
+	for step := 1; step <= shift.Steps; step++ {
+		if err := m.applyStep(shift, step); err != nil {
+			m.finish(shift, err)
+			return
+		}
+		if step == shift.Steps {
+			break
+		}
+
+		next := time.Now().Add(shift.interval)
+		m.mu.Lock()
+		shift.NextStepAt = &next
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(shift.interval):
+		}
+	}
+	m.finish(shift, nil)
+}
+
+// applyStep 执行一步权重调整；目标权重为0的成员在最后一步被暂停
+func (m *Manager) applyStep(shift *Shift, step int) error {
+	m.mu.Lock()
+	if shift.Status != ShiftStatusRunning {
+		m.mu.Unlock()
+		return nil
+	}
+	targets := append([]ShiftTarget(nil), shift.Targets...)
+	m.mu.Unlock()
+
+	for i, t := range targets {
+		weight := interpolate(t.From, t.To, step, shift.Steps)
+		if step == shift.Steps && t.To == 0 {
+			if err := shift.records.SetDomainRecordStatus(t.RecordId, service.RecordStatusDisable); err != nil {
+				return err
+			}
+			targets[i].Current = 0
+			continue
+		}
+		if step == 1 && t.disabled {
+			// 已暂停的成员先启用，再参与权重调整
+			if err := shift.records.SetDomainRecordStatus(t.RecordId, service.RecordStatusEnable); err != nil {
+				return err
+			}
+		}
+		if weight != t.Current {
+			if err := shift.records.UpdateSLBWeight(t.RecordId, weight); err != nil {
+				return err
+			}
+		}
+		targets[i].Current = weight
+	}
+
+	m.mu.Lock()
+	shift.Step = step
+	shift.Targets = targets
+	m.mu.Unlock()
+
+	m.log.Info("流量切换完成一步",
+		zap.String("shift_id", shift.Id),
+		zap.Int("step", step),
+		zap.Int("steps", shift.Steps),
+	)
+	return nil
+}
+
+// finish 结束任务，err 不为空时标记为失败
+func (m *Manager) finish(shift *Shift, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if shift.Status != ShiftStatusRunning {
+		return
+	}
+	now := time.Now()
+	shift.FinishedAt = &now
+	shift.NextStepAt = nil
+	shift.cancel()
+	if err != nil {
+		shift.Status = ShiftStatusFailed
+		shift.Error = redact.String(err.Error())
+		m.log.Error("流量切换任务失败", zap.String("shift_id", shift.Id), zap.Error(err))
+		return
+	}
+	shift.Status = ShiftStatusFinished
+	m.log.Info("流量切换任务完成", zap.String("shift_id", shift.Id))
+}
+
+// members 获取子域名默认线路的负载均衡成员
+func (m *Manager) members(domain, rr, recordType string) (*service.SLBMembers, error) {
+	members, err := m.records.GetSLBMembers(domain, rr, recordType)
+	if err != nil {
+		return nil, err
+	}
+	records := members.Records[:0]
+	for _, r := range members.Records {
+		if r.Line == "" || r.Line == defaultLine {
+			records = append(records, r)
+		}
+	}
+	members.Records = records
+	if len(members.Records) == 0 {
+		return nil, apperror.NotFound(fmt.Sprintf("%s 没有%s记录", members.SubDomain, members.Type))
+	}
+	return members, nil
+}
+
+// ensureOpen 子域名未开启负载均衡时开启
+func ensureOpen(records RecordService, domain, rr string, members *service.SLBMembers) error {
+	if members.Open {
+		return nil
+	}
+	if err := records.SetSLBStatus(domain, rr, members.Type, "", true); err != nil {
+		return err
+	}
+	members.Open = true
+	return nil
+}
+
+// resolveValues 将记录值映射为记录ID
+func resolveValues(members *service.SLBMembers, weights map[string]int32) (map[string]string, error) {
+	recordIds := make(map[string]string)
+	for _, r := range members.Records {
+		recordIds[r.Value] = r.RecordId
+	}
+	for value := range weights {
+		if _, ok := recordIds[value]; !ok {
+			return nil, apperror.BadRequest(fmt.Sprintf("%s 没有值为 %s 的记录", members.SubDomain, value))
+		}
+	}
+	return recordIds, nil
+}
+
+// interpolate 计算第 step 步的权重，中间步骤的权重不小于最小权重
+func interpolate(from, to int32, step, steps int) int32 {
+	weight := int32(math.Round(float64(from) + float64(to-from)*float64(step)/float64(steps)))
+	return max(weight, service.MinSLBWeight)
+}
+
+// purgeLocked 清理超过保留时长的已结束任务，调用方需持有锁
+func (m *Manager) purgeLocked() {
+	deadline := time.Now().Add(-ShiftRetention)
+	for id, s := range m.shifts {
+		if s.FinishedAt != nil && s.FinishedAt.Before(deadline) {
+			delete(m.shifts, id)
+		}
+	}
+}
+
+// copyShift 复制任务，调用方需持有锁
+func copyShift(s *Shift) *Shift {
+	c := *s
+	c.Targets = append([]ShiftTarget(nil), s.Targets...)
+	return &c
+}
+
+// newShiftId 生成随机任务ID
+func newShiftId() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultDir 默认的数据目录
+const DefaultDir = "data"
+
+// File 以 JSON 形式保存在单个文件中的状态，写入时先写临时文件再重命名，避免中途崩溃留下损坏的文件
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFile 在数据目录下创建状态文件，目录不存在时自动创建
+func NewFile(dir, name string) (*File, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("创建数据目录失败: %w", err)
+	}
+	return &File{path: filepath.Join(dir, name)}, nil
+}
+
+// Path 返回状态文件的路径
+func (f *File) Path() string {
+	return f.path
+}
+
+// Load 读取状态到 v，文件不存在时不修改 v 并返回 nil
+func (f *File) Load(v any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取状态文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解析状态文件 %s 失败: %w", f.path, err)
+	}
+	return nil
+}
+
+// Save 将 v 写入状态文件
+func (f *File) Save(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码状态失败: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"dns-update/internal/apperror"
+)
+
+// 支持的解析记录类型
+const (
+	TypeA           = "A"
+	TypeAAAA        = "AAAA"
+	TypeCNAME       = "CNAME"
+	TypeNS          = "NS"
+	TypeMX          = "MX"
+	TypeTXT         = "TXT"
+	TypeSRV         = "SRV"
+	TypeCAA         = "CAA"
+	TypeRedirectURL = "REDIRECT_URL"
+	TypeForwardURL  = "FORWARD_URL"
+)
+
+// queryTypePattern 记录类型的格式，大写字母、数字和下划线
+var queryTypePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// 数值边界
+const (
+	MinTTL         = 1
+	MaxTTL         = 86400
+	MinMXPriority  = 1
+	MaxMXPriority  = 50
+	MaxTXTLength   = 512
+	MaxTXTSegment  = 255
+	MaxRRLength    = 253
+	MaxLabelLength = 63
+)
+
+// SupportedTypes 支持的记录类型集合
+var SupportedTypes = map[string]bool{
+	TypeA:           true,
+	TypeAAAA:        true,
+	TypeCNAME:       true,
+	TypeNS:          true,
+	TypeMX:          true,
+	TypeTXT:         true,
+	TypeSRV:         true,
+	TypeCAA:         true,
+	TypeRedirectURL: true,
+	TypeForwardURL:  true,
+}
+
+// Record 待校验的解析记录
+type Record struct {
+	RR       string
+	Type     string
+	Value    string
+	TTL      int64 // 0 表示使用默认值
+	Priority int64 // 仅 MX 记录使用，0 表示使用默认值
+}
+
+// Errors 字段级校验错误集合
+type Errors []apperror.FieldError
+
+// Add 添加字段错误
+func (e *Errors) Add(field string, err error) {
+	*e = append(*e, apperror.FieldError{Field: field, Message: err.Error()})
+}
+
+// Err 没有错误时返回 nil，否则返回参数错误
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return apperror.Invalid(e...)
+}
+
+// ValidateRecord 校验解析记录的所有字段
+func ValidateRecord(r Record) error {
+	var errs Errors
+
+	if err := ValidateRR(r.RR); err != nil {
+		errs.Add("rr", err)
+	}
+
+	if err := ValidateTTL(r.TTL); err != nil {
+		errs.Add("ttl", err)
+	}
+
+	if err := ValidateType(r.Type); err != nil {
+		errs.Add("type", err)
+		return errs.Err()
+	}
+
+	if err := ValidateValue(r.Type, r.Value); err != nil {
+		errs.Add("value", err)
+	}
+
+	if r.Type == TypeMX {
+		if err := ValidateMXPriority(r.Priority); err != nil {
+			errs.Add("priority", err)
+		}
+	} else if r.Priority != 0 {
+		errs.Add("priority", errors.New("仅MX记录支持设置优先级"))
+	}
+
+	return errs.Err()
+}
+
+// ValidateType 校验记录类型
+func ValidateType(recordType string) error {
+	if recordType == "" {
+		return errors.New("记录类型不能为空")
+	}
+	if !SupportedTypes[recordType] {
+		return fmt.Errorf("不支持的记录类型: %s", recordType)
+	}
+	return nil
+}
+
+// ValidateQueryType 校验查询条件中的记录类型，只校验格式。
+// 账户下可能存在写接口不支持的类型，查询时交给上游判断
+func ValidateQueryType(recordType string) error {
+	if recordType == "" {
+		return errors.New("记录类型不能为空")
+	}
+	if !queryTypePattern.MatchString(recordType) {
+		return fmt.Errorf("记录类型格式错误: %s", recordType)
+	}
+	return nil
+}
+
+// ValidateValue 按记录类型校验记录值
+func ValidateValue(recordType, value string) error {
+	if value == "" {
+		return errors.New("记录值不能为空")
+	}
+
+	switch recordType {
+	case TypeA:
+		return validateIPv4(value)
+	case TypeAAAA:
+		return validateIPv6(value)
+	case TypeCNAME, TypeNS, TypeMX:
+		return validateHostname(value)
+	case TypeTXT:
+		return validateTXT(value)
+	case TypeSRV:
+		return validateSRV(value)
+	case TypeCAA:
+		return validateCAA(value)
+	case TypeRedirectURL, TypeForwardURL:
+		return validateURL(value)
+	}
+	return fmt.Errorf("不支持的记录类型: %s", recordType)
+}
+
+// ValidateTTL 校验TTL，0 表示使用默认值
+func ValidateTTL(ttl int64) error {
+	if ttl != 0 && (ttl < MinTTL || ttl > MaxTTL) {
+		return fmt.Errorf("TTL必须在%d-%d之间", MinTTL, MaxTTL)
+	}
+	return nil
+}
+
+// ValidateMXPriority 校验MX优先级，0 表示使用默认值
+func ValidateMXPriority(priority int64) error {
+	if priority != 0 && (priority < MinMXPriority || priority > MaxMXPriority) {
+		return fmt.Errorf("MX优先级必须在%d-%d之间", MinMXPriority, MaxMXPriority)
+	}
+	return nil
+}
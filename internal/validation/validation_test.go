@@ -0,0 +1,201 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"dns-update/internal/apperror"
+)
+
+func TestValidateValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		value      string
+		wantErr    bool
+	}{
+		{"A", TypeA, "192.0.2.1", false},
+		{"A 使用 IPv6 地址", TypeA, "2001:db8::1", true},
+		{"A 使用 IPv4 映射地址", TypeA, "::ffff:192.0.2.1", true},
+		{"AAAA", TypeAAAA, "2001:db8::1", false},
+		{"AAAA 使用 IPv4 地址", TypeAAAA, "192.0.2.1", true},
+		{"CNAME 末尾带点", TypeCNAME, "target.example.com.", false},
+		{"CNAME 使用 IP 地址", TypeCNAME, "192.0.2.1", true},
+		{"CNAME 标签以连字符开头", TypeCNAME, "-bad.example.com", true},
+		{"MX", TypeMX, "mx1.example.com", false},
+		{"空值", TypeA, "", true},
+
+		{"SRV", TypeSRV, "10 60 5060 sip.example.com", false},
+		{"SRV 目标为点表示服务不可用", TypeSRV, "0 0 1 .", false},
+		{"SRV 缺少目标主机", TypeSRV, "10 60 5060", true},
+		{"SRV 字段过多", TypeSRV, "10 60 5060 sip.example.com extra", true},
+		{"SRV 端口为 0", TypeSRV, "10 60 0 sip.example.com", true},
+		{"SRV 权重超出范围", TypeSRV, "10 65536 5060 sip.example.com", true},
+		{"SRV 优先级为负数", TypeSRV, "-1 60 5060 sip.example.com", true},
+
+		{"CAA issue", TypeCAA, `0 issue "letsencrypt.org"`, false},
+		{"CAA issue 带参数", TypeCAA, `0 issue "letsencrypt.org; validationmethods=dns-01"`, false},
+		{"CAA issue 为空表示禁止签发", TypeCAA, `0 issue ";"`, false},
+		{"CAA issuewild", TypeCAA, `128 issuewild "letsencrypt.org"`, false},
+		{"CAA iodef mailto", TypeCAA, `0 iodef "mailto:security@example.com"`, false},
+		{"CAA iodef https", TypeCAA, `0 iodef "https://example.com/caa"`, false},
+		{"CAA iodef 使用 ftp", TypeCAA, `0 iodef "ftp://example.com/caa"`, true},
+		{"CAA flags 超出范围", TypeCAA, `256 issue "letsencrypt.org"`, true},
+		{"CAA flags 不是数字", TypeCAA, `critical issue "letsencrypt.org"`, true},
+		{"CAA 不支持的标签", TypeCAA, `0 contactemail "security@example.com"`, true},
+		{"CAA 标签大小写敏感", TypeCAA, `0 ISSUE "letsencrypt.org"`, true},
+		{"CAA value 缺少引号", TypeCAA, `0 issue letsencrypt.org`, true},
+		{"CAA 缺少 value", TypeCAA, `0 issue`, true},
+
+		{"TXT 未加引号", TypeTXT, "v=spf1 include:spf.example.com ~all", false},
+		{"TXT 转义的引号", TypeTXT, `say \"hi\"`, false},
+		{"TXT 未转义的引号", TypeTXT, `say "hi"`, true},
+		{"TXT 以反斜杠结尾", TypeTXT, `abc\`, true},
+		{"TXT 分段", TypeTXT, `"part one" "part two"`, false},
+		{"TXT 分段内转义的引号", TypeTXT, `"say \"hi\""`, false},
+		{"TXT 引号未闭合", TypeTXT, `"part one`, true},
+		{"TXT 分段之间有未加引号的内容", TypeTXT, `"part one" two`, true},
+		{"TXT 控制字符", TypeTXT, "line\nbreak", true},
+		{"TXT 最大长度", TypeTXT, strings.Repeat("a", MaxTXTLength), false},
+		{"TXT 超过最大长度", TypeTXT, strings.Repeat("a", MaxTXTLength+1), true},
+		{"TXT 分段最大长度", TypeTXT, `"` + strings.Repeat("a", MaxTXTSegment) + `"`, false},
+		{"TXT 分段超过最大长度", TypeTXT, `"` + strings.Repeat("a", MaxTXTSegment+1) + `"`, true},
+
+		{"显性URL", TypeRedirectURL, "https://example.com/path", false},
+		{"显性URL 缺少协议", TypeRedirectURL, "example.com/path", true},
+		{"不支持的类型", "PTR", "host.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateValue(tt.recordType, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateValue(%s, %q) = %v, wantErr %v", tt.recordType, tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRR(t *testing.T) {
+	tests := []struct {
+		rr      string
+		wantErr bool
+	}{
+		{"@", false},
+		{"*", false},
+		{"www", false},
+		{"*.dev", false},
+		{"_dmarc", false},
+		{"_sip._tcp", false},
+		{"a.b.c", false},
+		{"", true},
+		{"dev.*", true},
+		{"a.*.b", true},
+		{"*dev", true},
+		{"@.www", true},
+		{"www..dev", true},
+		{"-www", true},
+		{"www-", true},
+		{"ww w", true},
+		{strings.Repeat("a", MaxLabelLength), false},
+		{strings.Repeat("a", MaxLabelLength+1), true},
+		{strings.Repeat("a.", MaxRRLength/2) + "a", false},
+		{strings.Repeat("a.", MaxRRLength/2+1) + "a", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateRR(tt.rr); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateRR(%q) = %v, wantErr %v", tt.rr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateTTL(t *testing.T) {
+	tests := []struct {
+		ttl     int64
+		wantErr bool
+	}{
+		{0, false},
+		{MinTTL, false},
+		{600, false},
+		{MaxTTL, false},
+		{-1, true},
+		{MaxTTL + 1, true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateTTL(tt.ttl); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateTTL(%d) = %v, wantErr %v", tt.ttl, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record Record
+		fields []string // 期望报错的字段
+	}{
+		{
+			name:   "有效的MX记录",
+			record: Record{RR: "@", Type: TypeMX, Value: "mx1.example.com", TTL: 600, Priority: 10},
+		},
+		{
+			name:   "MX优先级超出范围",
+			record: Record{RR: "@", Type: TypeMX, Value: "mx1.example.com", Priority: MaxMXPriority + 1},
+			fields: []string{"priority"},
+		},
+		{
+			name:   "非MX记录设置优先级",
+			record: Record{RR: "www", Type: TypeA, Value: "192.0.2.1", Priority: 10},
+			fields: []string{"priority"},
+		},
+		{
+			name:   "多个字段错误",
+			record: Record{RR: "a.*", Type: TypeA, Value: "2001:db8::1", TTL: MaxTTL + 1},
+			fields: []string{"rr", "ttl", "value"},
+		},
+		{
+			name:   "类型错误时不校验记录值",
+			record: Record{RR: "www", Type: "PTR", Value: ""},
+			fields: []string{"type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRecord(tt.record)
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateRecord() = %v", err)
+				}
+				return
+			}
+
+			appErr := apperror.From(err)
+			if appErr == nil || appErr.Code != apperror.CodeInvalidParameter {
+				t.Fatalf("ValidateRecord() = %v, 期望参数错误", err)
+			}
+			var fields []string
+			for _, f := range appErr.Fields {
+				fields = append(fields, f.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("错误字段 = %v, 期望 %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestValidateQueryType(t *testing.T) {
+	for _, recordType := range []string{"A", "PTR", "REDIRECT_URL", "TYPE65"} {
+		if err := ValidateQueryType(recordType); err != nil {
+			t.Errorf("ValidateQueryType(%q) = %v", recordType, err)
+		}
+	}
+	for _, recordType := range []string{"", "a", "1A", "A B", "A;"} {
+		if err := ValidateQueryType(recordType); err == nil {
+			t.Errorf("ValidateQueryType(%q) 应返回错误", recordType)
+		}
+	}
+}
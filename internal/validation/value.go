@@ -0,0 +1,274 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// caaTags CAA记录支持的标签
+var caaTags = map[string]bool{
+	"issue":     true,
+	"issuewild": true,
+	"iodef":     true,
+}
+
+// ValidateRR 校验主机记录，支持 @ 和通配符 *
+func ValidateRR(rr string) error {
+	if rr == "" {
+		return errors.New("主机记录不能为空")
+	}
+	if rr == "@" || rr == "*" {
+		return nil
+	}
+	if len(rr) > MaxRRLength {
+		return fmt.Errorf("主机记录长度不能超过%d个字符", MaxRRLength)
+	}
+
+	labels := strings.Split(rr, ".")
+	for i, label := range labels {
+		// 通配符只能作为最左侧的完整标签出现
+		if label == "*" {
+			if i != 0 {
+				return errors.New("通配符*只能出现在主机记录的最左侧")
+			}
+			continue
+		}
+		if err := validateLabel(label, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLabel 校验单个域名标签，allowUnderscore 允许下划线（如 _dmarc）
+func validateLabel(label string, allowUnderscore bool) error {
+	if label == "" {
+		return errors.New("域名标签不能为空")
+	}
+	if len(label) > MaxLabelLength {
+		return fmt.Errorf("域名标签%q长度不能超过%d个字符", label, MaxLabelLength)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("域名标签%q不能以连字符开头或结尾", label)
+	}
+	for _, ch := range label {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '-':
+		case ch == '_' && allowUnderscore:
+		default:
+			return fmt.Errorf("域名标签%q包含非法字符%q", label, ch)
+		}
+	}
+	return nil
+}
+
+// validateIPv4 校验IPv4地址
+func validateIPv4(value string) error {
+	ip := net.ParseIP(value)
+	if ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+		return fmt.Errorf("%q不是有效的IPv4地址", value)
+	}
+	return nil
+}
+
+// validateIPv6 校验IPv6地址
+func validateIPv6(value string) error {
+	ip := net.ParseIP(value)
+	if ip == nil || !strings.Contains(value, ":") {
+		return fmt.Errorf("%q不是有效的IPv6地址", value)
+	}
+	return nil
+}
+
+// validateHostname 校验主机名，允许末尾的点
+func validateHostname(value string) error {
+	name := strings.TrimSuffix(value, ".")
+	if name == "" {
+		return errors.New("主机名不能为空")
+	}
+	if len(name) > MaxRRLength {
+		return fmt.Errorf("主机名长度不能超过%d个字符", MaxRRLength)
+	}
+	if net.ParseIP(name) != nil {
+		return fmt.Errorf("%q是IP地址，需要填写主机名", value)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if err := validateLabel(label, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTXT 校验TXT记录，支持 "..." "..." 形式的分段写法
+func validateTXT(value string) error {
+	if len(value) > MaxTXTLength {
+		return fmt.Errorf("TXT记录长度不能超过%d个字符", MaxTXTLength)
+	}
+	for _, ch := range value {
+		if ch < 0x20 || ch == 0x7f {
+			return errors.New("TXT记录不能包含控制字符")
+		}
+	}
+
+	if !strings.HasPrefix(value, `"`) {
+		// 未加引号的写法中，引号和反斜杠必须转义
+		if err := checkEscapes(value, false); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	segments, err := splitQuoted(value)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		if len(seg) > MaxTXTSegment {
+			return fmt.Errorf("TXT记录的每个分段不能超过%d个字符", MaxTXTSegment)
+		}
+	}
+	return nil
+}
+
+// checkEscapes 检查转义字符是否合法，inQuotes 表示是否位于引号内
+func checkEscapes(value string, inQuotes bool) error {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 >= len(value) {
+				return errors.New("TXT记录不能以未转义的反斜杠结尾")
+			}
+			i++
+		case '"':
+			if !inQuotes {
+				return errors.New("TXT记录中的双引号必须转义")
+			}
+		}
+	}
+	return nil
+}
+
+// splitQuoted 将 "a" "b" 形式的字符串拆分为分段内容
+func splitQuoted(value string) ([]string, error) {
+	var segments []string
+	i := 0
+	for i < len(value) {
+		if value[i] == ' ' {
+			i++
+			continue
+		}
+		if value[i] != '"' {
+			return nil, errors.New("TXT记录分段必须使用双引号包围")
+		}
+
+		var seg strings.Builder
+		i++
+		closed := false
+		for i < len(value) {
+			ch := value[i]
+			if ch == '\\' {
+				if i+1 >= len(value) {
+					return nil, errors.New("TXT记录不能以未转义的反斜杠结尾")
+				}
+				seg.WriteByte(value[i+1])
+				i += 2
+				continue
+			}
+			if ch == '"' {
+				closed = true
+				i++
+				break
+			}
+			seg.WriteByte(ch)
+			i++
+		}
+		if !closed {
+			return nil, errors.New("TXT记录的双引号未闭合")
+		}
+		segments = append(segments, seg.String())
+	}
+	return segments, nil
+}
+
+// validateSRV 校验SRV记录，格式为 "priority weight port target"
+func validateSRV(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) != 4 {
+		return errors.New("SRV记录格式必须为: 优先级 权重 端口 目标主机")
+	}
+
+	names := []string{"优先级", "权重", "端口"}
+	for i, name := range names {
+		n, err := strconv.ParseUint(fields[i], 10, 16)
+		if err != nil {
+			return fmt.Errorf("SRV记录的%s必须是0-65535之间的整数", name)
+		}
+		if i == 2 && n == 0 {
+			return errors.New("SRV记录的端口不能为0")
+		}
+	}
+
+	// 目标为 . 表示该服务不可用
+	if fields[3] == "." {
+		return nil
+	}
+	return validateHostname(fields[3])
+}
+
+// validateCAA 校验CAA记录，格式为 "flags tag value"
+func validateCAA(value string) error {
+	fields := strings.SplitN(value, " ", 3)
+	if len(fields) != 3 {
+		return errors.New("CAA记录格式必须为: flags tag value")
+	}
+
+	if _, err := strconv.ParseUint(fields[0], 10, 8); err != nil {
+		return errors.New("CAA记录的flags必须是0-255之间的整数")
+	}
+
+	tag := fields[1]
+	if !caaTags[tag] {
+		return fmt.Errorf("不支持的CAA标签: %s", tag)
+	}
+
+	caaValue := fields[2]
+	if len(caaValue) < 2 || !strings.HasPrefix(caaValue, `"`) || !strings.HasSuffix(caaValue, `"`) {
+		return errors.New("CAA记录的value必须使用双引号包围")
+	}
+	caaValue = caaValue[1 : len(caaValue)-1]
+
+	if tag == "iodef" {
+		u, err := url.Parse(caaValue)
+		if err != nil || (u.Scheme != "mailto" && u.Scheme != "http" && u.Scheme != "https") {
+			return errors.New("iodef的value必须是mailto:、http://或https://地址")
+		}
+		return nil
+	}
+
+	// issue/issuewild 的值为CA域名，可以为空（表示禁止签发），分号后为参数
+	issuer := strings.TrimSpace(strings.SplitN(caaValue, ";", 2)[0])
+	if issuer == "" {
+		return nil
+	}
+	return validateHostname(issuer)
+}
+
+// validateURL 校验显性/隐性URL转发的目标地址
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%q不是有效的URL", value)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("URL必须以http://或https://开头")
+	}
+	if u.Host == "" {
+		return errors.New("URL缺少主机名")
+	}
+	return nil
+}
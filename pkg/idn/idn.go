@@ -0,0 +1,84 @@
+package idn
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// acePrefix Punycode 标签前缀
+const acePrefix = "xn--"
+
+// ToASCII 按 IDNA2008 将域名或主机记录转换为小写的 ASCII 形式
+//
+// 纯 ASCII 标签只做小写处理，从而保留 @、* 以及 _dmarc 这类下划线标签
+func ToASCII(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "@" {
+		return name, nil
+	}
+
+	trailingDot := strings.HasSuffix(name, ".")
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			labels[i] = strings.ToLower(label)
+			continue
+		}
+		ascii, err := idna.Lookup.ToASCII(label)
+		if err != nil {
+			return "", fmt.Errorf("%q不是有效的国际化域名: %w", name, err)
+		}
+		labels[i] = ascii
+	}
+
+	result := strings.Join(labels, ".")
+	if trailingDot {
+		result += "."
+	}
+	return result, nil
+}
+
+// ToUnicode 将域名或主机记录转换为 Unicode 形式，无法转换的标签保持原样
+func ToUnicode(name string) string {
+	if !strings.Contains(strings.ToLower(name), acePrefix) {
+		return name
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(strings.ToLower(label), acePrefix) {
+			continue
+		}
+		if unicode, err := idna.Lookup.ToUnicode(label); err == nil {
+			labels[i] = unicode
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// IsIDN 判断名称是否包含国际化标签
+func IsIDN(name string) bool {
+	return !isASCII(name) || strings.Contains(strings.ToLower(name), acePrefix)
+}
+
+// Equal 判断两个名称在 ASCII 形式下是否相同
+func Equal(a, b string) bool {
+	asciiA, errA := ToASCII(a)
+	asciiB, errB := ToASCII(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return strings.TrimSuffix(asciiA, ".") == strings.TrimSuffix(asciiB, ".")
+}
+
+// isASCII 判断字符串是否只包含 ASCII 字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
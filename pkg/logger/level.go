@@ -0,0 +1,276 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Root 全局日志级别，组件未单独设置级别时使用全局级别
+const Root = "root"
+
+// 组件名称，各组件的日志可以单独设置级别
+const (
+	ComponentHandler   = "handler"
+	ComponentService   = "service"
+	ComponentScheduler = "scheduler"
+)
+
+// ErrUnknownComponent 组件不存在
+var ErrUnknownComponent = errors.New("日志组件不存在")
+
+// LevelStatus 日志级别状态
+type LevelStatus struct {
+	Component string     `json:"component"`
+	Level     string     `json:"level"`               // 当前生效的级别
+	Inherited bool       `json:"inherited"`           // 是否跟随全局级别
+	Until     *time.Time `json:"until,omitempty"`     // 临时调整的恢复时间
+	RevertTo  string     `json:"revert_to,omitempty"` // 临时调整恢复后的级别，为空表示跟随全局级别
+}
+
+// componentLevel 组件的日志级别，实现 zapcore.LevelEnabler
+type componentLevel struct {
+	name    string
+	level   zap.AtomicLevel
+	inherit atomic.Bool // 跟随全局级别，全局级别本身始终为 false
+
+	// 以下字段由 levelMu 保护
+	base        zapcore.Level // 临时调整前的级别
+	baseInherit bool
+	until       time.Time // 临时调整的恢复时间，为零表示没有临时调整
+	timer       *time.Timer
+}
+
+var (
+	levelMu sync.Mutex
+	root    = newComponentLevel(Root, false)
+	// components 已注册的组件，通过 Named 获取日志时自动注册
+	components = map[string]*componentLevel{
+		ComponentHandler:   newComponentLevel(ComponentHandler, true),
+		ComponentService:   newComponentLevel(ComponentService, true),
+		ComponentScheduler: newComponentLevel(ComponentScheduler, true),
+	}
+	// configured 配置文件中的全局级别，重置全局级别时恢复到该级别
+	configured = zapcore.InfoLevel
+)
+
+func newComponentLevel(name string, inherit bool) *componentLevel {
+	l := &componentLevel{name: name, level: zap.NewAtomicLevel(), baseInherit: inherit}
+	l.inherit.Store(inherit)
+	return l
+}
+
+// Enabled 判断是否记录该级别的日志
+func (l *componentLevel) Enabled(lvl zapcore.Level) bool {
+	if l.inherit.Load() {
+		return root.level.Enabled(lvl)
+	}
+	return l.level.Enabled(lvl)
+}
+
+// Level 当前生效的级别
+func (l *componentLevel) Level() zapcore.Level {
+	if l.inherit.Load() {
+		return root.level.Level()
+	}
+	return l.level.Level()
+}
+
+// apply 设置级别，inherit 为 true 时跟随全局级别，调用方需持有 levelMu
+func (l *componentLevel) apply(lvl zapcore.Level, inherit bool) {
+	if !inherit {
+		l.level.SetLevel(lvl)
+	}
+	l.inherit.Store(inherit)
+}
+
+// cancelTemporary 取消临时调整，调用方需持有 levelMu
+func (l *componentLevel) cancelTemporary() {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.until = time.Time{}
+}
+
+// status 返回级别状态，调用方需持有 levelMu
+func (l *componentLevel) status() *LevelStatus {
+	s := &LevelStatus{
+		Component: l.name,
+		Level:     l.Level().String(),
+		Inherited: l.inherit.Load(),
+	}
+	if !l.until.IsZero() {
+		until := l.until
+		s.Until = &until
+		if !l.baseInherit {
+			s.RevertTo = l.base.String()
+		}
+	}
+	return s
+}
+
+// lookup 查找组件，调用方需持有 levelMu
+func lookup(component string) (*componentLevel, error) {
+	if component == Root {
+		return root, nil
+	}
+	l, ok := components[component]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownComponent, component)
+	}
+	return l, nil
+}
+
+// Levels 返回全局和各组件的日志级别
+func Levels() []LevelStatus {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	statuses := []LevelStatus{*root.status()}
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		statuses = append(statuses, *components[name].status())
+	}
+	return statuses
+}
+
+// GetLevel 返回全局或组件的日志级别
+func GetLevel(component string) (*LevelStatus, error) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	l, err := lookup(component)
+	if err != nil {
+		return nil, err
+	}
+	return l.status(), nil
+}
+
+// SetLevel 设置全局或组件的日志级别，duration 大于 0 时为临时调整，到期后自动恢复到调整前的级别
+func SetLevel(component string, level zapcore.Level, duration time.Duration) (*LevelStatus, error) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	l, err := lookup(component)
+	if err != nil {
+		return nil, err
+	}
+
+	if duration <= 0 {
+		l.cancelTemporary()
+		l.base, l.baseInherit = level, false
+		l.apply(level, false)
+		return l.status(), nil
+	}
+
+	// 连续临时调整时恢复到第一次调整前的级别
+	if l.until.IsZero() {
+		l.base, l.baseInherit = l.level.Level(), l.inherit.Load()
+	}
+	l.cancelTemporary()
+	l.apply(level, false)
+	l.until = time.Now().Add(duration)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(duration, func() {
+		levelMu.Lock()
+		if l.timer != timer {
+			levelMu.Unlock()
+			return
+		}
+		l.timer, l.until = nil, time.Time{}
+		l.apply(l.base, l.baseInherit)
+		level := l.Level()
+		levelMu.Unlock()
+
+		GetLogger().Info("临时日志级别已恢复",
+			zap.String("component", l.name),
+			zap.String("level", level.String()),
+		)
+	})
+	l.timer = timer
+	return l.status(), nil
+}
+
+// ResetLevel 取消临时调整，组件恢复为跟随全局级别，全局级别恢复为配置文件中的级别
+func ResetLevel(component string) (*LevelStatus, error) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	l, err := lookup(component)
+	if err != nil {
+		return nil, err
+	}
+	l.cancelTemporary()
+	inherit := l != root
+	l.base, l.baseInherit = configured, inherit
+	l.apply(configured, inherit)
+	return l.status(), nil
+}
+
+// setConfigured 设置配置文件中的全局级别并取消全局级别的临时调整
+func setConfigured(level zapcore.Level) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	configured = level
+	root.cancelTemporary()
+	root.base, root.baseInherit = level, false
+	root.apply(level, false)
+}
+
+// register 注册组件，已注册时返回原来的级别
+func register(component string) *componentLevel {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	if component == Root {
+		return root
+	}
+	l, ok := components[component]
+	if !ok {
+		l = newComponentLevel(component, true)
+		components[component] = l
+	}
+	return l
+}
+
+// levelCore 按全局或组件的日志级别过滤日志
+type levelCore struct {
+	zapcore.Core
+	enabler *componentLevel
+}
+
+// Enabled 判断是否记录该级别的日志
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.enabler.Enabled(lvl) && c.Core.Enabled(lvl)
+}
+
+// Level 当前生效的级别
+func (c *levelCore) Level() zapcore.Level {
+	return c.enabler.Level()
+}
+
+// With 添加字段
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), enabler: c.enabler}
+}
+
+// Check 判断是否记录该条日志
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabler.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -1,27 +1,67 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var Log *zap.Logger
 
+// 日志输出目标
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+	OutputSyslog = "syslog"
+)
+
+// 日志格式
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
 // LogConfig 日志配置
 type LogConfig struct {
-	LogPath    string // 日志文件路径
-	MaxSize    int    // 每个日志文件的最大大小（MB）
-	MaxBackups int    // 保留的旧日志文件的最大数量
-	MaxAge     int    // 保留的旧日志文件的最大天数
-	Compress   bool   // 是否压缩旧日志文件
+	Level      string         // 日志级别 debug、info、warn、error
+	Format     string         // 日志格式 json、console
+	Outputs    []string       // 输出目标 stdout、stderr、file、syslog
+	LogPath    string         // 日志文件路径
+	MaxSize    int            // 每个日志文件的最大大小（MB）
+	MaxBackups int            // 保留的旧日志文件的最大数量，0 表示不按数量删除
+	MaxAge     int            // 保留的旧日志文件的最大天数，0 表示不按时间删除
+	Compress   bool           // 是否压缩旧日志文件
+	Syslog     SyslogConfig   // 输出到 syslog 时的配置
+	Sampling   SamplingConfig // 采样配置，Initial 为 0 时不采样
+}
+
+// SyslogConfig syslog 输出配置
+type SyslogConfig struct {
+	Network string // tcp、udp，为空时连接本机 syslog
+	Address string
+	Tag     string
+}
+
+// SamplingConfig 日志采样配置，每个 Tick 内相同级别和内容的日志先记录 Initial 条，之后每 Thereafter 条记录一条
+type SamplingConfig struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
 }
 
 // DefaultLogConfig 默认日志配置
 var DefaultLogConfig = LogConfig{
+	Level:      "info",
+	Format:     FormatJSON,
+	Outputs:    []string{OutputStdout},
 	LogPath:    "logs/app.log",
 	MaxSize:    100,
 	MaxBackups: 30,
@@ -29,21 +69,128 @@ var DefaultLogConfig = LogConfig{
 	Compress:   true,
 }
 
-// InitLogger 初始化日志
+var (
+	mu sync.Mutex
+	// base 按配置创建的日志输出，不过滤级别
+	base zapcore.Core
+	// named 各组件的日志，重新配置后重新创建
+	named map[string]*zap.Logger
+	// file 当前输出的日志文件，未输出到文件时为空
+	file *lumberjack.Logger
+	// closers 当前日志使用的文件和 syslog 连接，重新配置后关闭
+	closers []func() error
+)
+
+// InitLogger 使用默认配置初始化日志，用于加载配置文件前
 func InitLogger() {
-	initLoggerWithConfig(DefaultLogConfig)
+	if err := Configure(DefaultLogConfig); err != nil {
+		panic("初始化日志失败: " + err.Error())
+	}
 }
 
-// initLoggerWithConfig 使用指定配置初始化日志
-func initLoggerWithConfig(config LogConfig) {
-	// 确保日志目录存在
-	logDir := filepath.Dir(config.LogPath)
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		panic("创建日志目录失败: " + err.Error())
+// Configure 按配置重新初始化日志，失败时保留原来的日志
+func Configure(config LogConfig) error {
+	level, err := zapcore.ParseLevel(config.Level)
+	if err != nil {
+		return fmt.Errorf("日志级别 %s 无效", config.Level)
 	}
 
-	// 创建编码器配置
-	encoderConfig := zapcore.EncoderConfig{
+	var encoder zapcore.Encoder
+	switch config.Format {
+	case FormatJSON, "":
+		encoder = zapcore.NewJSONEncoder(encoderConfig())
+	case FormatConsole:
+		encoder = zapcore.NewConsoleEncoder(encoderConfig())
+	default:
+		return fmt.Errorf("日志格式 %s 无效", config.Format)
+	}
+
+	var (
+		syncers    []zapcore.WriteSyncer
+		newClosers []func() error
+		newFile    *lumberjack.Logger
+	)
+	closeAll := func() {
+		for _, c := range newClosers {
+			_ = c()
+		}
+	}
+	for _, output := range config.Outputs {
+		switch strings.ToLower(strings.TrimSpace(output)) {
+		case OutputStdout:
+			syncers = append(syncers, zapcore.Lock(os.Stdout))
+		case OutputStderr:
+			syncers = append(syncers, zapcore.Lock(os.Stderr))
+		case OutputFile:
+			if newFile != nil {
+				continue
+			}
+			// 确保日志目录存在
+			if err := os.MkdirAll(filepath.Dir(config.LogPath), 0755); err != nil {
+				closeAll()
+				return fmt.Errorf("创建日志目录失败: %w", err)
+			}
+			newFile = &lumberjack.Logger{
+				Filename:   config.LogPath,
+				MaxSize:    config.MaxSize,
+				MaxBackups: config.MaxBackups,
+				MaxAge:     config.MaxAge,
+				Compress:   config.Compress,
+				LocalTime:  true,
+			}
+			syncers = append(syncers, zapcore.AddSync(newFile))
+			newClosers = append(newClosers, newFile.Close)
+		case OutputSyslog:
+			w, err := dialSyslog(config.Syslog)
+			if err != nil {
+				closeAll()
+				return fmt.Errorf("连接 syslog 失败: %w", err)
+			}
+			syncers = append(syncers, zapcore.AddSync(w))
+			newClosers = append(newClosers, w.Close)
+		default:
+			closeAll()
+			return fmt.Errorf("日志输出 %s 无效", output)
+		}
+	}
+	if len(syncers) == 0 {
+		syncers = append(syncers, zapcore.Lock(os.Stdout))
+	}
+
+	// 按全局或组件的级别过滤，底层记录所有级别；写入前替换敏感信息
+	var core zapcore.Core = &redactCore{
+		Core: zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(syncers...), zapcore.DebugLevel),
+	}
+	if s := config.Sampling; s.Initial > 0 {
+		tick := s.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, s.Initial, s.Thereafter)
+	}
+
+	setConfigured(level)
+
+	mu.Lock()
+	defer mu.Unlock()
+	old := closers
+	if Log != nil {
+		_ = Log.Sync()
+	}
+	base = core
+	Log = newLogger(root)
+	named = make(map[string]*zap.Logger)
+	file = newFile
+	closers = newClosers
+	for _, c := range old {
+		_ = c()
+	}
+	return nil
+}
+
+// encoderConfig 日志编码配置
+func encoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
 		NameKey:        "logger",
@@ -57,69 +204,49 @@ func initLoggerWithConfig(config LogConfig) {
 		EncodeDuration: zapcore.SecondsDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
-
-	// 创建文件输出
-	fileWriter, err := os.OpenFile(config.LogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic("打开日志文件失败: " + err.Error())
-	}
-
-	// 创建Core
-	fileCore := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.AddSync(fileWriter),
-		zapcore.InfoLevel,
-	)
-
-	// 创建控制台输出
-	consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
-	consoleCore := zapcore.NewCore(
-		consoleEncoder,
-		zapcore.AddSync(os.Stdout),
-		zapcore.InfoLevel,
-	)
-
-	// 合并多个Core
-	core := zapcore.NewTee(fileCore, consoleCore)
-
-	// 创建Logger
-	Log = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 }
 
 // GetLogger 获取日志实例
 func GetLogger() *zap.Logger {
-	if Log == nil {
-		InitLogger()
+	mu.Lock()
+	log := Log
+	mu.Unlock()
+	if log != nil {
+		return log
 	}
+
+	InitLogger()
+	mu.Lock()
+	defer mu.Unlock()
 	return Log
 }
 
-// RotateLogFile 轮转日志文件
-func RotateLogFile() error {
-	// 获取当前时间
-	now := time.Now()
-	timestamp := now.Format("20060102150405")
-
-	// 构建新的文件名
-	logPath := DefaultLogConfig.LogPath
-	dir := filepath.Dir(logPath)
-	base := filepath.Base(logPath)
-	ext := filepath.Ext(base)
-	name := base[:len(base)-len(ext)]
-	rotatedPath := filepath.Join(dir, name+"."+timestamp+ext)
-
-	// 关闭当前日志文件
-	if Log != nil {
-		Log.Sync()
-	}
+// Named 获取组件的日志，级别可以通过 SetLevel 单独设置，未设置时跟随全局级别
+func Named(component string) *zap.Logger {
+	GetLogger()
+	l := register(component)
 
-	// 重命名当前日志文件
-	if err := os.Rename(logPath, rotatedPath); err != nil && !os.IsNotExist(err) {
-		return err
+	mu.Lock()
+	defer mu.Unlock()
+	if log, ok := named[component]; ok {
+		return log
 	}
+	log := newLogger(l).Named(component)
+	named[component] = log
+	return log
+}
 
-	// 重新初始化日志
-	InitLogger()
+// newLogger 创建按指定级别过滤的日志，调用方需持有 mu
+func newLogger(level *componentLevel) *zap.Logger {
+	return zap.New(&levelCore{Core: base, enabler: level}, zap.AddCaller(), zap.AddCallerSkip(1))
+}
 
-	return nil
+// RotateLogFile 立即轮转日志文件，未输出到文件时不做处理
+func RotateLogFile() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	return file.Rotate()
 }
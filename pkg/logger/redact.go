@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"dns-update/pkg/redact"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactCore 写入前替换日志内容和字段中的 AccessKey、访问令牌、密码等敏感信息
+type redactCore struct {
+	zapcore.Core
+}
+
+// With 添加字段
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+// Check 判断是否记录该条日志
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 替换敏感信息后写入
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = redact.String(ent.Message)
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactFields 敏感字段整体替换，字符串、错误等字段替换其中的敏感信息
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = redactField(f)
+	}
+	return redacted
+}
+
+// redactField 替换单个字段中的敏感信息
+//
+// zap.Any、zap.Object、zap.Array、zap.Inline 等结构化字段按 JSON 编码后的内容替换，有替换时输出替换后的内容；
+// 数值、时间等其他类型的字段只按字段名判断
+func redactField(f zapcore.Field) zapcore.Field {
+	switch f.Type {
+	case zapcore.SkipType, zapcore.NamespaceType:
+		return f
+	case zapcore.StringType:
+		return zap.String(f.Key, redact.Value(f.Key, f.String))
+	case zapcore.ByteStringType:
+		return zap.String(f.Key, redact.Value(f.Key, string(f.Interface.([]byte))))
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			if s, ok := safeString(err.Error); ok {
+				return zap.String(f.Key, redact.Value(f.Key, s))
+			}
+		}
+		return f
+	case zapcore.StringerType:
+		if v, ok := f.Interface.(fmt.Stringer); ok && v != nil {
+			if s, ok := safeString(v.String); ok {
+				return zap.String(f.Key, redact.Value(f.Key, s))
+			}
+		}
+		return f
+	}
+
+	if redact.IsSensitiveKey(f.Key) {
+		return zap.String(f.Key, redact.Placeholder)
+	}
+
+	switch f.Type {
+	case zapcore.ReflectType, zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.InlineMarshalerType:
+		return redactStructured(f)
+	}
+	return f
+}
+
+// redactStructured 将结构化字段编码为 JSON 后替换敏感字段和敏感信息，没有替换或无法编码时原样返回
+func redactStructured(f zapcore.Field) zapcore.Field {
+	v, ok := encodeField(f)
+	if !ok {
+		return f
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return f
+	}
+	var decoded any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return f
+	}
+	decoded, changed := redactValue(decoded)
+	if !changed {
+		return f
+	}
+
+	if f.Type == zapcore.InlineMarshalerType {
+		if m, ok := decoded.(map[string]any); ok {
+			return zap.Inline(inlineMap(m))
+		}
+	}
+	return zap.Any(f.Key, decoded)
+}
+
+// redactValue 递归替换 JSON 解码后的值，返回替换后的值以及是否有替换
+func redactValue(v any) (any, bool) {
+	switch v := v.(type) {
+	case string:
+		s := redact.String(v)
+		return s, s != v
+	case map[string]any:
+		changed := false
+		for k, item := range v {
+			if redact.IsSensitiveKey(k) && item != nil && item != "" {
+				v[k] = redact.Placeholder
+				changed = true
+				continue
+			}
+			if redacted, ok := redactValue(item); ok {
+				v[k] = redacted
+				changed = true
+			}
+		}
+		return v, changed
+	case []any:
+		changed := false
+		for i, item := range v {
+			if redacted, ok := redactValue(item); ok {
+				v[i] = redacted
+				changed = true
+			}
+		}
+		return v, changed
+	}
+	return v, false
+}
+
+// encodeField 取出结构化字段的值，MarshalLogObject 等方法出错或 panic 时返回 false
+func encodeField(f zapcore.Field) (v any, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	enc := zapcore.NewMapObjectEncoder()
+	var err error
+	switch f.Type {
+	case zapcore.ReflectType:
+		return f.Interface, true
+	case zapcore.ObjectMarshalerType:
+		err = enc.AddObject(f.Key, f.Interface.(zapcore.ObjectMarshaler))
+	case zapcore.ArrayMarshalerType:
+		err = enc.AddArray(f.Key, f.Interface.(zapcore.ArrayMarshaler))
+	case zapcore.InlineMarshalerType:
+		err = f.Interface.(zapcore.ObjectMarshaler).MarshalLogObject(enc)
+		return enc.Fields, err == nil
+	}
+	return enc.Fields[f.Key], err == nil
+}
+
+// inlineMap 按键名顺序输出的内联字段
+type inlineMap map[string]any
+
+// MarshalLogObject 实现 zapcore.ObjectMarshaler
+func (m inlineMap) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := enc.AddReflected(k, m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// safeString 调用 Error 或 String 方法，值为空指针等导致 panic 时返回 false，由 zap 按原方式处理
+func safeString(fn func() string) (s string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return fn(), true
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"dns-update/pkg/redact"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const (
+	testAccessKeyId = "LTAI5tQz8xY2wV3uR7pK9mNa"
+	testSecret      = "Zx9v8u7t6s5r4q3p2o1n0m"
+)
+
+// stringer 用于 zap.Stringer 字段
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+// credentials 用于 zap.Object 字段
+type credentials struct {
+	id, secret string
+}
+
+func (c credentials) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", c.id)
+	enc.AddString("secret", c.secret)
+	return nil
+}
+
+// newObserved 创建写入 observer 的脱敏日志
+func newObserved() (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(&redactCore{Core: core}), logs
+}
+
+// newBuffered 创建以 JSON 格式写入缓冲区的脱敏日志
+func newBuffered() (*zap.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig()), zapcore.AddSync(buf), zapcore.DebugLevel)
+	return zap.New(&redactCore{Core: core}), buf
+}
+
+func TestRedactCoreWrite(t *testing.T) {
+	log, logs := newObserved()
+	log.Info("请求失败 Authorization: Bearer "+testSecret,
+		zap.String("url", "https://example.com/api?access_token="+testSecret),
+		zap.ByteString("body", []byte(`{"AccessKeyId":"`+testAccessKeyId+`"}`)),
+		zap.Error(errors.New("SDKError: AccessKeyId "+testAccessKeyId+" is invalid")),
+		zap.Stringer("config", stringer("access_key_secret="+testSecret)),
+		zap.String("password", "hunter2"),
+		zap.Int("api_token", 42),
+		zap.String("token", "acme-challenge-token"),
+		zap.String("domain", "example.com"),
+	)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("期望 1 条日志，得到 %d 条", len(entries))
+	}
+	entry := entries[0]
+	if strings.Contains(entry.Message, testSecret) {
+		t.Errorf("日志内容仍包含敏感信息: %q", entry.Message)
+	}
+
+	fields := entry.ContextMap()
+	for key, v := range fields {
+		s, _ := v.(string)
+		if strings.Contains(s, testSecret) || strings.Contains(s, testAccessKeyId) || s == "hunter2" {
+			t.Errorf("字段 %s 仍包含敏感信息: %q", key, s)
+		}
+	}
+	if fields["password"] != redact.Placeholder {
+		t.Errorf("敏感字段 password = %v", fields["password"])
+	}
+	if fields["api_token"] != redact.Placeholder {
+		t.Errorf("敏感字段 api_token = %v", fields["api_token"])
+	}
+	if fields["token"] != "acme-challenge-token" {
+		t.Errorf("普通字段 token = %v", fields["token"])
+	}
+	if fields["domain"] != "example.com" {
+		t.Errorf("普通字段 domain = %v", fields["domain"])
+	}
+}
+
+func TestRedactCoreWith(t *testing.T) {
+	log, logs := newObserved()
+	log.With(
+		zap.String("accessKeyId", testAccessKeyId),
+		zap.Error(errors.New("security_token="+testSecret)),
+	).Info("初始化")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("期望 1 条日志，得到 %d 条", len(entries))
+	}
+	for key, v := range entries[0].ContextMap() {
+		s, _ := v.(string)
+		if strings.Contains(s, testSecret) || strings.Contains(s, testAccessKeyId) {
+			t.Errorf("With 添加的字段 %s 仍包含敏感信息: %q", key, s)
+		}
+	}
+}
+
+func TestRedactCoreStructuredFields(t *testing.T) {
+	log, buf := newBuffered()
+	log.Info("结构化字段",
+		zap.Any("request", map[string]any{
+			"domain": "example.com",
+			"auth":   map[string]string{"credential": testSecret},
+			"url":    "https://example.com/?access_token=" + testSecret,
+		}),
+		zap.Object("client", credentials{id: "client-1", secret: testSecret}),
+		zap.Strings("args", []string{"--access-key-id", testAccessKeyId}),
+		zap.Inline(credentials{id: "inline-1", secret: testSecret}),
+		zap.Any("ttl", 600),
+	)
+
+	out := buf.String()
+	if strings.Contains(out, testSecret) || strings.Contains(out, testAccessKeyId) {
+		t.Fatalf("结构化字段仍包含敏感信息: %s", out)
+	}
+	for _, want := range []string{`"domain":"example.com"`, `"id":"client-1"`, `"id":"inline-1"`, `"ttl":600`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %s: %s", want, out)
+		}
+	}
+}
+
+func TestRedactCoreKeepsOrdinaryStructuredFields(t *testing.T) {
+	log, logs := newObserved()
+	records := []string{"www", "api"}
+	log.Info("普通字段", zap.Any("records", map[string]int{"total": 2}), zap.Strings("rr", records))
+
+	fields := logs.All()[0].Context
+	if fields[0].Type != zapcore.ReflectType || fields[1].Type != zapcore.ArrayMarshalerType {
+		t.Errorf("没有敏感信息的字段不应改变类型: %v", fields)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// dialSyslog 连接 syslog，日志以 daemon 设施写入，级别包含在日志内容中
+func dialSyslog(config SyslogConfig) (io.WriteCloser, error) {
+	tag := config.Tag
+	if tag == "" {
+		tag = "dns-update"
+	}
+	return syslog.Dial(config.Network, config.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
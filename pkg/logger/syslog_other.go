@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+// dialSyslog 当前平台不支持 syslog
+func dialSyslog(SyslogConfig) (io.WriteCloser, error) {
+	return nil, errors.New("当前平台不支持 syslog")
+}
@@ -0,0 +1,91 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder 替换敏感信息的占位符
+const Placeholder = "[REDACTED]"
+
+// sensitiveKeys 字段名（忽略大小写和 _ - . 分隔符）包含这些词时视为敏感字段
+var sensitiveKeys = []string{
+	"accesskey",
+	"secret",
+	"password",
+	"passwd",
+	"authorization",
+	"signature",
+	"apikey",
+	"credential",
+	"cookie",
+}
+
+// tokenKeys 字段名以这些词结尾时视为敏感字段；单独的 token 可能是 ACME 验证令牌、分页游标等，不视为敏感字段
+var tokenKeys = []string{
+	"accesstoken",
+	"apitoken",
+	"authtoken",
+	"bearertoken",
+	"idtoken",
+	"refreshtoken",
+	"securitytoken",
+	"sessiontoken",
+}
+
+var (
+	// accessKeyId 阿里云 AccessKey ID
+	accessKeyId = regexp.MustCompile(`\bLTAI[0-9A-Za-z]{12,28}\b`)
+	// authScheme Authorization 请求头中的凭证
+	authScheme = regexp.MustCompile(`(?i)\b(Bearer|Basic)\s+[A-Za-z0-9._~+/=-]+`)
+	// keyValue key=value、key: value、"key":"value" 形式的敏感参数
+	keyValue = regexp.MustCompile(`(?i)("?[a-z_.-]*(?:access_?key(?:_?id|_?secret)?|secret|password|passwd|(?:access|api|auth|bearer|id|refresh|security|session)[_.-]?token|authorization|signature|api_?key)"?\s*[:=]\s*"?)([^"&\s,;}]+)`)
+	// encodedKeyValue URL 编码后的敏感参数，如签名错误时返回的待签名字符串，值本身可能再次编码（%25XX），到编码后的 & （%26）为止
+	encodedKeyValue = regexp.MustCompile(`(?i)((?:AccessKeyId|AccessKeySecret|SecurityToken|Signature)%3D)(?:[^%&\s]|%[^2&\s]|%2[^6&\s])+`)
+	// userinfo URL 中的密码
+	userinfo = regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`)
+)
+
+// IsSensitiveKey 判断字段名是否为敏感字段
+func IsSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	for _, k := range sensitiveKeys {
+		if strings.Contains(normalized, k) {
+			return true
+		}
+	}
+	for _, k := range tokenKeys {
+		if strings.HasSuffix(normalized, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// String 替换文本中的 AccessKey、访问令牌、密码等敏感信息
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+	s = authScheme.ReplaceAllString(s, "$1 "+Placeholder)
+	s = keyValue.ReplaceAllStringFunc(s, func(m string) string {
+		parts := keyValue.FindStringSubmatch(m)
+		// Authorization: Bearer xxx 的凭证部分已经替换
+		if strings.EqualFold(parts[2], "Bearer") || strings.EqualFold(parts[2], "Basic") || parts[2] == Placeholder {
+			return m
+		}
+		return parts[1] + Placeholder
+	})
+	s = encodedKeyValue.ReplaceAllString(s, "${1}"+Placeholder)
+	s = userinfo.ReplaceAllString(s, "${1}"+Placeholder+"@")
+	s = accessKeyId.ReplaceAllString(s, Placeholder)
+	return s
+}
+
+// Value 按字段名替换敏感字段的值，其他字段替换其中的敏感信息
+func Value(key, value string) string {
+	if value != "" && IsSensitiveKey(key) {
+		return Placeholder
+	}
+	return String(value)
+}